@@ -4,10 +4,11 @@ import (
 	"flag"
 	"fmt"
 	"image"
-	"image/png"
 	"os"
 	"runtime"
 	"runtime/pprof"
+	"slices"
+	"strings"
 
 	"fortio.org/cli"
 	"fortio.org/log"
@@ -20,33 +21,86 @@ func main() {
 	os.Exit(Main())
 }
 
-func SaveImage(img image.Image, fname string) error {
-	pngFile, err := os.Create(fname)
+func saveScene(fname string, scene *ray.Scene, camera *ray.Camera) error {
+	f, err := os.Create(fname)
 	if err != nil {
-		return fmt.Errorf("could not create PNG file %q: %w", fname, err)
+		return fmt.Errorf("could not create scene file %q: %w", fname, err)
 	}
-	defer pngFile.Close()
-	if err := png.Encode(pngFile, img); err != nil {
-		return fmt.Errorf("could not encode PNG to file %q: %w", fname, err)
+	defer f.Close()
+	if err := ray.SaveScene(f, scene, camera); err != nil {
+		return fmt.Errorf("could not save scene to %q: %w", fname, err)
 	}
 	return nil
 }
 
+func saveGIF(fname string, frames []*image.RGBA, delay int) error {
+	f, err := os.Create(fname)
+	if err != nil {
+		return fmt.Errorf("could not create gif file %q: %w", fname, err)
+	}
+	defer f.Close()
+	if err := ray.EncodeGIF(frames, delay, f); err != nil {
+		return fmt.Errorf("could not save gif to %q: %w", fname, err)
+	}
+	return nil
+}
+
+// saveImage saves the rendered image in the given format, or the linear colors retained by rt for hdr.
+func saveImage(rt *ray.Tracer, img image.Image, fname, format string, jpegQuality int) error {
+	if format == "hdr" {
+		b := img.Bounds()
+		return ray.SaveHDR(fname, rt.HDRBuffer(), b.Dx(), b.Dy())
+	}
+	return ray.SaveImageAs(img, fname, format, jpegQuality)
+}
+
 func Main() int {
 	// default matches the book code.
 	fRays := flag.Int("r", 10, "Number of rays per pixel")
 	fMaxDepth := flag.Int("d", 20, "Maximum ray bounce depth")
 	fWorkers := flag.Int("w", 1, "Number of parallel workers (0 = GOMAXPROCS)")
 	fCPUProfile := flag.String("profile-cpu", "", "Write CPU profile to file")
-	fSave := flag.String("save", "out.png", "Save the rendered image to the specified PNG file")
+	fSave := flag.String("save", "out.png", "Save the rendered image to the specified PNG, JPEG (.jpg/.jpeg) or Radiance HDR (.hdr, linear) file")
+	fJPEGQuality := flag.Int("jpeg-quality", 90, "JPEG quality (1-100) when saving to a .jpg/.jpeg file")
+	fFormat := flag.String("format", "",
+		"Format of the saved image: png, jpeg, ppm (binary, to compare with the C++ reference) or hdr; default from the -save extension")
 	// We get 486 objects like the c++ version with seed 7
 	fSeed := flag.Uint64("seed", 7, "Seed for the random generators (0 randomizes each time)")
 	// Matches https://github.com/RayTracing/raytracing.github.io/blob/release/src/InOneWeekend/main.cc#L66-L67
 	fWidth := flag.Int("width", 1200, "Image width in pixels")
 	fHeight := flag.Int("height", 675, "Image height in pixels")
+	fSuperSample := flag.Float64("s", 1, "Supersampling factor: render s times bigger (per side) and box filter down to the image size")
 	fProgressBar := flag.Bool("progress", true, "Disable progress bar with -progress=false")
+	fScene := flag.String("scene", "rich",
+		"Built-in scene to render: "+strings.Join(ray.SceneNames(), ", ")+"; or a .json file to load the scene (and camera) from")
+	fSaveScene := flag.String("save-scene", "", "Save the scene (and camera) to the specified JSON file")
+	fFrames := flag.Int("frames", 0, "Render an animation of that many frames orbiting the camera around its LookAt point (see -out)")
+	fOut := flag.String("out", "frame%04d.png", "File name pattern (with the frame number) for the -frames images")
+	fSampling := flag.String("sampling", "mis",
+		"How diffuse surfaces sample the scene's emitters (e.g. -scene emitters): mis (mix of both), brdf or light")
+	fCompare := flag.String("compare", "",
+		"Compare the rendered image to this reference `image` (png, jpeg or ppm) and fail when it differs by more than -max-diff")
+	fMaxDiff := flag.Int("max-diff", 0, "Largest 8 bit channel difference with the -compare image that is tolerated")
+	fGIF := flag.String("gif", "",
+		"Render progressively (one ray per pixel per pass, no -s supersampling) and also save the passes as an animated GIF to this `file`")
+	fGIFDelay := flag.Int("gif-delay", 20, "Delay between the -gif frames in 1/100th of a second")
 	cli.Main()
 	fname := *fSave
+	frames := *fFrames
+	if frames > 0 {
+		fname = *fOut
+	}
+	format := *fFormat
+	if format == "" {
+		format = ray.ImageFormat(fname)
+	}
+	if !slices.Contains(ray.ImageFormats, format) {
+		return log.FErrf("unknown image format %q, should be one of %v", format, ray.ImageFormats)
+	}
+	sampling, err := ray.ParseSampling(*fSampling)
+	if err != nil {
+		return log.FErrf("%v", err)
+	}
 	imgWidth := *fWidth
 	imgHeight := *fHeight
 	if *fCPUProfile != "" {
@@ -60,42 +114,107 @@ func Main() int {
 		}
 		defer pprof.StopCPUProfile()
 	}
-	rng := rand.New(*fSeed)
-	scene := ray.RichScene(rng)
+	scene, sceneCamera, err := ray.LoadNamedScene(*fScene, rand.New(*fSeed), imgWidth, imgHeight)
+	if err != nil {
+		return log.FErrf("%v", err)
+	}
+	camera := *sceneCamera
+	if *fSaveScene != "" {
+		if err = saveScene(*fSaveScene, scene, &camera); err != nil {
+			return log.FErrf("%v", err)
+		}
+		log.Infof("Saved scene to %q", *fSaveScene)
+	}
 	if *fWorkers <= 0 {
 		*fWorkers = runtime.GOMAXPROCS(0)
 	}
 	log.Infof("Rendering image %dx%d with %d rays/pixel, max depth %d, %d workers, seed %d: %d objects",
 		imgWidth, imgHeight, *fRays, *fMaxDepth, *fWorkers, *fSeed, len(scene.Objects))
-	rt := ray.New(imgWidth, imgHeight)
-	rt.MaxDepth = *fMaxDepth
-	rt.NumRaysPerPixel = *fRays
-	rt.NumWorkers = *fWorkers
-	rt.Seed = *fSeed
-	// Camera setup:
-	rt.Camera = ray.RichSceneCamera()
+	rt := ray.NewTracer(imgWidth, imgHeight, ray.WithDepth(*fMaxDepth), ray.WithRays(*fRays),
+		ray.WithWorkers(*fWorkers), ray.WithSeed(*fSeed), ray.WithCamera(camera))
+	rt.KeepHDR = format == "hdr"
+	rt.SuperSample = *fSuperSample
+	rt.EnableStats = true
+	rt.SamplingStrategy = sampling
 	// Setup progress bar
 	var pb *progressbar.Bar
 	if *fProgressBar {
 		pb = progressbar.NewBar()
 		pb.Prefix = "Rendering "
-		total := imgWidth * imgHeight
+		w, h := rt.RenderSize()
+		total := w * h * max(1, frames)
+		if *fGIF != "" {
+			// Progressive passes, which don't supersample.
+			total = imgWidth * imgHeight * rt.NumRaysPerPixel
+		}
 		p := progressbar.NewAutoProgress(pb, int64(total))
 		rt.ProgressFunc = func(n int) {
 			p.Update(n)
 		}
 	}
-	img := rt.Render(scene)
+	if frames > 0 {
+		rt.CameraPath = ray.OrbitPath(camera, frames)
+		for i := range frames {
+			img := rt.RenderFrame(scene, float64(i)/float64(frames))
+			frameName := fmt.Sprintf(fname, i)
+			if err = saveImage(rt, img, frameName, format, *fJPEGQuality); err != nil {
+				return log.FErrf("could not save frame %d to %q: %v", i, frameName, err)
+			}
+		}
+		if pb != nil {
+			pb.End()
+		}
+		log.Infof("Saved %d frames to %q", frames, fname)
+		return 0
+	}
+	var img *image.RGBA
+	var passes []*image.RGBA
+	if *fGIF != "" {
+		img = rt.RenderSnapshots(scene, func(frame *image.RGBA, _ int) bool {
+			passes = append(passes, frame)
+			return true
+		})
+	} else {
+		img = rt.Render(scene)
+	}
 	if pb != nil {
 		pb.End()
 	}
+	if *fGIF != "" {
+		if err = saveGIF(*fGIF, passes, *fGIFDelay); err != nil {
+			return log.FErrf("%v", err)
+		}
+		log.Infof("Saved %d passes to %q", len(passes), *fGIF)
+	}
+	log.Infof("Stats: %v", rt.Stats)
 	// Save image
 	if fname != "" {
-		err := SaveImage(img, fname)
+		err = saveImage(rt, img, fname, format, *fJPEGQuality)
 		if err != nil {
 			return log.FErrf("could not save image to %q: %v", fname, err)
 		}
 		log.Infof("Saved rendered image to %q", fname)
 	}
+	if *fCompare != "" {
+		return compare(img, *fCompare, *fMaxDiff)
+	}
+	return 0
+}
+
+// compare logs how much img differs from the reference image file, and returns the exit
+// code: 1 when a channel differs by more than maxDiff.
+func compare(img *image.RGBA, reference string, maxDiff int) int {
+	ref, err := ray.LoadImage(reference)
+	if err != nil {
+		return log.FErrf("%v", err)
+	}
+	if ref.Bounds().Size() != img.Bounds().Size() {
+		return log.FErrf("reference %q is %v, the rendered image %v", reference, ref.Bounds().Size(), img.Bounds().Size())
+	}
+	diff, mean, psnr := ray.CompareImages(ref, img)
+	log.Infof("Compared to %q: max diff %.0f, mean diff %.4f, PSNR %.2f dB", reference, diff, mean, psnr)
+	if diff > float64(maxDiff) {
+		return log.FErrf("rendered image differs from %q by up to %.0f, more than -max-diff %d", reference, diff, maxDiff)
+	}
 	return 0
 }
@@ -8,7 +8,6 @@ require (
 	fortio.org/progressbar v1.2.0
 	fortio.org/rand v1.1.0
 	fortio.org/terminal v0.63.4
-	golang.org/x/image v0.35.0
 	golang.org/x/term v0.39.0
 )
 
@@ -20,5 +19,6 @@ require (
 	github.com/kortschak/goroutine v1.1.3 // indirect
 	github.com/rivo/uniseg v0.4.7 // indirect
 	golang.org/x/crypto/x509roots/fallback v0.0.0-20250406160420-959f8f3db0fb // indirect
+	golang.org/x/image v0.35.0 // indirect
 	golang.org/x/sys v0.40.0 // indirect
 )
@@ -1,13 +1,15 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"image"
-	"image/png"
+	"io/fs"
 	"math"
 	"os"
 	"runtime/pprof"
+	"strings"
 
 	"fortio.org/cli"
 	"fortio.org/log"
@@ -15,7 +17,6 @@ import (
 	"fortio.org/rand"
 	"fortio.org/terminal/ansipixels"
 	"fortio.org/tray/ray"
-	"golang.org/x/image/draw"
 	"golang.org/x/term"
 )
 
@@ -23,28 +24,125 @@ func main() {
 	os.Exit(Main())
 }
 
-func SaveImage(img image.Image, fname string) error {
-	pngFile, err := os.Create(fname)
-	if err != nil {
-		return fmt.Errorf("could not create PNG file %q: %w", fname, err)
+// debugPixel logs the hits along the path through the center of pixel (x, y) and its color.
+func debugPixel(rt *ray.Tracer, scene *ray.Scene, x, y int) {
+	c, path := scene.RayColorDebug(rt.PrimaryRay(x, y), rt.MaxDepth)
+	log.Infof("Pixel %d,%d: %d hits, color %v", x, y, len(path), c)
+	for i, hr := range path {
+		log.Infof("  bounce %d: %T at %v, t %.6g, normal %v (front face %t), uv %.3g,%.3g",
+			i, hr.Mat, hr.Point, hr.T, hr.Normal, hr.FrontFace, hr.U, hr.V)
+	}
+}
+
+// saveImage saves the rendered image, or the linear colors retained by rt for .hdr files.
+func saveImage(rt *ray.Tracer, img image.Image, fname string, jpegQuality int) error {
+	if ray.ImageFormat(fname) == "hdr" {
+		b := img.Bounds()
+		return ray.SaveHDR(fname, rt.HDRBuffer(), b.Dx(), b.Dy())
+	}
+	return ray.SaveImage(img, fname, jpegQuality)
+}
+
+// moveCamera applies the camera motion mapped to the input key (WASD, R/F and arrows)
+// and returns whether the camera moved. Steps are a tenth of the distance to LookAt.
+func moveCamera(c *ray.Camera, input []byte) bool {
+	step := 0.1 * ray.Length(ray.Sub(c.LookAt, c.Position))
+	if step == 0 {
+		step = 0.1
+	}
+	switch string(input) {
+	case "w", "W":
+		c.MoveForward(step)
+	case "s", "S":
+		c.MoveForward(-step)
+	case "a", "A", "\x1b[D": // left arrow
+		c.Strafe(-step)
+	case "d", "D", "\x1b[C": // right arrow
+		c.Strafe(step)
+	case "r", "R", "\x1b[A": // up arrow
+		c.MoveUp(step)
+	case "f", "F", "\x1b[B": // down arrow
+		c.MoveUp(-step)
+	default:
+		return false
+	}
+	return true
+}
+
+// adjustLens applies the zoom (+/-, field of view) or depth of field ([/], aperture)
+// change mapped to the input key and returns whether the camera changed.
+func adjustLens(c *ray.Camera, input []byte) bool {
+	fov := c.VerticalFoV
+	if fov == 0 {
+		fov = 90 // Camera.Initialize's default
 	}
-	defer pngFile.Close()
-	if err := png.Encode(pngFile, img); err != nil {
-		return fmt.Errorf("could not encode PNG to file %q: %w", fname, err)
+	switch string(input) {
+	case "+", "=":
+		c.VerticalFoV = max(fov/1.2, 1)
+	case "-", "_":
+		c.VerticalFoV = min(fov*1.2, 170)
+	case "[":
+		c.Aperture = max(c.Aperture-0.05, 0)
+	case "]":
+		c.Aperture += 0.05
+	default:
+		return false
 	}
-	return nil
+	return true
+}
+
+// nextSnapshotName returns the first tray-NNNN.png file name not already in use.
+func nextSnapshotName() string {
+	for i := 1; ; i++ {
+		fname := fmt.Sprintf("tray-%04d.png", i)
+		if _, err := os.Stat(fname); errors.Is(err, fs.ErrNotExist) {
+			return fname
+		}
+	}
+}
+
+// lensStatus returns the current field of view and aperture for display.
+func lensStatus(c *ray.Camera) string {
+	fov := c.VerticalFoV
+	if fov == 0 {
+		fov = 90
+	}
+	return fmt.Sprintf("FoV %.1f°, Aperture %.2f", fov, c.Aperture)
 }
 
 func Main() int { //nolint:funlen // yes but fairly linear.
 	fSample := flag.Float64("s", 4, "Image supersampling factor")
 	fRays := flag.Int("r", 64, "Number of rays per pixel")
+	fTime := flag.Duration("time", 0, "Render for this long (e.g. 2s) with as many rays per pixel as fit, instead of -r")
 	fMaxDepth := flag.Int("d", 12, "Maximum ray bounce depth")
 	fWorkers := flag.Int("w", 0, "Number of parallel workers (0 = GOMAXPROCS)")
 	fCPUProfile := flag.String("profile-cpu", "", "Write CPU profile to file")
 	fExit := flag.Bool("exit", false,
 		"Not interactive (no raw), and exit immediately after rendering the image once (for timing purposes)")
-	fSave := flag.String("save", "", "Save the rendered image to the specified PNG file")
-	fSeed := flag.Uint64("seed", 0, "Seed for the random generators (0 randomizes each time)")
+	fSave := flag.String("save", "", "Save the rendered image to the specified PNG, JPEG (.jpg/.jpeg) or Radiance HDR (.hdr, linear) file")
+	fJPEGQuality := flag.Int("jpeg-quality", 90, "JPEG quality (1-100) when saving to a .jpg/.jpeg file")
+	fSeed := flag.Uint64("seed", 0, "Seed for the random generators building the scene (0 randomizes each time)")
+	fRenderSeed := flag.Uint64("render-seed", 0,
+		"Seed for the per pixel rays (noise pattern); 0 uses -seed, or picks one at startup so re-renders stay identical")
+	fToneMap := flag.String("tonemap", "none", "Tone mapping applied before sRGB conversion: none, reinhard or aces")
+	fEncoding := flag.String("encoding", "srgb", "Output encoding of the image: srgb, linear or gamma (see -gamma)")
+	fGamma := flag.Float64("gamma", ray.DefaultGamma, "Gamma exponent for -encoding gamma")
+	fSampler := flag.String("sampler", "random",
+		"Sub-pixel sampling with multiple rays per pixel: random or stratified (needs a perfect square -r)")
+	fFilter := flag.String("filter", "box",
+		"Weighting of the rays of a pixel by their offset: box, tent or gaussian (see -ray-radius)")
+	fShading := flag.String("shading", "full",
+		"Shading: full (path tracing) or a quick preview to position the camera: normals, flat, depth or ao (ambient occlusion)")
+	fAORadius := flag.Float64("ao-radius", ray.DefaultAORadius, "Distance within which hits darken the -shading ao (ambient occlusion)")
+	fSampling := flag.String("sampling", "mis",
+		"How diffuse surfaces sample the scene's emitters (e.g. -scene emitters): mis (mix of both), brdf or light")
+	fResize := flag.String("resize", "box",
+		"How the -s supersampled image is scaled to the terminal (in linear light): box, bilinear or nearest")
+	fDebugPixel := flag.String("debug-pixel", "",
+		"Log the bounces of the path through the center of this `x,y` pixel of the rendered (supersampled) image")
+	fRayRadius := flag.Float64("ray-radius", 0.5, "Radius (in pixels) of the sub-pixel ray offsets, 1 or more to overlap neighboring pixels")
+	fScene := flag.String("scene", "rich",
+		"Built-in scene to render: "+strings.Join(ray.SceneNames(), ", ")+"; or a .json file to load the scene (and camera) from")
 	cli.Main()
 	if *fCPUProfile != "" {
 		f, err := os.Create(*fCPUProfile)
@@ -57,6 +155,57 @@ func Main() int { //nolint:funlen // yes but fairly linear.
 		}
 		defer pprof.StopCPUProfile()
 	}
+	// Scene files without a camera are framed for the terminal's shape (2 pixels per cell).
+	termWidth, termHeight, _ := ansipixels.NonRawTerminalSize()
+	scene, sceneCamera, err := ray.LoadNamedScene(*fScene, rand.New(*fSeed), termWidth, 2*termHeight)
+	if err != nil {
+		return log.FErrf("%v", err)
+	}
+	camera := *sceneCamera
+	toneMapper, err := ray.ParseToneMapper(*fToneMap)
+	if err != nil {
+		return log.FErrf("%v", err)
+	}
+	encoding, err := ray.ParseOutputEncoding(*fEncoding)
+	if err != nil {
+		return log.FErrf("%v", err)
+	}
+	sampler, err := ray.ParseSampler(*fSampler)
+	if err != nil {
+		return log.FErrf("%v", err)
+	}
+	filter, err := ray.ParseFilter(*fFilter)
+	if err != nil {
+		return log.FErrf("%v", err)
+	}
+	// The scene seed only drives building the scene, the render seed the rays' sampling: kept
+	// fixed for the whole session so re-renders (e.g. on resize) don't change the noise.
+	renderSeed := *fRenderSeed
+	if renderSeed == 0 {
+		renderSeed = *fSeed
+	}
+	if renderSeed == 0 {
+		renderSeed = rand.New(0).Uint64()
+		log.Infof("Using render seed %d", renderSeed)
+	}
+	debugX, debugY := -1, -1
+	if *fDebugPixel != "" {
+		if _, err := fmt.Sscanf(*fDebugPixel, "%d,%d", &debugX, &debugY); err != nil {
+			return log.FErrf("Invalid -debug-pixel %q, should be x,y: %v", *fDebugPixel, err)
+		}
+	}
+	shading, err := ray.ParseShading(*fShading)
+	if err != nil {
+		return log.FErrf("%v", err)
+	}
+	resizeFilter, err := ray.ParseResizeFilter(*fResize)
+	if err != nil {
+		return log.FErrf("%v", err)
+	}
+	sampling, err := ray.ParseSampling(*fSampling)
+	if err != nil {
+		return log.FErrf("%v", err)
+	}
 	supersample := *fSample
 	if supersample <= 0 {
 		supersample = 1
@@ -81,36 +230,50 @@ func Main() int { //nolint:funlen // yes but fairly linear.
 		ap.W, ap.H, _ = ansipixels.NonRawTerminalSize()
 		defer fmt.Println()
 	}
-	var resized *image.RGBA
+	var resized, fullRes *image.RGBA
 	showSplash := normalRawMode
+	status := "" // shown in a box after the next render
 	fname := *fSave
-	rng := rand.New(*fSeed)
-	scene := ray.RichScene(rng)
 	ap.OnResize = func() error {
 		ap.ClearScreen()
 		// render at supersampled resolution
 		imgWidth, imgHeight := int(math.Round(supersample*float64(ap.W))), int(math.Round(supersample*float64(ap.H*2)))
-		rt := ray.New(imgWidth, imgHeight)
-		rt.Seed = *fSeed
-		rt.MaxDepth = *fMaxDepth
-		rt.NumRaysPerPixel = *fRays
-		rt.NumWorkers = *fWorkers
-		// Camera setup:
-		rt.Camera = ray.RichSceneCamera()
-		// Setup progress bar
-		pb := progressbar.NewBar()
-		pb.Prefix = "Rendering "
-		pb.ScreenWriter = ap.Logger
-		total := imgWidth * imgHeight
-		p := progressbar.NewAutoProgress(pb, int64(total))
-		rt.ProgressFunc = func(n int) {
-			p.Update(n)
+		rt := ray.NewTracer(imgWidth, imgHeight, ray.WithSeed(renderSeed), ray.WithDepth(*fMaxDepth),
+			ray.WithRays(*fRays), ray.WithWorkers(*fWorkers), ray.WithCamera(camera))
+		rt.KeepHDR = ray.ImageFormat(fname) == "hdr"
+		rt.ToneMapper = toneMapper
+		rt.OutputEncoding = encoding
+		rt.Gamma = *fGamma
+		rt.Sampler = sampler
+		rt.Filter = filter
+		rt.RayRadius = *fRayRadius
+		rt.Shading = shading
+		rt.SamplingStrategy = sampling
+		rt.AORadius = *fAORadius
+		var img *image.RGBA
+		if *fTime > 0 {
+			img, rt.NumRaysPerPixel = rt.RenderWithin(scene, *fTime)
+			log.Infof("Rendered %d rays per pixel in %v", rt.NumRaysPerPixel, *fTime)
+		} else {
+			// Setup progress bar
+			pb := progressbar.NewBar()
+			pb.Prefix = "Rendering "
+			pb.ScreenWriter = ap.Logger
+			total := imgWidth * imgHeight
+			p := progressbar.NewAutoProgress(pb, int64(total))
+			rt.ProgressFunc = func(n int) {
+				p.Update(n)
+			}
+			img = rt.Render(scene)
+			pb.End()
+		}
+		fullRes = img
+		if debugX >= 0 && (showSplash || exitAfterRender) {
+			debugPixel(rt, scene, debugX, debugY)
 		}
-		img := rt.Render(scene)
-		pb.End()
 		if fname != "" && (showSplash || exitAfterRender) {
 			// only save once, not after keypresses
-			err := SaveImage(img, fname)
+			err := saveImage(rt, img, fname, *fJPEGQuality)
 			if err != nil {
 				return fmt.Errorf("could not save image to %q: %w", fname, err)
 			}
@@ -119,18 +282,17 @@ func Main() int { //nolint:funlen // yes but fairly linear.
 		// Downscale image:
 		resized = img
 		if supersample != 1 {
-			origBounds := img.Bounds()
-			resized = image.NewRGBA(image.Rect(0, 0, ap.W, ap.H*2))
-			if supersample < 1 {
-				draw.NearestNeighbor.Scale(resized, resized.Bounds(), img, origBounds, draw.Over, nil)
-			} else {
-				draw.BiLinear.Scale(resized, resized.Bounds(), img, origBounds, draw.Over, nil)
-			}
+			resized = ray.Resize(img, ap.W, ap.H*2, resizeFilter)
 		}
 		_ = ap.ShowScaledImage(resized)
 		if showSplash {
-			ap.WriteBoxed(ap.H/2-2, "TRay: Terminal Ray-tracing\n%d x %d image (%.1fx)\nRays %d, Depth %d\nQ to quit.",
-				imgWidth, imgHeight, supersample, rt.NumRaysPerPixel, rt.MaxDepth)
+			ap.WriteBoxed(ap.H/2-2,
+				"TRay: Terminal Ray-tracing\n%d x %d image (%.1fx)\nRays %d, Depth %d\n%s\n"+
+					"WASD, R/F or arrows to move\n+/- to zoom, [/] for depth of field\nP to save a snapshot, Q to quit.",
+				imgWidth, imgHeight, supersample, rt.NumRaysPerPixel, rt.MaxDepth, lensStatus(&rt.Camera))
+		} else if status != "" {
+			ap.WriteBoxed(ap.H-3, "%s", status)
+			status = ""
 		}
 		ap.EndSyncMode()
 		return nil
@@ -140,24 +302,39 @@ func Main() int { //nolint:funlen // yes but fairly linear.
 		return 0
 	}
 	ap.AutoSync = false
-	err := ap.FPSTicks(func() bool {
+	err = ap.FPSTicks(func() bool {
 		if len(ap.Data) == 0 {
 			return true
 		}
 		c := ap.Data[0]
-		switch c {
-		case 'q', 'Q', 3: // Ctrl-C
+		switch {
+		case c == 'q' || c == 'Q' || c == 3: // Ctrl-C
 			log.Infof("Exiting on %q", c)
 			return false
+		case showSplash:
+			ap.HideCursor()
+			showSplash = false
+			_ = ap.ShowScaledImage(resized)
+		case c == 'p' || c == 'P': // not S, which moves the camera backward (WASD)
+			snapshot := nextSnapshotName()
+			_ = ap.ShowScaledImage(resized)
+			if err := ray.SaveImage(fullRes, snapshot, *fJPEGQuality); err != nil {
+				log.Errf("Could not save snapshot: %v", err)
+				ap.WriteBoxed(ap.H-3, "Could not save %q", snapshot)
+				break
+			}
+			log.Infof("Saved %dx%d snapshot to %q", fullRes.Bounds().Dx(), fullRes.Bounds().Dy(), snapshot)
+			ap.WriteBoxed(ap.H-3, "Saved %s", snapshot)
+		case adjustLens(&camera, ap.Data):
+			status = lensStatus(&camera)
+			log.Debugf("Input %q, %s, rerendering...", ap.Data, status)
+			_ = ap.OnResize()
+		case moveCamera(&camera, ap.Data):
+			log.Debugf("Input %q, camera moved to %v, rerendering...", ap.Data, camera.Position)
+			_ = ap.OnResize()
 		default:
 			log.Debugf("Input %q, rerendering...", c)
-			if showSplash {
-				ap.HideCursor()
-				showSplash = false
-				_ = ap.ShowScaledImage(resized)
-			} else {
-				_ = ap.OnResize()
-			}
+			_ = ap.OnResize()
 		}
 		return true
 	})
@@ -0,0 +1,124 @@
+package ray
+
+import "math"
+
+// AABB is an axis-aligned bounding box, one Interval per axis.
+type AABB struct {
+	X, Y, Z Interval
+}
+
+var (
+	// EmptyAABB contains nothing, it's the neutral element for UnionAABB.
+	EmptyAABB = AABB{Empty, Empty, Empty}
+	// UniverseAABB contains everything.
+	UniverseAABB = AABB{Universe, Universe, Universe}
+)
+
+// NewAABB returns the box having a and b as opposite corners (in any order).
+func NewAABB(a, b Vec3) AABB {
+	return AABB{
+		X: OrderedInterval(a.x, b.x),
+		Y: OrderedInterval(a.y, b.y),
+		Z: OrderedInterval(a.z, b.z),
+	}
+}
+
+// UnionAABB returns the smallest box enclosing both a and b.
+func UnionAABB(a, b AABB) AABB {
+	return AABB{
+		X: Union(a.X, b.X),
+		Y: Union(a.Y, b.Y),
+		Z: Union(a.Z, b.Z),
+	}
+}
+
+// Axis returns the interval for axis n (0 for X, 1 for Y, 2 for Z).
+func (b AABB) Axis(n int) Interval {
+	switch n {
+	case 1:
+		return b.Y
+	case 2:
+		return b.Z
+	default:
+		return b.X
+	}
+}
+
+// LongestAxis returns the index (see Axis) of the box's longest axis.
+func (b AABB) LongestAxis() int {
+	x, y, z := b.X.Length(), b.Y.Length(), b.Z.Length()
+	switch {
+	case x >= y && x >= z:
+		return 0
+	case y >= z:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// Pad returns the box with the axes thinner than delta widened to delta (around their middle).
+// Flat boxes (e.g. of axis aligned quads) would otherwise never be hit: Hit's slab test
+// needs some thickness.
+func (b AABB) Pad(delta float64) AABB {
+	pad := func(i Interval) Interval {
+		if i.Length() >= delta {
+			return i
+		}
+		return i.Expand(delta - i.Length())
+	}
+	return AABB{X: pad(b.X), Y: pad(b.Y), Z: pad(b.Z)}
+}
+
+// Centroid returns the center of the box.
+func (b AABB) Centroid() Vec3 {
+	return Vec3{(b.X.Start + b.X.End) / 2, (b.Y.Start + b.Y.End) / 2, (b.Z.Start + b.Z.End) / 2}
+}
+
+// IsFinite returns true if the box is bounded (and not empty) along all the axes.
+func (b AABB) IsFinite() bool {
+	for _, i := range [3]Interval{b.X, b.Y, b.Z} {
+		if i.IsEmpty() || math.IsInf(i.Start, 0) || math.IsInf(i.End, 0) {
+			return false
+		}
+	}
+	return true
+}
+
+// Contains returns true if p is inside the box (or on its surface).
+func (b AABB) Contains(p Vec3) bool {
+	return b.X.Contains(p.x) && b.Y.Contains(p.y) && b.Z.Contains(p.z)
+}
+
+// Hit returns true if the ray intersects the box within rayT (slab method).
+// Rays parallel to a slab hit it if their origin is within the slab (boundary included).
+func (b AABB) Hit(r *Ray, rayT Interval) bool {
+	ro := r.Origin.Components()
+	rd := r.Direction.Components()
+	for a := range 3 {
+		ax := b.Axis(a)
+		if rd[a] == 0 {
+			// Parallel: 1/0 = Inf times 0 (origin on the slab plane) would give NaN.
+			if !ax.Contains(ro[a]) {
+				return false
+			}
+			continue
+		}
+		adinv := 1.0 / rd[a]
+		t0 := (ax.Start - ro[a]) * adinv
+		t1 := (ax.End - ro[a]) * adinv
+		if t0 > t1 {
+			t0, t1 = t1, t0
+		}
+		if t0 > rayT.Start {
+			rayT.Start = t0
+		}
+		if t1 < rayT.End {
+			rayT.End = t1
+		}
+		if rayT.End <= rayT.Start {
+			return false
+		}
+	}
+	return true
+}
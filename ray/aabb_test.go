@@ -0,0 +1,185 @@
+package ray
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNewAABBOrdersCorners(t *testing.T) {
+	box := NewAABB(Vec3{1, -2, 3}, Vec3{-1, 2, -3})
+	expected := AABB{
+		X: Interval{Start: -1, End: 1},
+		Y: Interval{Start: -2, End: 2},
+		Z: Interval{Start: -3, End: 3},
+	}
+	if box != expected {
+		t.Errorf("NewAABB() = %v, want %v", box, expected)
+	}
+}
+
+func TestUnionAABB(t *testing.T) {
+	a := NewAABB(Vec3{0, 0, 0}, Vec3{1, 1, 1})
+	b := NewAABB(Vec3{2, -1, 0.5}, Vec3{3, 0, 0.7})
+	u := UnionAABB(a, b)
+	expected := NewAABB(Vec3{0, -1, 0}, Vec3{3, 1, 1})
+	if u != expected {
+		t.Errorf("UnionAABB() = %v, want %v", u, expected)
+	}
+	if UnionAABB(EmptyAABB, a) != a {
+		t.Errorf("UnionAABB(EmptyAABB, a) = %v, want %v", UnionAABB(EmptyAABB, a), a)
+	}
+}
+
+func TestAABBAxis(t *testing.T) {
+	box := NewAABB(Vec3{0, 1, 2}, Vec3{3, 4, 5})
+	for n, expected := range []Interval{box.X, box.Y, box.Z} {
+		if box.Axis(n) != expected {
+			t.Errorf("Axis(%d) = %v, want %v", n, box.Axis(n), expected)
+		}
+	}
+}
+
+func TestAABBHit(t *testing.T) {
+	rnd := RandForTests()
+	box := NewAABB(Vec3{-1, -1, -3}, Vec3{1, 1, -2})
+	tests := []struct {
+		name     string
+		origin   Vec3
+		dir      Vec3
+		interval Interval
+		expected bool
+	}{
+		{"straight on", Vec3{0, 0, 0}, Vec3{0.1, 0.1, -1}, FrontEpsilon, true},
+		{"miss to the side", Vec3{0, 0, 0}, Vec3{1, 0, -0.1}, FrontEpsilon, false},
+		{"pointing away", Vec3{0, 0, 0}, Vec3{0.1, 0.1, 1}, FrontEpsilon, false},
+		{"interval too short", Vec3{0, 0, 0}, Vec3{0.1, 0.1, -1}, Interval{Start: 0, End: 1}, false},
+		{"from inside", Vec3{0, 0, -2.5}, Vec3{1, 2, 3}, Front, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ray := NewRay(rnd, tt.origin, tt.dir)
+			if got := box.Hit(ray, tt.interval); got != tt.expected {
+				t.Errorf("Hit() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSphereBoundingBox(t *testing.T) {
+	sphere := &Sphere{Center: Vec3{1, 2, 3}, Radius: 0.5}
+	expected := NewAABB(Vec3{0.5, 1.5, 2.5}, Vec3{1.5, 2.5, 3.5})
+	if box := sphere.BoundingBox(); box != expected {
+		t.Errorf("BoundingBox() = %v, want %v", box, expected)
+	}
+}
+
+func TestSceneBoundingBox(t *testing.T) {
+	scene := &Scene{}
+	if box := scene.BoundingBox(); box != EmptyAABB {
+		t.Errorf("empty scene BoundingBox() = %v, want EmptyAABB", box)
+	}
+	scene.Objects = []Hittable{
+		&Sphere{Center: Vec3{0, 0, 0}, Radius: 1},
+		&Sphere{Center: Vec3{5, 0, 0}, Radius: 2},
+	}
+	expected := NewAABB(Vec3{-1, -2, -2}, Vec3{7, 2, 2})
+	if box := scene.BoundingBox(); box != expected {
+		t.Errorf("BoundingBox() = %v, want %v", box, expected)
+	}
+}
+
+func TestAABBLongestAxis(t *testing.T) {
+	tests := []struct {
+		box      AABB
+		expected int
+	}{
+		{NewAABB(Vec3{0, 0, 0}, Vec3{3, 1, 2}), 0},
+		{NewAABB(Vec3{0, -5, 0}, Vec3{3, 1, 2}), 1},
+		{NewAABB(Vec3{0, 0, -1}, Vec3{1, 1, 1}), 2},
+		{NewAABB(Vec3{0, 0, 0}, Vec3{1, 1, 1}), 0}, // ties go to the first axis
+		{NewAABB(Vec3{0, 0, 0}, Vec3{0, 2, 2}), 1},
+	}
+	for _, tt := range tests {
+		if got := tt.box.LongestAxis(); got != tt.expected {
+			t.Errorf("%v.LongestAxis() = %d, want %d", tt.box, got, tt.expected)
+		}
+	}
+}
+
+func TestAABBPad(t *testing.T) {
+	box := NewAABB(Vec3{-1, 2, 3}, Vec3{1, 2, 3.05}).Pad(0.1)
+	expected := AABB{X: Interval{-1, 1}, Y: Interval{1.95, 2.05}, Z: Interval{2.975, 3.075}}
+	for a := range 3 {
+		if got, want := box.Axis(a), expected.Axis(a); math.Abs(got.Start-want.Start) > 1e-12 || math.Abs(got.End-want.End) > 1e-12 {
+			t.Errorf("Pad() axis %d = %v, want %v", a, got, want)
+		}
+	}
+	// A flat box is never hit by the slab test, the padded one is.
+	rnd := RandForTests()
+	flat := NewAABB(Vec3{-1, -1, -2}, Vec3{1, 1, -2})
+	for _, dir := range []Vec3{{0, 0, -1}, {0.2, -0.3, -1}} {
+		ray := NewRay(rnd, Vec3{0, 0, 0}, dir)
+		if flat.Hit(ray, FrontEpsilon) {
+			t.Errorf("flat box Hit(%v) = true, expected the degenerate slab to miss", dir)
+		}
+		if !flat.Pad(1e-4).Hit(ray, FrontEpsilon) {
+			t.Errorf("padded box Hit(%v) = false, want true", dir)
+		}
+	}
+}
+
+func TestAABBCentroidContains(t *testing.T) {
+	box := NewAABB(Vec3{-1, 0, 2}, Vec3{3, 1, 4})
+	if c := box.Centroid(); c != (Vec3{1, 0.5, 3}) {
+		t.Errorf("Centroid() = %v, want {1 0.5 3}", c)
+	}
+	tests := []struct {
+		p        Vec3
+		expected bool
+	}{
+		{Vec3{1, 0.5, 3}, true},
+		{Vec3{-1, 0, 2}, true}, // corners are inside
+		{Vec3{3, 1, 4}, true},
+		{Vec3{3.01, 0.5, 3}, false},
+		{Vec3{1, -0.01, 3}, false},
+		{Vec3{1, 0.5, 4.5}, false},
+	}
+	for _, tt := range tests {
+		if got := box.Contains(tt.p); got != tt.expected {
+			t.Errorf("Contains(%v) = %v, want %v", tt.p, got, tt.expected)
+		}
+	}
+	if EmptyAABB.Contains(Vec3{}) {
+		t.Error("EmptyAABB should not contain anything")
+	}
+}
+
+func TestAABBHitParallel(t *testing.T) {
+	rnd := RandForTests()
+	box := NewAABB(Vec3{-1, -1, -1}, Vec3{1, 1, 1})
+	tests := []struct {
+		name     string
+		origin   Vec3
+		dir      Vec3
+		expected bool
+	}{
+		{"along x through", Vec3{-5, 0, 0}, Vec3{1, 0, 0}, true},
+		{"along y through", Vec3{0, -5, 0.5}, Vec3{0, 1, 0}, true},
+		{"along z through", Vec3{0.5, -0.5, 5}, Vec3{0, 0, -1}, true},
+		{"along x on the y=1 face plane", Vec3{-5, 1, 0}, Vec3{1, 0, 0}, true},
+		{"along y on the x=-1 and z=1 edge", Vec3{-1, 5, 1}, Vec3{0, -1, 0}, true},
+		{"along z on the x=1 face plane", Vec3{1, 0, -5}, Vec3{0, 0, 1}, true},
+		{"along x above", Vec3{-5, 1.001, 0}, Vec3{1, 0, 0}, false},
+		{"along y beside", Vec3{-1.001, -5, 0}, Vec3{0, 1, 0}, false},
+		{"along z beside", Vec3{0, 2, 5}, Vec3{0, 0, -1}, false},
+		{"along x pointing away", Vec3{5, 0, 0}, Vec3{1, 0, 0}, false},
+		{"in the xy plane on z=-1 face plane", Vec3{-5, -4, -1}, Vec3{1, 1, 0}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := box.Hit(NewRay(rnd, tt.origin, tt.dir), FrontEpsilon); got != tt.expected {
+				t.Errorf("Hit(%v, %v) = %v, want %v", tt.origin, tt.dir, got, tt.expected)
+			}
+		})
+	}
+}
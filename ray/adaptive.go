@@ -0,0 +1,42 @@
+package ray
+
+import (
+	"math"
+
+	"fortio.org/rand"
+)
+
+// Luminance returns the relative luminance (Rec. 709 weights) of the linear color c.
+func Luminance(c ColorF) float64 {
+	return 0.2126*c.x + 0.7152*c.y + 0.0722*c.z
+}
+
+// adaptivePixel samples pixel (x, y) with at least NumRaysPerPixel (and 2) rays, then keeps
+// adding rays until the standard error of the mean luminance drops below AdaptiveThreshold
+// or MaxRaysPerPixel is reached. Returns the average color (weighted according to the Filter)
+// and the number of rays used.
+// The running variance is computed with Welford's algorithm (mean and M2).
+func (t *Tracer) adaptivePixel(rng rand.Rand, scene *Scene, scratch *pathScratch, x, y, rrDepth int) (ColorF, int) {
+	minRays := max(2, t.NumRaysPerPixel)
+	maxRays := max(minRays, t.MaxRaysPerPixel)
+	colorSum := ColorF{0, 0, 0}
+	weights := 0.0
+	mean, m2 := 0.0, 0.0
+	n := 0
+	for n < maxRays {
+		offsetX, offsetY := rng.InDisc(t.RayRadius)
+		c := t.sample(rng, scene, scratch, x, y, offsetX, offsetY, rrDepth)
+		weight := t.Filter.Weight(offsetX, offsetY, t.RayRadius)
+		colorSum = AddScaled(colorSum, c, weight)
+		weights += weight
+		n++
+		l := Luminance(c)
+		delta := l - mean
+		mean += delta / float64(n)
+		m2 += delta * (l - mean)
+		if n >= minRays && math.Sqrt(m2/float64(n-1)/float64(n)) < t.AdaptiveThreshold {
+			break
+		}
+	}
+	return SDiv(colorSum, weights), n
+}
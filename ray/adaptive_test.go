@@ -0,0 +1,80 @@
+package ray
+
+import (
+	"sync/atomic"
+	"testing"
+)
+
+func TestLuminance(t *testing.T) {
+	tests := []struct {
+		c        ColorF
+		expected float64
+	}{
+		{ColorF{0, 0, 0}, 0},
+		{ColorF{1, 1, 1}, 1},
+		{ColorF{0, 1, 0}, 0.7152},
+	}
+	for _, tt := range tests {
+		if l := Luminance(tt.c); l != tt.expected {
+			t.Errorf("Luminance(%v) = %v, want %v", tt.c, l, tt.expected)
+		}
+	}
+}
+
+func renderAdaptive(t *testing.T, scene *Scene) (rays int) {
+	t.Helper()
+	tracer := New(12, 12)
+	tracer.Seed = 42
+	tracer.NumRaysPerPixel = 4
+	tracer.MaxRaysPerPixel = 256
+	tracer.AdaptiveThreshold = 0.01
+	var total atomic.Int64
+	tracer.ProgressFunc = func(n int) { total.Add(int64(n)) }
+	img := tracer.Render(scene)
+	for y := range 12 {
+		for x := range 12 {
+			if _, _, _, a := img.At(x, y).RGBA(); a != 0xffff {
+				t.Errorf("pixel (%d,%d) not rendered", x, y)
+			}
+		}
+	}
+	return int(total.Load())
+}
+
+func TestAdaptiveSampling(t *testing.T) {
+	const pixels = 12 * 12
+	// Flat light everywhere: no variance, so only the base rays are used.
+	flat := &Scene{Objects: []Hittable{}, Background: AmbientLight{ColorA: ColorF{0.5, 0.5, 0.5}, ColorB: ColorF{0.5, 0.5, 0.5}}}
+	if rays := renderAdaptive(t, flat); rays != 4*pixels {
+		t.Errorf("flat scene used %d rays, want %d", rays, 4*pixels)
+	}
+	// The pixels on the edge need more rays, but not the rest of the image.
+	rays := renderAdaptive(t, edgeScene())
+	if rays <= 4*pixels || rays >= 256*pixels/4 {
+		t.Errorf("edge scene used %d rays, want between %d and %d", rays, 4*pixels, 256*pixels/4)
+	}
+}
+
+func TestAdaptivePixelMaxRays(t *testing.T) {
+	tracer := New(12, 12)
+	tracer.NumRaysPerPixel = 1
+	tracer.MaxRaysPerPixel = 50
+	tracer.AdaptiveThreshold = 1e-9 // never converges
+	scene := tracer.setup(edgeScene())
+	rng := RandForTests()
+	// Pixels straddling the edge (neither fully lit nor dark) should use all the rays.
+	found := 0
+	for x := range 12 {
+		c, n := tracer.adaptivePixel(rng, scene, &pathScratch{}, x, 6, 0)
+		if c.x == 0 || c.x == 1 {
+			continue
+		}
+		found++
+		if n != 50 {
+			t.Errorf("pixel (%d,6) = %v used %d rays, want the max 50", x, c, n)
+		}
+	}
+	if found == 0 {
+		t.Error("no pixel on the edge found")
+	}
+}
@@ -0,0 +1,97 @@
+package ray
+
+import (
+	"image"
+	"math"
+)
+
+// CameraPath is a camera animation: the Keyframes are evenly spaced over t in [0,1]
+// (first at 0, last at 1) and the camera is linearly interpolated in between.
+type CameraPath struct {
+	Keyframes []Camera
+}
+
+// At returns the camera at time t (clamped to [0,1]) along the path. The zero Camera
+// is returned for an empty path. The result needs to be initialized (as Tracer.Render does).
+func (p *CameraPath) At(t float64) Camera {
+	n := len(p.Keyframes)
+	if n == 0 {
+		return Camera{}
+	}
+	t = ZeroOne.Clamp(t)
+	pos := t * float64(n-1)
+	i := min(int(pos), n-2)
+	if i < 0 {
+		return p.Keyframes[0].settings()
+	}
+	return lerpCamera(p.Keyframes[i], p.Keyframes[i+1], pos-float64(i))
+}
+
+// settings returns a copy of the camera without the computed (Initialize) fields.
+func (c Camera) settings() Camera {
+	return Camera{
+		Position:       c.Position,
+		LookAt:         c.LookAt,
+		Up:             c.Up,
+		VerticalFoV:    c.VerticalFoV,
+		FocalLength:    c.FocalLength,
+		FocusDistance:  c.FocusDistance,
+		AutoFocus:      c.AutoFocus,
+		Aperture:       c.Aperture,
+		ApertureBlades: c.ApertureBlades,
+		Orthographic:   c.Orthographic,
+		OrthoHeight:    c.OrthoHeight,
+		Time0:          c.Time0,
+		Time1:          c.Time1,
+	}
+}
+
+func lerp(a, b, t float64) float64 {
+	return a + (b-a)*t
+}
+
+// lerpCamera linearly interpolates all the camera settings between a (t=0) and b (t=1),
+// except the discrete ones (blades, projection, auto focus target) taken from a.
+func lerpCamera(a, b Camera, t float64) Camera {
+	return Camera{
+		Position:       Lerp(a.Position, b.Position, t),
+		LookAt:         Lerp(a.LookAt, b.LookAt, t),
+		Up:             Lerp(a.Up, b.Up, t),
+		VerticalFoV:    lerp(a.VerticalFoV, b.VerticalFoV, t),
+		FocalLength:    lerp(a.FocalLength, b.FocalLength, t),
+		FocusDistance:  lerp(a.FocusDistance, b.FocusDistance, t),
+		AutoFocus:      a.AutoFocus,
+		Aperture:       lerp(a.Aperture, b.Aperture, t),
+		ApertureBlades: a.ApertureBlades,
+		Orthographic:   a.Orthographic,
+		OrthoHeight:    lerp(a.OrthoHeight, b.OrthoHeight, t),
+		Time0:          lerp(a.Time0, b.Time0, t),
+		Time1:          lerp(a.Time1, b.Time1, t),
+	}
+}
+
+// OrbitPath returns a full turn of the camera around its LookAt point, about the
+// vertical (Y) axis, as n+1 keyframes (the last one being back at the start).
+// Bigger n gives a rounder orbit: linear interpolation cuts the corners in between.
+func OrbitPath(c Camera, n int) *CameraPath {
+	n = max(n, 3)
+	p := &CameraPath{Keyframes: make([]Camera, n+1)}
+	offset := Sub(c.Position, c.LookAt)
+	for i := range n + 1 {
+		angle := 2 * math.Pi * float64(i) / float64(n)
+		k := c.settings()
+		k.Position = Add(c.LookAt, Rotate(offset, Vec3{0, 1, 0}, angle))
+		p.Keyframes[i] = k
+	}
+	return p
+}
+
+// RenderFrame renders the scene with the camera at time at (in [0,1]) along CameraPath,
+// e.g. at = i/frames for the frame i of an animation. The current Camera is used
+// when CameraPath is nil. The returned image is reused by the next render.
+func (t *Tracer) RenderFrame(scene *Scene, at float64) *image.RGBA {
+	if t.CameraPath != nil {
+		t.Camera = t.CameraPath.At(at)
+	}
+	return t.Render(scene)
+}
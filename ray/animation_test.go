@@ -0,0 +1,91 @@
+package ray
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+func TestCameraPathAt(t *testing.T) {
+	a := Camera{Position: Vec3{0, 0, 0}, LookAt: Vec3{0, 0, -1}, VerticalFoV: 20}
+	b := Camera{Position: Vec3{2, 0, 0}, LookAt: Vec3{0, 0, -1}, VerticalFoV: 40}
+	c := Camera{Position: Vec3{2, 4, 0}, LookAt: Vec3{0, 0, -1}, VerticalFoV: 40, Aperture: 1}
+	path := &CameraPath{Keyframes: []Camera{a, b, c}}
+	tests := []struct {
+		at       float64
+		position Vec3
+		fov      float64
+		aperture float64
+	}{
+		{-1, Vec3{0, 0, 0}, 20, 0},
+		{0, Vec3{0, 0, 0}, 20, 0},
+		{0.25, Vec3{1, 0, 0}, 30, 0},
+		{0.5, Vec3{2, 0, 0}, 40, 0},
+		{0.75, Vec3{2, 2, 0}, 40, 0.5},
+		{1, Vec3{2, 4, 0}, 40, 1},
+		{2, Vec3{2, 4, 0}, 40, 1},
+	}
+	for _, tt := range tests {
+		got := path.At(tt.at)
+		if !Equal(got.Position, tt.position, 1e-12) || math.Abs(got.VerticalFoV-tt.fov) > 1e-12 ||
+			math.Abs(got.Aperture-tt.aperture) > 1e-12 || got.LookAt != a.LookAt {
+			t.Errorf("At(%v) = %+v, want position %v, fov %v, aperture %v", tt.at, got, tt.position, tt.fov, tt.aperture)
+		}
+	}
+	single := &CameraPath{Keyframes: []Camera{b}}
+	if got := single.At(0.3); got != b {
+		t.Errorf("At() with a single keyframe = %+v, want %+v", got, b)
+	}
+	if got := (&CameraPath{}).At(0.3); got != (Camera{}) {
+		t.Errorf("At() of an empty path = %+v, want the zero camera", got)
+	}
+}
+
+func TestOrbitPath(t *testing.T) {
+	c := *RichSceneCamera()
+	c.LookAt = Vec3{1, 0, 1}
+	path := OrbitPath(c, 8)
+	if len(path.Keyframes) != 9 {
+		t.Fatalf("OrbitPath() has %d keyframes, want 9", len(path.Keyframes))
+	}
+	radius := Length(Sub(c.Position, c.LookAt))
+	for i, k := range path.Keyframes {
+		if d := Length(Sub(k.Position, c.LookAt)); math.Abs(d-radius) > 1e-9 || k.Position.y != c.Position.y {
+			t.Errorf("keyframe %d at %v is %v away from LookAt, want %v at the same height", i, k.Position, d, radius)
+		}
+		if k.LookAt != c.LookAt || k.VerticalFoV != c.VerticalFoV {
+			t.Errorf("keyframe %d = %+v, should keep the camera settings", i, k)
+		}
+	}
+	if start, end := path.At(0), path.At(1); !Equal(start.Position, end.Position, 1e-9) {
+		t.Errorf("orbit should end where it started: %v != %v", end.Position, start.Position)
+	}
+	// Half way is opposite the start.
+	opposite := Add(c.LookAt, Sub(c.LookAt, c.Position))
+	opposite.y = c.Position.y
+	if half := path.At(0.5); !Equal(half.Position, opposite, 1e-9) {
+		t.Errorf("At(0.5) = %v, want %v", half.Position, opposite)
+	}
+}
+
+func TestRenderFrame(t *testing.T) {
+	rt := New(16, 8)
+	rt.Seed = 42
+	scene := DefaultScene()
+	rt.Camera = *RichSceneCamera()
+	first := bytes.Clone(rt.RenderFrame(scene, 0.5).Pix) // no path: the camera doesn't move
+	if again := rt.RenderFrame(scene, 0); !bytes.Equal(first, again.Pix) {
+		t.Errorf("RenderFrame() without path should not depend on the time")
+	}
+	rt.CameraPath = OrbitPath(Camera{Position: Vec3{0, 1, 3}, LookAt: Vec3{0, 0, -1}}, 16)
+	frame0 := bytes.Clone(rt.RenderFrame(scene, 0).Pix)
+	if rt.Position != rt.CameraPath.Keyframes[0].Position {
+		t.Errorf("RenderFrame(0) camera at %v, want %v", rt.Position, rt.CameraPath.Keyframes[0].Position)
+	}
+	if frame := rt.RenderFrame(scene, 0.25); bytes.Equal(frame0, frame.Pix) {
+		t.Errorf("RenderFrame(0.25) should differ from the first frame")
+	}
+	if frame := rt.RenderFrame(scene, 1); !bytes.Equal(frame0, frame.Pix) {
+		t.Errorf("RenderFrame(1) of an orbit should be the same as the first frame")
+	}
+}
@@ -0,0 +1,68 @@
+package ray
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"math"
+
+	"fortio.org/rand"
+)
+
+// RenderAOV renders the scene (color, as Render) along with 2 auxiliary buffers (arbitrary
+// output variables) from the primary hit through each pixel's center, e.g. for compositing
+// or to feed a denoiser:
+//   - normal: the surface normal (facing the camera) mapped from [-1,1] to [0,255] per
+//     component, black where no object is hit.
+//   - depth: the distance of the hit along the view direction, as gray levels relative to
+//     the farthest hit (white), also white where no object is hit.
+//
+// Both are linear (no sRGB nor tone mapping) and the returned color image is reused by the next render.
+func (t *Tracer) RenderAOV(scene *Scene) (colorImg, normal, depth *image.RGBA) {
+	colorImg = t.Render(scene)
+	if scene == nil {
+		scene = DefaultScene() // what Render used (and it set the matching camera)
+	}
+	bounds := colorImg.Bounds()
+	normal = image.NewRGBA(bounds)
+	depth = image.NewRGBA(bounds)
+	pinhole := t.Camera
+	pinhole.Aperture = 0
+	forward := pinhole.Forward()
+	depths := make([]float64, t.width*t.height)
+	t.parallelTiles(context.Background(), bounds, func(tile image.Rectangle) {
+		var hr HitRecord
+		rng := rand.New(t.Seed) // only used for the ray time, when the shutter is open
+		for y := tile.Min.Y; y < tile.Max.Y; y++ {
+			for x := tile.Min.X; x < tile.Max.X; x++ {
+				r := pinhole.GetRay(rng, float64(x), float64(y), 0, 0)
+				if !scene.Hit(r, FrontEpsilon, &hr) {
+					depths[y*t.width+x] = math.Inf(1)
+					continue
+				}
+				n := hr.Normal
+				normal.SetRGBA(x, y, color.RGBA{toByte(0.5 * (n.x + 1)), toByte(0.5 * (n.y + 1)), toByte(0.5 * (n.z + 1)), 255})
+				depths[y*t.width+x] = Dot(Sub(hr.Point, pinhole.Position), forward)
+			}
+		}
+	})
+	maxDepth := 0.0
+	for _, d := range depths {
+		if !math.IsInf(d, 1) {
+			maxDepth = max(maxDepth, d)
+		}
+	}
+	for i, d := range depths {
+		g := uint8(255)
+		if !math.IsInf(d, 1) && maxDepth > 0 {
+			g = toByte(d / maxDepth)
+		}
+		depth.Pix[4*i], depth.Pix[4*i+1], depth.Pix[4*i+2], depth.Pix[4*i+3] = g, g, g, 255
+	}
+	return colorImg, normal, depth
+}
+
+// toByte maps [0,1] (clamped) linearly to [0,255].
+func toByte(v float64) uint8 {
+	return uint8(math.Round(255 * ZeroOne.Clamp(v)))
+}
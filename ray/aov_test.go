@@ -0,0 +1,49 @@
+package ray
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestRenderAOV(t *testing.T) {
+	// Sphere in front of a wall (top half of the view only) facing the camera.
+	scene := &Scene{
+		Objects: []Hittable{
+			&Sphere{Center: Vec3{0, 0, -3}, Radius: 1, Mat: Lambertian{Albedo: ColorF{0.5, 0.5, 0.5}}},
+			NewQuad(Vec3{-10, 0, -6}, Vec3{20, 0, 0}, Vec3{0, 10, 0}, Lambertian{Albedo: ColorF{0.5, 0.5, 0.5}}),
+		},
+	}
+	rt := New(40, 40)
+	rt.Seed = 42
+	rt.Camera = Camera{Position: Vec3{0, 0, 0}, LookAt: Vec3{0, 0, -1}, VerticalFoV: 90}
+	img, normal, depth := rt.RenderAOV(scene)
+	if img != rt.imageData || normal.Bounds() != img.Bounds() || depth.Bounds() != img.Bounds() {
+		t.Fatalf("RenderAOV() images have bounds %v %v %v", img.Bounds(), normal.Bounds(), depth.Bounds())
+	}
+	tests := []struct {
+		name   string
+		x, y   int
+		normal color.RGBA
+		depth  uint8
+	}{
+		// Sphere center faces the camera: normal {0, 0, 1}, depth 2 out of the wall's 6.
+		{"sphere center", 20, 20, color.RGBA{134, 121, 255, 255}, 85}, // (slightly off center)
+		// The wall at z=-6 is the farthest hit.
+		{"wall", 20, 5, color.RGBA{128, 128, 255, 255}, 255},
+		{"miss", 0, 39, color.RGBA{0, 0, 0, 0}, 255},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normal.RGBAAt(tt.x, tt.y); got != tt.normal {
+				t.Errorf("normal at (%d, %d) = %v, want %v", tt.x, tt.y, got, tt.normal)
+			}
+			if got := depth.RGBAAt(tt.x, tt.y); got != (color.RGBA{tt.depth, tt.depth, tt.depth, 255}) {
+				t.Errorf("depth at (%d, %d) = %v, want gray %d", tt.x, tt.y, got, tt.depth)
+			}
+		})
+	}
+	// Top of the sphere: normal pointing up (and toward the camera).
+	if got := normal.RGBAAt(20, 14); got.G <= 160 || got.G <= got.R {
+		t.Errorf("normal near the top of the sphere = %v, want pointing up", got)
+	}
+}
@@ -0,0 +1,51 @@
+package ray
+
+import (
+	"cmp"
+	"slices"
+)
+
+// BVHNode is a bounding volume hierarchy node: a binary tree of objects sorted along the
+// longest axis of their bounding box, so a ray only tests the objects whose boxes it
+// crosses. Use NewBVH to build one.
+type BVHNode struct {
+	Left, Right Hittable
+	bbox        AABB
+}
+
+// NewBVH returns a bounding volume hierarchy of the objects (the slice is reordered), or
+// the object itself when there is only one. The objects shouldn't move afterwards.
+// Objects with infinite boxes (e.g. Plane) are better left out, in the Scene directly.
+func NewBVH(objects []Hittable) Hittable {
+	switch len(objects) {
+	case 0:
+		return &Scene{}
+	case 1:
+		return objects[0]
+	}
+	bbox := EmptyAABB
+	for _, o := range objects {
+		bbox = UnionAABB(bbox, o.BoundingBox())
+	}
+	axis := bbox.LongestAxis()
+	slices.SortFunc(objects, func(a, b Hittable) int {
+		return cmp.Compare(a.BoundingBox().Centroid().Components()[axis], b.BoundingBox().Centroid().Components()[axis])
+	})
+	mid := len(objects) / 2
+	return &BVHNode{Left: NewBVH(objects[:mid]), Right: NewBVH(objects[mid:]), bbox: bbox}
+}
+
+func (n *BVHNode) Hit(r *Ray, i Interval, hr *HitRecord) bool {
+	if !n.bbox.Hit(r, i) {
+		return false
+	}
+	hitLeft := n.Left.Hit(r, i, hr)
+	if hitLeft {
+		i.End = hr.T
+	}
+	return n.Right.Hit(r, i, hr) || hitLeft
+}
+
+func (n *BVHNode) BoundingBox() AABB {
+	return n.bbox
+}
@@ -0,0 +1,46 @@
+package ray
+
+import (
+	"math"
+	"testing"
+)
+
+func TestBVHSameAsList(t *testing.T) {
+	rng := RandForTests()
+	objects := make([]Hittable, 0, 100)
+	for range 100 {
+		objects = append(objects, NewSphere(RandomInRange(rng, Interval{-5, 5}), 0.1+0.4*rng.Float64(), Lambertian{}))
+	}
+	list := &Scene{Objects: objects}
+	bvh := NewBVH(append([]Hittable(nil), objects...))
+	if bvh.BoundingBox() != list.BoundingBox() {
+		t.Errorf("BoundingBox() = %v, want %v", bvh.BoundingBox(), list.BoundingBox())
+	}
+	var want, got HitRecord
+	for range 1000 {
+		r := NewRay(rng, RandomInRange(rng, Interval{-8, 8}), RandomUnitVector(rng))
+		hit := list.Hit(r, FrontEpsilon, &want)
+		if bvhHit := bvh.Hit(r, FrontEpsilon, &got); bvhHit != hit || (hit && math.Abs(got.T-want.T) > 1e-12) {
+			t.Fatalf("BVH Hit() = %v at %v, want %v at %v", bvhHit, got.T, hit, want.T)
+		}
+	}
+}
+
+func TestNewBVHSmall(t *testing.T) {
+	if _, ok := NewBVH(nil).(*Scene); !ok {
+		t.Errorf("NewBVH(nil) should be an empty group")
+	}
+	s := NewSphere(Vec3{}, 1, Lambertian{})
+	if NewBVH([]Hittable{s}) != s {
+		t.Errorf("NewBVH() of one object should be that object")
+	}
+}
+
+func BenchmarkBVHHit(b *testing.B) {
+	mesh := TessellateSphere(Vec3{0, 0, -3}, 1, 32, 64, Lambertian{})
+	r := NewRay(RandForTests(), Vec3{0, 0, 0}, Vec3{0.1, 0.1, -1})
+	var hr HitRecord
+	for b.Loop() {
+		mesh.Hit(r, FrontEpsilon, &hr)
+	}
+}
@@ -25,22 +25,79 @@ type Camera struct {
 	FocalLength float64
 	// FocusDistance is the distance from the camera to the plane that will be in sharp focus.
 	// Objects at this distance appear sharp; closer/farther objects are blurred based on Aperture.
-	// If zero, defaults to FocalLength.
+	// If zero, defaults to FocalLength. See also FocusOn and AutoFocus.
 	FocusDistance float64
+	// AutoFocus, when set, makes Initialize set FocusDistance to the distance from Position
+	// to that point (see FocusOn), so it stays in focus as the camera moves.
+	AutoFocus *Vec3
 	// Aperture is the diameter of the camera's aperture. Zero means pinhole (no blur).
 	// Larger aperture = more blur for out-of-focus objects (shallower depth of field).
 	Aperture float64
+	// ApertureBlades, when more than 2, makes the aperture a regular polygon with that many
+	// sides (inscribed in the Aperture diameter circle) instead of a disk, giving polygonal
+	// bokeh (out of focus highlights). Zero (default) means round.
+	ApertureBlades int
+	// Orthographic switches to a parallel projection: all the rays go in the view direction,
+	// from origins spread over the viewport (centered on Position), so there is no perspective
+	// (nor depth of field, Aperture is ignored). Useful for technical renders and debugging.
+	Orthographic bool
+	// OrthoHeight is the height of the orthographic viewport in world units. If zero,
+	// defaults to the height covered by VerticalFoV at the LookAt distance.
+	OrthoHeight float64
+	// Time0 and Time1 are the shutter open and close times. When Time1 > Time0, each ray
+	// gets a random time in [Time0, Time1) and moving objects are motion blurred. They are
+	// clamped to [0, 1] by Initialize: the time range over which objects move (and are
+	// bounded, see MovingSphere).
+	// Zero values (default) means an instantaneous shutter at time 0.
+	Time0, Time1 float64
+	// PixelAspect is the width over height of the image's pixels, for displays with
+	// non-square pixels (e.g. 0.5 for one pixel per terminal character cell, which are
+	// about twice as tall as wide). The viewport width is then the image's width/height
+	// ratio times PixelAspect times the viewport height. If zero, defaults to 1 (square).
+	PixelAspect float64
 	// Computed fields (initialized by Initialize)
 	pixel00      Vec3
 	pixelXVector Vec3
 	pixelYVector Vec3
 	defocusDiskU Vec3 // basis vector for lens disk (right)
 	defocusDiskV Vec3 // basis vector for lens disk (up)
+	orthoDir     Vec3 // direction of all the rays when Orthographic
+	u, v, w      Vec3 // orthonormal basis: right, up, backward (see Right, ViewUp, Forward)
+}
+
+// NewCamera returns a camera at lookFrom looking at lookAt, with the given up direction
+// and vertical field of view (in degrees). The other fields are the defaults (pinhole, etc.)
+// and, like for any Camera, Initialize must be called before use.
+func NewCamera(lookFrom, lookAt, up Vec3, vfov float64) *Camera {
+	return &Camera{Position: lookFrom, LookAt: lookAt, Up: up, VerticalFoV: vfov}
+}
+
+// FocusOn sets FocusDistance so that target is in sharp focus (with an Aperture).
+func (c *Camera) FocusOn(target Vec3) {
+	c.FocusDistance = Length(Sub(c.Position, target))
+}
+
+// Right returns the unit vector pointing to the right of the image (u), valid after Initialize.
+func (c *Camera) Right() Vec3 {
+	return c.u
+}
+
+// ViewUp returns the unit vector pointing to the top of the image (v), valid after Initialize.
+// It's Up made orthogonal to the view direction (the Up field itself can be any length and tilted).
+func (c *Camera) ViewUp() Vec3 {
+	return c.v
+}
+
+// Forward returns the unit view direction (-w), valid after Initialize.
+func (c *Camera) Forward() Vec3 {
+	return Neg(c.w)
 }
 
 // Initialize computes the viewport parameters for the given image dimensions.
 // Sets default values for any zero-valued fields. Must be called before rendering.
+// Dimensions below 1 are treated as 1.
 func (c *Camera) Initialize(width, height int) {
+	width, height = max(width, 1), max(height, 1)
 	var zero Vec3
 	// Set defaults for zero-valued fields
 	if c.FocalLength == 0 {
@@ -52,9 +109,13 @@ func (c *Camera) Initialize(width, height int) {
 	if c.Up == zero {
 		c.Up = Vec3{0, 1, 0}
 	}
+	if c.AutoFocus != nil {
+		c.FocusOn(*c.AutoFocus)
+	}
 	if c.FocusDistance == 0 {
 		c.FocusDistance = c.FocalLength
 	}
+	c.Time0, c.Time1 = ZeroOne.Clamp(c.Time0), ZeroOne.Clamp(c.Time1)
 	// If both Position and LookAt are at origin, set LookAt to look down -Z
 	if c.Position == zero && c.LookAt == zero {
 		c.LookAt = Vec3{0, 0, -1}
@@ -77,8 +138,15 @@ func (c *Camera) Initialize(width, height int) {
 	// v: points up in camera space (w × u, perpendicular to both, adjusted by Up)
 	// Note: Changing Up rotates the camera around the view axis (roll).
 	w := Unit(viewDirection)
-	u := Unit(Cross(c.Up, w))
+	right := Cross(c.Up, w)
+	if NearZero(right) {
+		// Up is along the view direction (looking straight up or down): any other
+		// up works, pick the axis least aligned with w.
+		right = Cross(leastAlignedAxis(w), w)
+	}
+	u := Unit(right)
 	v := Cross(w, u)
+	c.u, c.v, c.w = u, v, w
 
 	// Compute defocus disk basis vectors for depth of field
 	// The disk radius is aperture/2, and these vectors define the disk's orientation
@@ -91,7 +159,16 @@ func (c *Camera) Initialize(width, height int) {
 	// viewportHeight = 2 * focalLength * tan(fov/2)
 	theta := c.VerticalFoV * (math.Pi / 180.0) // degrees to radians
 	viewportHeight := 2.0 * c.FocalLength * math.Tan(theta/2.0)
-	aspectRatio := float64(width) / float64(height)
+	if c.Orthographic {
+		viewportHeight = c.OrthoHeight
+		if viewportHeight <= 0 {
+			viewportHeight = 2.0 * Length(viewDirection) * math.Tan(theta/2.0)
+		}
+	}
+	if c.PixelAspect <= 0 {
+		c.PixelAspect = 1
+	}
+	aspectRatio := float64(width) / float64(height) * c.PixelAspect
 	viewportWidth := aspectRatio * viewportHeight
 
 	// Viewport edges in world coordinates
@@ -101,9 +178,27 @@ func (c *Camera) Initialize(width, height int) {
 	c.pixelYVector = SDiv(vertical, float64(height))
 	// Upper left corner of viewport
 	upperLeftCorner := c.Position.Minus(SMul(w, c.FocalLength), horizontal.Times(0.5), vertical.Times(0.5))
+	if c.Orthographic {
+		// The viewport goes through Position, rays are all going forward (-w).
+		upperLeftCorner = c.Position.Minus(horizontal.Times(0.5), vertical.Times(0.5))
+		c.orthoDir = Neg(w)
+	}
 	c.pixel00 = upperLeftCorner.Plus(Add(c.pixelXVector, c.pixelYVector).Times(0.5)) // center of pixel (0,0)
 }
 
+// leastAlignedAxis returns the unit axis vector most perpendicular to v.
+func leastAlignedAxis(v Vec3) Vec3 {
+	ax, ay, az := math.Abs(v.x), math.Abs(v.y), math.Abs(v.z)
+	switch {
+	case ax <= ay && ax <= az:
+		return Vec3{1, 0, 0}
+	case ay <= az:
+		return Vec3{0, 1, 0}
+	default:
+		return Vec3{0, 0, 1}
+	}
+}
+
 // GetRay generates a ray from the camera through the specified pixel coordinates,
 // with optional depth of field blur if Aperture > 0.
 // The offsets (offsetX, offsetY) allow for sub-pixel sampling:
@@ -111,6 +206,30 @@ func (c *Camera) Initialize(width, height int) {
 //   - (-0.5, -0.5) = upper-left corner
 //   - (0.5, 0.5) = lower-right corner
 func (c *Camera) GetRay(rng rand.Rand, pixelX, pixelY, offsetX, offsetY float64) *Ray {
+	var lensX, lensY float64
+	if c.Aperture > 0 && !c.Orthographic {
+		lensX, lensY = c.lensSample(rng)
+	}
+	return c.rayThrough(rng, pixelX, pixelY, offsetX, offsetY, lensX, lensY)
+}
+
+// lensSample returns a random point on the unit lens disk (or polygon, see ApertureBlades).
+func (c *Camera) lensSample(rng rand.Rand) (x, y float64) {
+	if c.ApertureBlades > 2 {
+		return InPolygon(rng, c.ApertureBlades)
+	}
+	return rng.InDisc(1.0) // Sample unit disk
+}
+
+// symmetricLens returns true if the lens shape is symmetric about its center, i.e. the
+// mirror image (-x, -y) of a lensSample is as likely as (x, y).
+func (c *Camera) symmetricLens() bool {
+	return c.ApertureBlades <= 2 || c.ApertureBlades%2 == 0
+}
+
+// rayThrough is GetRay with the given point (lensX, lensY) of the unit lens, used when
+// Aperture > 0.
+func (c *Camera) rayThrough(rng rand.Rand, pixelX, pixelY, offsetX, offsetY, lensX, lensY float64) *Ray {
 	// Compute the point on the viewport
 	// offset (0,0) = pixel center, pixel00 already points to center of pixel (0,0)
 	pixelSample := c.pixel00.Plus(
@@ -122,27 +241,152 @@ func (c *Camera) GetRay(rng rand.Rand, pixelX, pixelY, offsetX, offsetY float64)
 	rayOrigin := c.Position
 	rayDirection := Sub(pixelSample, c.Position)
 
-	// If aperture > 0, simulate depth of field by sampling from lens disk
-	if c.Aperture > 0 {
-		// Sample random point on lens disk
-		dx, dy := rng.InDisc(1.0) // Sample unit disk
-		offset := Add(SMul(c.defocusDiskU, dx), SMul(c.defocusDiskV, dy))
+	if c.Orthographic {
+		// Parallel rays from the viewport itself.
+		rayOrigin = pixelSample
+		rayDirection = c.orthoDir
+	} else if c.Aperture > 0 {
+		// If aperture > 0, simulate depth of field from the lens sample
+		offset := Add(SMul(c.defocusDiskU, lensX), SMul(c.defocusDiskV, lensY))
 
 		// Compute the focus point: where the center ray hits the focus plane
 		// Focus plane is FocusDistance away from camera along view direction
 		focusTime := c.FocusDistance / c.FocalLength
-		focusPoint := Add(c.Position, SMul(rayDirection, focusTime))
+		focusPoint := AddScaled(c.Position, rayDirection, focusTime)
 
 		// Ray now originates from offset position on lens disk and aims at focus point
 		rayOrigin = Add(c.Position, offset)
 		rayDirection = Sub(focusPoint, rayOrigin)
 	}
 
-	return NewRay(rng, rayOrigin, rayDirection)
+	ray := NewRay(rng, rayOrigin, rayDirection)
+	if c.Time1 > c.Time0 {
+		ray.Time = rng.Float64Range(c.Time0, c.Time1)
+	}
+	return ray
+}
+
+// lookDirection returns the unit view direction (from Position to LookAt), -Z when they are the same.
+func (c *Camera) lookDirection() Vec3 {
+	dir := Sub(c.LookAt, c.Position)
+	if NearZero(dir) {
+		return Vec3{0, 0, -1}
+	}
+	return Unit(dir)
+}
+
+// upDirection returns the unit Up vector, Y when not set.
+func (c *Camera) upDirection() Vec3 {
+	if c.Up == (Vec3{}) {
+		return Vec3{0, 1, 0}
+	}
+	return Unit(c.Up)
+}
+
+// move translates both Position and LookAt by delta, so the view direction is unchanged.
+func (c *Camera) move(delta Vec3) {
+	c.Position = Add(c.Position, delta)
+	c.LookAt = Add(c.LookAt, delta)
+}
+
+// MoveForward moves the camera (and its LookAt point) by d along the view direction,
+// backward when d is negative. Initialize must be called again before rendering.
+func (c *Camera) MoveForward(d float64) {
+	c.move(SMul(c.lookDirection(), d))
+}
+
+// Strafe moves the camera (and its LookAt point) sideways by d, to the right of the image
+// when positive (the u basis vector). Initialize must be called again before rendering.
+func (c *Camera) Strafe(d float64) {
+	c.move(SMul(Unit(Cross(c.lookDirection(), c.upDirection())), d))
+}
+
+// MoveUp moves the camera (and its LookAt point) by d along Up.
+// Initialize must be called again before rendering.
+func (c *Camera) MoveUp(d float64) {
+	c.move(SMul(c.upDirection(), d))
+}
+
+// Frame points the camera at the center of box and moves Position back (or forward) along
+// the current view direction so that the box's bounding sphere, enlarged by margin (e.g.
+// 0.1 for 10%), fits the field of view of a width x height image: the vertical one, or
+// the horizontal one for portrait images (taking PixelAspect into account). A zero size
+// is framed as a square. With an Aperture, the focus is set on the center; orthographic
+// cameras get an OrthoHeight covering the sphere instead. An empty or unbounded box
+// (see Scene.FiniteBoundingBox) leaves the camera unchanged.
+// Initialize must be called again before rendering.
+func (c *Camera) Frame(box AABB, margin float64, width, height int) {
+	if !box.IsFinite() {
+		return
+	}
+	center := box.Centroid()
+	radius := (1 + margin) * Length(Vec3{box.X.Length(), box.Y.Length(), box.Z.Length()}) / 2
+	fov := c.VerticalFoV
+	if fov == 0 {
+		fov = 90
+	}
+	aspectRatio := 1.0
+	if width > 0 && height > 0 {
+		aspectRatio = float64(width) / float64(height)
+		if c.PixelAspect > 0 {
+			aspectRatio *= c.PixelAspect
+		}
+	}
+	// Half angle of the narrowest of the vertical and horizontal fields of view.
+	halfAngle := math.Atan(math.Tan(fov*math.Pi/360) * min(aspectRatio, 1))
+	distance := radius / math.Sin(halfAngle)
+	if radius == 0 {
+		distance = 1 // a point: anywhere in front of it
+	}
+	c.Position = Sub(center, SMul(c.lookDirection(), distance))
+	c.LookAt = center
+	if c.Aperture > 0 {
+		c.FocusDistance = distance
+	}
+	if c.Orthographic {
+		c.OrthoHeight = 2 * radius / min(aspectRatio, 1)
+	}
+}
+
+// InPolygon returns a uniformly distributed random point inside the regular polygon
+// with n sides inscribed in the unit circle, with a vertex at the top (0, 1).
+func InPolygon(rng rand.Rand, n int) (x, y float64) {
+	// Pick one of the n identical triangles (center, vertex k, vertex k+1),
+	// then a uniform point in it (folding the parallelogram back into the triangle).
+	k := float64(rng.IntN(n))
+	step := 2 * math.Pi / float64(n)
+	sinA, cosA := math.Sincos(math.Pi/2 + k*step)
+	sinB, cosB := math.Sincos(math.Pi/2 + (k+1)*step)
+	a, b := rng.Float64(), rng.Float64()
+	if a+b > 1 {
+		a, b = 1-a, 1-b
+	}
+	return a*cosA + b*cosB, a*sinA + b*sinB
+}
+
+// DefaultSceneCamera returns the camera for DefaultScene, also used by Tracer.Render
+// when called with a nil scene. It's a new camera each time, like RichSceneCamera's.
+func DefaultSceneCamera() *Camera {
+	c := &Camera{
+		Position:    Vec3{-2, 2, 1},
+		LookAt:      Vec3{0, 0, -1},
+		VerticalFoV: 20.0,
+		Aperture:    .1,
+	}
+	c.FocusOn(c.LookAt)
+	return c
 }
 
-func RichSceneCamera() Camera {
-	return Camera{
+// RichSceneCamera returns the camera for RichScene, the book's cover view:
+//   - Position (13, 2, 3) looking at the origin with Y up
+//   - VerticalFoV 20°
+//   - Aperture 0.1 with FocalLength and FocusDistance 10 (so the focus plane goes near
+//     the origin, where the big spheres are)
+//
+// It's a new camera each time, so callers can change any of the fields, e.g. Aperture 0
+// for no depth of field, without affecting others.
+func RichSceneCamera() *Camera {
+	return &Camera{
 		Position:      Vec3{13, 2, 3},
 		LookAt:        Vec3{0, 0, 0},
 		Up:            Vec3{0, 1, 0},
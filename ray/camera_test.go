@@ -1,6 +1,7 @@
 package ray
 
 import (
+	"math"
 	"testing"
 
 	"fortio.org/rand"
@@ -34,6 +35,40 @@ func TestCamera_Initialize_PositionEqualsLookAt(t *testing.T) {
 	}
 }
 
+func hasNaN(vs ...Vec3) bool {
+	for _, v := range vs {
+		if math.IsNaN(v.x) || math.IsNaN(v.y) || math.IsNaN(v.z) || math.IsInf(v.x+v.y+v.z, 0) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestCamera_Initialize_Degenerate(t *testing.T) {
+	tests := []struct {
+		name          string
+		camera        Camera
+		width, height int
+	}{
+		{"zero size", Camera{}, 0, 0},
+		{"zero height", Camera{}, 80, 0},
+		{"negative size", Camera{}, -3, -1},
+		{"looking straight down", Camera{Position: Vec3{0, 5, 0}, LookAt: Vec3{0, 0, 0}}, 10, 10},
+		{"looking straight up", Camera{Position: Vec3{1, 0, 0}, LookAt: Vec3{1, 3, 0}, Up: Vec3{0, 2, 0}}, 10, 10},
+	}
+	for _, tt := range tests {
+		c := tt.camera
+		c.Initialize(tt.width, tt.height)
+		if hasNaN(c.u, c.v, c.w, c.pixel00, c.pixelXVector, c.pixelYVector) {
+			t.Errorf("%s: Initialize() gave NaN/Inf vectors u %v v %v w %v pixel00 %v dx %v dy %v",
+				tt.name, c.u, c.v, c.w, c.pixel00, c.pixelXVector, c.pixelYVector)
+		}
+		if math.Abs(Dot(c.u, c.w)) > 1e-12 || math.Abs(Length(c.u)-1) > 1e-12 {
+			t.Errorf("%s: Initialize() u %v isn't a unit vector orthogonal to w %v", tt.name, c.u, c.w)
+		}
+	}
+}
+
 func TestCamera_Initialize_Defaults(t *testing.T) {
 	// Test that Initialize sets default values for zero fields
 	camera := Camera{}
@@ -174,6 +209,50 @@ func TestCamera_FocusDistance_Default(t *testing.T) {
 	}
 }
 
+func TestCamera_FocusOn(t *testing.T) {
+	camera := Camera{Position: Vec3{1, 2, 3}, LookAt: Vec3{0, 0, -1}, Aperture: 0.2}
+	target := Vec3{4, 6, 3} // 3-4-5 triangle from Position
+	camera.FocusOn(target)
+	if camera.FocusDistance != 5 {
+		t.Errorf("FocusOn(%v) FocusDistance = %g, want 5", target, camera.FocusDistance)
+	}
+	// Initialize keeps it.
+	camera.Initialize(10, 10)
+	if camera.FocusDistance != 5 {
+		t.Errorf("FocusDistance after Initialize = %g, want 5", camera.FocusDistance)
+	}
+}
+
+func TestCamera_AutoFocus(t *testing.T) {
+	target := Vec3{0, 0, -4}
+	camera := Camera{Position: Vec3{0, 0, 0}, LookAt: Vec3{0, 0, -1}, Aperture: 0.2, FocusDistance: 1, AutoFocus: &target}
+	camera.Initialize(10, 10)
+	if camera.FocusDistance != 4 {
+		t.Errorf("AutoFocus FocusDistance = %g, want 4", camera.FocusDistance)
+	}
+	// Follows the camera as it moves.
+	camera.MoveForward(1)
+	camera.Initialize(10, 10)
+	if math.Abs(camera.FocusDistance-3) > 1e-12 {
+		t.Errorf("AutoFocus FocusDistance after moving = %g, want 3", camera.FocusDistance)
+	}
+	// Also set from the Tracer and applied by Render.
+	tracer := New(11, 11)
+	tracer.AutoFocus = &target // promoted from the embedded Camera
+	tracer.Aperture = 0.5
+	tracer.Render(&Scene{})
+	if tracer.FocusDistance != 4 {
+		t.Errorf("Tracer.AutoFocus FocusDistance = %g, want 4", tracer.FocusDistance)
+	}
+}
+
+func TestDefaultSceneCamera_FocusOnLookAt(t *testing.T) {
+	c := DefaultSceneCamera()
+	if want := Length(Sub(c.Position, c.LookAt)); c.FocusDistance != want {
+		t.Errorf("DefaultSceneCamera FocusDistance = %g, want %g", c.FocusDistance, want)
+	}
+}
+
 func TestCamera_GetRay_PixelCenter(t *testing.T) {
 	// Test that offset (0,0) produces a ray through the exact pixel center
 	// Simple camera setup for easy math verification
@@ -250,7 +329,7 @@ func TestRichSceneCamera_RendersNonBlackImage(t *testing.T) {
 	scene := RichScene(rng)
 
 	tracer := New(width, height)
-	tracer.Camera = RichSceneCamera()
+	tracer.Camera = *RichSceneCamera()
 	tracer.MaxDepth = 10
 	tracer.NumRaysPerPixel = 2 // Low but not 1, to get some antialiasing
 
@@ -281,3 +360,380 @@ func TestRichSceneCamera_RendersNonBlackImage(t *testing.T) {
 
 	t.Logf("Rendered RichScene with %d/%d non-black pixels", nonBlackPixels, totalPixels)
 }
+
+func TestCamera_GetRay_Time(t *testing.T) {
+	rng := RandForTests()
+	camera := Camera{}
+	camera.Initialize(10, 10)
+	if r := camera.GetRay(rng, 5, 5, 0, 0); r.Time != 0 {
+		t.Errorf("Time = %v, want 0 without shutter interval", r.Time)
+	}
+	camera.Time0, camera.Time1 = 0.25, 0.75
+	seen := map[float64]bool{}
+	for range 100 {
+		r := camera.GetRay(rng, 5, 5, 0, 0)
+		if r.Time < 0.25 || r.Time >= 0.75 {
+			t.Fatalf("Time = %v, want in [0.25, 0.75)", r.Time)
+		}
+		seen[r.Time] = true
+	}
+	if len(seen) < 90 {
+		t.Errorf("Expected random times, got only %d distinct values", len(seen))
+	}
+}
+
+func TestCamera_Initialize_ClampsShutter(t *testing.T) {
+	// Rays past time 1 would miss MovingSphere's bounding box (and get culled by a BVH).
+	camera := Camera{Time0: -0.5, Time1: 3}
+	camera.Initialize(10, 10)
+	if camera.Time0 != 0 || camera.Time1 != 1 {
+		t.Errorf("shutter = [%v, %v], want clamped to [0, 1]", camera.Time0, camera.Time1)
+	}
+	rng := RandForTests()
+	ms := &MovingSphere{Center: Vec3{0, 0, -5}, Center1: Vec3{2, 0, -5}, Radius: 0.5}
+	box := ms.BoundingBox()
+	for range 100 {
+		r := camera.GetRay(rng, 5, 5, 0, 0)
+		if c := ms.CenterAt(r.Time); c.x < box.X.Start || c.x > box.X.End {
+			t.Fatalf("center %v at time %v outside of the bounding box %v", c, r.Time, box)
+		}
+	}
+}
+
+func TestRender_MotionBlur(t *testing.T) {
+	// Count pixels that are neither (mostly) lit nor (mostly) dark: blurred ones.
+	blurredPixels := func(obj Hittable) int {
+		tracer := New(32, 32)
+		tracer.Seed = 1
+		tracer.NumRaysPerPixel = 64
+		tracer.Time1 = 1
+		scene := &Scene{Objects: []Hittable{obj}, Background: NoBackground}
+		img := tracer.Render(scene)
+		count := 0
+		for y := range 32 {
+			for x := range 32 {
+				r, _, _, _ := img.At(x, y).RGBA()
+				if r > 0x2000 && r < 0xe000 {
+					count++
+				}
+			}
+		}
+		return count
+	}
+	light := DiffuseLight{Emit: ColorF{1, 1, 1}}
+	static := blurredPixels(&Sphere{Center: Vec3{0, 0, -3}, Radius: 0.8, Mat: light})
+	moving := blurredPixels(&MovingSphere{Center: Vec3{-0.5, 0, -3}, Center1: Vec3{0.5, 0, -3}, Radius: 0.8, Mat: light})
+	if moving < 3*static {
+		t.Errorf("Expected moving sphere to be much more blurred: %d blurred pixels vs %d static", moving, static)
+	}
+}
+
+func TestCamera_Move(t *testing.T) {
+	camera := Camera{Position: Vec3{0, 0, 0}, LookAt: Vec3{0, 0, -2}}
+	tests := []struct {
+		name     string
+		move     func(c *Camera)
+		expected Vec3
+	}{
+		{"forward", func(c *Camera) { c.MoveForward(1) }, Vec3{0, 0, -1}},
+		{"backward", func(c *Camera) { c.MoveForward(-0.5) }, Vec3{0, 0, 0.5}},
+		{"strafe right", func(c *Camera) { c.Strafe(1) }, Vec3{1, 0, 0}},
+		{"strafe left", func(c *Camera) { c.Strafe(-2) }, Vec3{-2, 0, 0}},
+		{"up", func(c *Camera) { c.MoveUp(3) }, Vec3{0, 3, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := camera
+			tt.move(&c)
+			if !Equal(c.Position, tt.expected, 1e-12) {
+				t.Errorf("Position = %v, want %v", c.Position, tt.expected)
+			}
+			if !Equal(Sub(c.LookAt, c.Position), Sub(camera.LookAt, camera.Position), 1e-12) {
+				t.Errorf("view direction changed: LookAt %v from %v", c.LookAt, c.Position)
+			}
+		})
+	}
+	// Strafing follows the image's x axis, whatever the camera orientation.
+	c := Camera{Position: Vec3{1, 2, 3}, LookAt: Vec3{-3, 0, 1}, Up: Vec3{0, 2, 0}}
+	c.Initialize(10, 10)
+	start := c.Position
+	c.Strafe(1)
+	if right := Unit(c.pixelXVector); !Equal(Sub(c.Position, start), right, 1e-12) {
+		t.Errorf("Strafe(1) moved by %v, want %v", Sub(c.Position, start), right)
+	}
+}
+
+func TestInPolygon(t *testing.T) {
+	rng := RandForTests()
+	for _, n := range []int{3, 5, 6} {
+		apothem := math.Cos(math.Pi / float64(n))
+		var sumX, sumY float64
+		maxR := 0.0
+		const samples = 20000
+		for range samples {
+			x, y := InPolygon(rng, n)
+			r := math.Hypot(x, y)
+			if r > 1+1e-12 {
+				t.Fatalf("InPolygon(%d) = (%v, %v) outside the unit circle", n, x, y)
+			}
+			maxR = max(maxR, r)
+			// Inside every edge's half plane: edge k's outward normal is half way between vertices k and k+1.
+			for k := range n {
+				angle := math.Pi/2 + (float64(k)+0.5)*2*math.Pi/float64(n)
+				if d := x*math.Cos(angle) + y*math.Sin(angle); d > apothem+1e-12 {
+					t.Fatalf("InPolygon(%d) = (%v, %v) outside edge %d (%v > %v)", n, x, y, k, d, apothem)
+				}
+			}
+			sumX += x
+			sumY += y
+		}
+		if math.Abs(sumX/samples) > 0.02 || math.Abs(sumY/samples) > 0.02 {
+			t.Errorf("InPolygon(%d) mean = (%v, %v), want centered", n, sumX/samples, sumY/samples)
+		}
+		if maxR < 0.95 {
+			t.Errorf("InPolygon(%d) max radius %v, should reach toward the vertices", n, maxR)
+		}
+	}
+}
+
+func TestCamera_GetRay_ApertureBlades(t *testing.T) {
+	camera := Camera{
+		Position:       Vec3{0, 0, 5},
+		LookAt:         Vec3{0, 0, 0},
+		Aperture:       0.5,
+		ApertureBlades: 6,
+		FocusDistance:  5.0,
+	}
+	camera.Initialize(100, 100)
+	rng := RandForTests()
+	maxDist := camera.Aperture / 2
+	// Point where r crosses the focus plane (z=0).
+	onFocusPlane := func(r *Ray) Vec3 { return r.At(-r.Origin.z / r.Direction.z) }
+	focus := onFocusPlane(camera.GetRay(rng, 50, 50, 0, 0))
+	for range 1000 {
+		r := camera.GetRay(rng, 50, 50, 0, 0)
+		if dist := Length(Sub(r.Origin, camera.Position)); dist > maxDist+1e-12 {
+			t.Fatalf("Ray origin too far from camera: %f > %f", dist, maxDist)
+		}
+		// Whatever the lens point, rays converge on the focus plane.
+		if p := onFocusPlane(r); !Equal(p, focus, 1e-9) {
+			t.Fatalf("Ray from %v reaches the focus plane at %v, not the focus point %v", r.Origin, p, focus)
+		}
+	}
+}
+
+func TestCamera_GetRay_Orthographic(t *testing.T) {
+	camera := Camera{
+		Position:     Vec3{1, 2, 5},
+		LookAt:       Vec3{1, 2, 0},
+		Orthographic: true,
+		OrthoHeight:  4,
+		Aperture:     1, // ignored
+	}
+	camera.Initialize(200, 100)
+	rng := RandForTests()
+	center := camera.GetRay(rng, 100, 50, -0.5, -0.5) // exactly the middle of the image
+	if !Equal(center.Origin, camera.Position, 1e-12) {
+		t.Errorf("center ray origin = %v, want the camera position %v", center.Origin, camera.Position)
+	}
+	for _, p := range [][2]float64{{0, 0}, {199, 0}, {0, 99}, {199, 99}, {37, 81}} {
+		r := camera.GetRay(rng, p[0], p[1], 0, 0)
+		if !Equal(Unit(r.Direction), Vec3{0, 0, -1}, 1e-12) {
+			t.Errorf("ray (%v) direction = %v, want all parallel to {0 0 -1}", p, r.Direction)
+		}
+		if r.Origin.z != 5 {
+			t.Errorf("ray (%v) origin = %v, want on the z=5 viewport", p, r.Origin)
+		}
+	}
+	// The viewport is OrthoHeight tall and keeps the image aspect ratio.
+	topLeft := camera.GetRay(rng, 0, 0, -0.5, -0.5).Origin
+	bottomRight := camera.GetRay(rng, 200, 100, -0.5, -0.5).Origin
+	if size := Sub(bottomRight, topLeft); !Equal(size, Vec3{8, -4, 0}, 1e-12) {
+		t.Errorf("viewport size = %v, want {8 -4 0}", size)
+	}
+	// Default height: what the field of view covers at the LookAt distance.
+	camera.OrthoHeight = 0
+	camera.VerticalFoV = 90
+	camera.Initialize(100, 100)
+	topLeft = camera.GetRay(rng, 0, 0, -0.5, -0.5).Origin
+	if math.Abs(topLeft.y-(2+5)) > 1e-12 {
+		t.Errorf("default ortho viewport top = %v, want %v", topLeft.y, 2+5)
+	}
+}
+
+func TestNewCameraBasis(t *testing.T) {
+	camera := NewCamera(Vec3{1, 2, 3}, Vec3{1, 2, -7}, Vec3{0, 3, 0}, 40)
+	if camera.VerticalFoV != 40 || camera.Position != (Vec3{1, 2, 3}) || camera.Aperture != 0 {
+		t.Errorf("NewCamera() = %+v", camera)
+	}
+	camera.Initialize(100, 50)
+	tests := []struct {
+		name     string
+		got      Vec3
+		expected Vec3
+	}{
+		{"Right", camera.Right(), Vec3{1, 0, 0}},
+		{"ViewUp", camera.ViewUp(), Vec3{0, 1, 0}},
+		{"Forward", camera.Forward(), Vec3{0, 0, -1}},
+	}
+	for _, tt := range tests {
+		if !Equal(tt.got, tt.expected, 1e-12) {
+			t.Errorf("%s() = %v, want %v", tt.name, tt.got, tt.expected)
+		}
+	}
+	// Tilted view: still an orthonormal, right-handed basis matching the image axes.
+	camera = NewCamera(Vec3{13, 2, 3}, Vec3{0, 0, 0}, Vec3{0, 1, 0}, 20)
+	camera.Initialize(100, 50)
+	r, u, f := camera.Right(), camera.ViewUp(), camera.Forward()
+	for _, v := range []Vec3{r, u, f} {
+		if math.Abs(Length(v)-1) > 1e-12 {
+			t.Errorf("basis vector %v is not unit", v)
+		}
+	}
+	if math.Abs(Dot(r, u)) > 1e-12 || math.Abs(Dot(r, f)) > 1e-12 || math.Abs(Dot(u, f)) > 1e-12 {
+		t.Errorf("basis %v %v %v is not orthogonal", r, u, f)
+	}
+	if !Equal(Cross(r, u), Neg(f), 1e-12) {
+		t.Errorf("Right x ViewUp = %v, want -Forward %v", Cross(r, u), Neg(f))
+	}
+	if !Equal(f, Unit(Sub(camera.LookAt, camera.Position)), 1e-12) {
+		t.Errorf("Forward() = %v, want toward LookAt", f)
+	}
+	if Dot(Unit(camera.pixelXVector), r) < 1-1e-12 || Dot(Unit(camera.pixelYVector), u) > -1+1e-12 {
+		t.Errorf("Right/ViewUp don't match the image axes")
+	}
+}
+
+func TestCamera_Frame(t *testing.T) {
+	scene := &Scene{Objects: []Hittable{
+		NewPlane(Vec3{0, 0, 0}, Vec3{0, 1, 0}, Lambertian{}), // unbounded, not framed
+		NewSphere(Vec3{10, 1, -20}, 1, Lambertian{}),
+		NewSphere(Vec3{14, 2, -23}, 2, Lambertian{}),
+		NewBox(Vec3{8, 0, -26}, Vec3{9, 3, -25}, Lambertian{}),
+	}}
+	if scene.BoundingBox().IsFinite() {
+		t.Fatalf("BoundingBox() = %v should be unbounded because of the plane", scene.BoundingBox())
+	}
+	box := scene.FiniteBoundingBox()
+	want := NewAABB(Vec3{8, 0, -26}, Vec3{16, 4, -19})
+	if !Equal(box.Centroid(), want.Centroid(), 1e-3) || !box.Contains(Vec3{8, 0, -26}) || !box.Contains(Vec3{16, 4, -19}) {
+		t.Fatalf("FiniteBoundingBox() = %v, want %v", box, want)
+	}
+	center := box.Centroid()
+	radius := Length(Vec3{box.X.Length(), box.Y.Length(), box.Z.Length()}) / 2
+	// Landscape and portrait images: the sphere fits the narrowest field of view.
+	for _, size := range [][2]int{{40, 30}, {100, 200}} {
+		w, h := size[0], size[1]
+		for _, margin := range []float64{0, 0.2} {
+			camera := NewCamera(Vec3{0, 5, 0}, Vec3{1, 5, -1}, Vec3{0, 1, 0}, 40)
+			camera.Frame(box, margin, w, h)
+			if camera.LookAt != center {
+				t.Errorf("%dx%d margin %v: LookAt = %v, want the center %v", w, h, margin, camera.LookAt, center)
+			}
+			if d := Dot(Unit(Sub(camera.LookAt, camera.Position)), Unit(Vec3{1, 0, -1})); math.Abs(d-1) > 1e-12 {
+				t.Errorf("%dx%d margin %v: view direction changed to %v", w, h, margin, Sub(camera.LookAt, camera.Position))
+			}
+			camera.Initialize(w, h)
+			// The primary rays across the narrowest dimension (middle column for landscape,
+			// middle row for portrait) all intersect the bounding sphere: with no margin, the
+			// extreme ones are tangent.
+			var rays [][2]float64
+			if w >= h {
+				for y := range h + 1 {
+					rays = append(rays, [2]float64{float64(w) / 2, float64(y)})
+				}
+			} else {
+				for x := range w + 1 {
+					rays = append(rays, [2]float64{float64(x), float64(h) / 2})
+				}
+			}
+			for _, p := range rays {
+				r := camera.GetRay(RandForTests(), p[0]-0.5, p[1]-0.5, 0, 0) // pixel edges
+				toCenter := Sub(center, r.Origin)
+				along := Dot(toCenter, Unit(r.Direction))
+				dist := Length(Sub(toCenter, SMul(Unit(r.Direction), along)))
+				if dist > radius*(1+margin)*(1+1e-9) {
+					t.Errorf("%dx%d margin %v: ray through %v misses the bounding sphere: %v > %v", w, h, margin, p, dist, radius*(1+margin))
+				}
+				if margin == 0 && (p == rays[0] || p == rays[len(rays)-1]) && math.Abs(dist-radius) > 1e-9*radius {
+					t.Errorf("%dx%d margin %v: edge ray %v at %v from the center, want tangent at %v", w, h, margin, p, dist, radius)
+				}
+			}
+			// And the whole box is visible.
+			tanHalf := math.Tan(40 * math.Pi / 360)
+			for _, corner := range []Vec3{
+				{box.X.Start, box.Y.Start, box.Z.Start}, {box.X.End, box.Y.Start, box.Z.Start},
+				{box.X.Start, box.Y.End, box.Z.Start}, {box.X.End, box.Y.End, box.Z.Start},
+				{box.X.Start, box.Y.Start, box.Z.End}, {box.X.End, box.Y.Start, box.Z.End},
+				{box.X.Start, box.Y.End, box.Z.End}, {box.X.End, box.Y.End, box.Z.End},
+			} {
+				d := Sub(corner, camera.Position)
+				f := Dot(d, camera.Forward())
+				if math.Abs(Dot(d, camera.ViewUp())/f) > tanHalf || math.Abs(Dot(d, camera.Right())/f) > tanHalf*float64(w)/float64(h) {
+					t.Errorf("%dx%d margin %v: corner %v is out of view", w, h, margin, corner)
+				}
+			}
+		}
+	}
+	// Nothing to frame.
+	camera := NewCamera(Vec3{1, 2, 3}, Vec3{0, 0, 0}, Vec3{0, 1, 0}, 40)
+	camera.Frame(EmptyAABB, 0.1, 40, 30)
+	if camera.Position != (Vec3{1, 2, 3}) || camera.LookAt != (Vec3{}) {
+		t.Errorf("Frame(EmptyAABB) moved the camera to %v looking at %v", camera.Position, camera.LookAt)
+	}
+}
+
+func TestCamera_PixelAspect(t *testing.T) {
+	base := Camera{Position: Vec3{1, 2, 3}, LookAt: Vec3{0, 0, -1}, VerticalFoV: 50}
+	// 1 (like the default 0) keeps the square pixels geometry.
+	def, square := base, base
+	square.PixelAspect = 1
+	def.Initialize(40, 30)
+	square.Initialize(40, 30)
+	if def != square {
+		t.Errorf("PixelAspect 1 camera = %+v, want the default's %+v", square, def)
+	}
+	// Half as wide pixels (e.g. one per terminal cell): the same view as twice as many
+	// square ones vertically.
+	cells := base
+	cells.PixelAspect = 0.5
+	cells.Initialize(40, 15)
+	for _, p := range [][2]int{{0, 0}, {39, 14}, {20, 7}, {5, 11}} {
+		x, y := p[0], p[1]
+		got := cells.GetRay(RandForTests(), float64(x), float64(y), 0, 0)
+		want := def.GetRay(RandForTests(), float64(x), 2*float64(y)+0.5, 0, 0)
+		if !Equal(Unit(got.Direction), Unit(want.Direction), 1e-12) {
+			t.Errorf("pixel %d,%d direction = %v, want %v", x, y, Unit(got.Direction), Unit(want.Direction))
+		}
+	}
+}
+
+func TestRichSceneCamera_DocumentedValues(t *testing.T) {
+	c := RichSceneCamera()
+	c.Initialize(160, 90)
+	want := Camera{
+		Position:      Vec3{13, 2, 3},
+		LookAt:        Vec3{0, 0, 0},
+		Up:            Vec3{0, 1, 0},
+		VerticalFoV:   20,
+		Aperture:      0.1,
+		FocalLength:   10,
+		FocusDistance: 10,
+		PixelAspect:   1,
+	}
+	got := *c
+	got.pixel00, got.pixelXVector, got.pixelYVector = Vec3{}, Vec3{}, Vec3{}
+	got.defocusDiskU, got.defocusDiskV, got.orthoDir = Vec3{}, Vec3{}, Vec3{}
+	got.u, got.v, got.w = Vec3{}, Vec3{}, Vec3{}
+	if got != want {
+		t.Errorf("RichSceneCamera() after Initialize = %+v, want %+v", got, want)
+	}
+	if f := c.Forward(); !Equal(f, Unit(Vec3{-13, -2, -3}), 1e-12) {
+		t.Errorf("Forward() = %v, want toward the origin", f)
+	}
+	// Changing one doesn't affect the next one.
+	c.Aperture = 0
+	if RichSceneCamera().Aperture != 0.1 {
+		t.Error("RichSceneCamera() should return a fresh camera each time")
+	}
+}
@@ -0,0 +1,88 @@
+package ray
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// checkpointMagic starts (and versions) the SaveCheckpoint format.
+const checkpointMagic = "TRAYCKP1"
+
+// checkpointHeader is what precedes the pixels in a checkpoint.
+type checkpointHeader struct {
+	Magic                 [len(checkpointMagic)]byte
+	Width, Height, Passes uint32
+}
+
+// SaveCheckpoint writes the state of the last RenderProgressive (or RenderWithin): the
+// image size, the number of completed passes and the running sum of their samples, so
+// an interrupted render can be resumed later with LoadCheckpoint. The format is the
+// magic "TRAYCKP1" followed by little endian uint32 width, height and passes, then the
+// float64 r, g, b of each pixel, row by row.
+func (t *Tracer) SaveCheckpoint(w io.Writer) error {
+	if t.passes == 0 || len(t.accum) != t.width*t.height {
+		return errors.New("no progressive render to checkpoint")
+	}
+	header := checkpointHeader{
+		Width:  uint32(t.width),  //nolint:gosec // image sizes fit.
+		Height: uint32(t.height), //nolint:gosec // image sizes fit.
+		Passes: uint32(t.passes), //nolint:gosec // so do the passes.
+	}
+	copy(header.Magic[:], checkpointMagic)
+	pixels := make([]float64, 0, 3*len(t.accum))
+	for _, c := range t.accum {
+		pixels = append(pixels, c.x, c.y, c.z)
+	}
+	bw := bufio.NewWriter(w)
+	if err := binary.Write(bw, binary.LittleEndian, header); err != nil {
+		return fmt.Errorf("could not write checkpoint: %w", err)
+	}
+	if err := binary.Write(bw, binary.LittleEndian, pixels); err != nil {
+		return fmt.Errorf("could not write checkpoint: %w", err)
+	}
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("could not write checkpoint: %w", err)
+	}
+	return nil
+}
+
+// LoadCheckpoint reads a checkpoint written by SaveCheckpoint, for a Tracer of the same
+// size. The next RenderProgressive (or RenderWithin) then resumes after the saved passes
+// instead of starting over. With the same (non 0) Seed, scene and settings, the final
+// image is identical to an uninterrupted render's.
+func (t *Tracer) LoadCheckpoint(r io.Reader) error {
+	br := bufio.NewReader(r)
+	var header checkpointHeader
+	if err := binary.Read(br, binary.LittleEndian, &header); err != nil {
+		return fmt.Errorf("could not read checkpoint header: %w", err)
+	}
+	if string(header.Magic[:]) != checkpointMagic {
+		return fmt.Errorf("not a checkpoint, magic is %q instead of %q", header.Magic[:], checkpointMagic)
+	}
+	if int(header.Width) != t.width || int(header.Height) != t.height {
+		return fmt.Errorf("checkpoint is for a %dx%d image, not %dx%d", header.Width, header.Height, t.width, t.height)
+	}
+	pixels := make([]float64, 3*t.width*t.height)
+	if err := binary.Read(br, binary.LittleEndian, pixels); err != nil {
+		return fmt.Errorf("could not read checkpoint pixels: %w", err)
+	}
+	accum := make([]ColorF, t.width*t.height)
+	for i := range accum {
+		accum[i] = ColorF{pixels[3*i], pixels[3*i+1], pixels[3*i+2]}
+	}
+	t.accum, t.passes, t.resume = accum, int(header.Passes), header.Passes > 0
+	return nil
+}
+
+// showAccum sets the pixels to the average of the passes in accum.
+func (t *Tracer) showAccum() {
+	div := 1.0 / float64(t.passes)
+	for y := range t.height {
+		for x := range t.width {
+			t.setPixel(x, y, SMul(t.accum[y*t.width+x], div))
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package ray
+
+import (
+	"bytes"
+	"image"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCheckpointResume(t *testing.T) {
+	const w, h, rays = 12, 9, 6
+	newTracer := func() *Tracer {
+		rt := New(w, h)
+		rt.Seed = 42
+		rt.NumRaysPerPixel = rays
+		rt.KeepHDR = true
+		return rt
+	}
+	expected := newTracer()
+	want := expected.RenderProgressive(nil, nil)
+
+	// Interrupted after 2 passes (e.g. Ctrl-C), saved, then resumed by a new Tracer.
+	first := newTracer()
+	first.RenderProgressive(nil, func(_ *image.RGBA, pass int) bool { return pass < 2 })
+	var buf bytes.Buffer
+	if err := first.SaveCheckpoint(&buf); err != nil {
+		t.Fatalf("SaveCheckpoint() error: %v", err)
+	}
+	if size := buf.Len(); size != len(checkpointMagic)+12+w*h*3*8 {
+		t.Errorf("checkpoint size = %d bytes, want %d", size, len(checkpointMagic)+12+w*h*3*8)
+	}
+	resumed := newTracer()
+	if err := resumed.LoadCheckpoint(&buf); err != nil {
+		t.Fatalf("LoadCheckpoint() error: %v", err)
+	}
+	var passes []int
+	got := resumed.RenderProgressive(nil, func(_ *image.RGBA, pass int) bool {
+		passes = append(passes, pass)
+		return true
+	})
+	if !slices.Equal(passes, []int{3, 4, 5, 6}) {
+		t.Errorf("resumed passes = %v, want [3 4 5 6]", passes)
+	}
+	if !slices.Equal(got.Pix, want.Pix) {
+		t.Errorf("resumed image differs from the uninterrupted render")
+	}
+	if !slices.Equal(resumed.HDRBuffer(), expected.HDRBuffer()) {
+		t.Errorf("resumed HDR buffer differs from the uninterrupted render")
+	}
+	// The checkpoint is only used once: rendering again starts over.
+	if again := resumed.RenderProgressive(nil, nil); !slices.Equal(again.Pix, want.Pix) {
+		t.Errorf("render after the resumed one differs from the uninterrupted render")
+	}
+}
+
+func TestCheckpointErrors(t *testing.T) {
+	if err := New(4, 4).SaveCheckpoint(&bytes.Buffer{}); err == nil {
+		t.Error("SaveCheckpoint() without a progressive render should fail")
+	}
+	rt := New(4, 4)
+	rt.Seed = 1
+	rt.RenderProgressive(nil, nil)
+	var buf bytes.Buffer
+	if err := rt.SaveCheckpoint(&buf); err != nil {
+		t.Fatalf("SaveCheckpoint() error: %v", err)
+	}
+	data := buf.Bytes()
+	tests := []struct {
+		name    string
+		tracer  *Tracer
+		data    []byte
+		wantErr string
+	}{
+		{"wrong size", New(4, 5), data, "checkpoint is for a 4x4 image, not 4x5"},
+		{"bad magic", New(4, 4), append([]byte("NOTACKPT"), data[8:]...), "not a checkpoint"},
+		{"truncated header", New(4, 4), data[:10], "could not read checkpoint header"},
+		{"truncated pixels", New(4, 4), data[:len(data)-1], "could not read checkpoint pixels"},
+	}
+	for _, tt := range tests {
+		err := tt.tracer.LoadCheckpoint(bytes.NewReader(tt.data))
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: LoadCheckpoint() error = %v, want %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
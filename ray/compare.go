@@ -0,0 +1,38 @@
+package ray
+
+import (
+	"image"
+	"math"
+)
+
+// CompareImages returns how much b differs from a, over the red, green and blue channels
+// of all the pixels (alpha is ignored): the largest and the mean absolute difference of
+// the 8 bit values, and the peak signal to noise ratio in dB, +Inf for identical images
+// (above ~40 dB differences are hard to see). Useful to check that an optimization didn't
+// change a render, or only within a tolerance. Images of different sizes are reported as
+// completely different: 255, 255 and 0.
+func CompareImages(a, b *image.RGBA) (maxDiff, meanDiff, psnr float64) {
+	ba, bb := a.Bounds(), b.Bounds()
+	if ba.Size() != bb.Size() {
+		return 255, 255, 0
+	}
+	var sum, sumSq float64
+	for y := range ba.Dy() {
+		rowA := a.Pix[a.PixOffset(ba.Min.X, ba.Min.Y+y):]
+		rowB := b.Pix[b.PixOffset(bb.Min.X, bb.Min.Y+y):]
+		for i := range 4 * ba.Dx() {
+			if i%4 == 3 {
+				continue // alpha
+			}
+			d := math.Abs(float64(rowA[i]) - float64(rowB[i]))
+			maxDiff = max(maxDiff, d)
+			sum += d
+			sumSq += d * d
+		}
+	}
+	n := float64(3 * ba.Dx() * ba.Dy())
+	if n == 0 || sumSq == 0 {
+		return maxDiff, 0, math.Inf(1)
+	}
+	return maxDiff, sum / n, 10 * math.Log10(255*255/(sumSq/n))
+}
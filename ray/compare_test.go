@@ -0,0 +1,51 @@
+package ray
+
+import (
+	"image"
+	"math"
+	"testing"
+)
+
+func TestCompareImages(t *testing.T) {
+	a := skyGround()
+	b := image.NewRGBA(a.Bounds())
+	copy(b.Pix, a.Pix)
+	if maxDiff, meanDiff, psnr := CompareImages(a, b); maxDiff != 0 || meanDiff != 0 || !math.IsInf(psnr, 1) {
+		t.Errorf("CompareImages() of identical images = %v, %v, %v; want 0, 0, +Inf", maxDiff, meanDiff, psnr)
+	}
+	b.Pix[3] = 0 // alpha is ignored
+	b.Pix[4*9+1] -= 10
+	b.Pix[4*20+2] += 20
+	maxDiff, meanDiff, psnr := CompareImages(a, b)
+	n := 3.0 * 8 * 4
+	wantPSNR := 10 * math.Log10(255*255/((10*10+20*20)/n))
+	if maxDiff != 20 || math.Abs(meanDiff-30/n) > 1e-12 || math.Abs(psnr-wantPSNR) > 1e-9 {
+		t.Errorf("CompareImages() = %v, %v, %v; want 20, %v, %v", maxDiff, meanDiff, psnr, 30/n, wantPSNR)
+	}
+	// Only the sizes matter, not the origins.
+	if maxDiff, _, _ := CompareImages(a.SubImage(image.Rect(0, 0, 4, 2)).(*image.RGBA),
+		a.SubImage(image.Rect(4, 0, 8, 2)).(*image.RGBA)); maxDiff != 0 {
+		t.Errorf("CompareImages() of same sub images = %v, want 0", maxDiff)
+	}
+	if maxDiff, meanDiff, psnr := CompareImages(a, image.NewRGBA(image.Rect(0, 0, 4, 8))); maxDiff != 255 || meanDiff != 255 || psnr != 0 {
+		t.Errorf("CompareImages() of different sizes = %v, %v, %v; want 255, 255, 0", maxDiff, meanDiff, psnr)
+	}
+}
+
+func TestCompareImages_Renders(t *testing.T) {
+	// The use case: checking a render didn't change, or only within a tolerance.
+	render := func(rays int) *image.RGBA {
+		rt := New(32, 18)
+		rt.Seed = 42
+		rt.NumRaysPerPixel = rays
+		return rt.Render(nil)
+	}
+	ref := render(16)
+	if maxDiff, _, _ := CompareImages(ref, render(16)); maxDiff != 0 {
+		t.Errorf("same render differs by %v", maxDiff)
+	}
+	_, meanDiff, psnr := CompareImages(ref, render(4))
+	if meanDiff == 0 || psnr < 20 {
+		t.Errorf("noisier render of the same scene: mean diff %v, PSNR %v dB; want > 0 and >= 20", meanDiff, psnr)
+	}
+}
@@ -0,0 +1,104 @@
+package ray
+
+import (
+	"image"
+	"math"
+)
+
+// Edge stopping parameters for Denoise.
+const (
+	denoiseSigmaColor  = 0.4 // on [0,1] color differences, halved at each pass
+	denoiseNormalPower = 64  // exponent of the normals' cosine: bigger is more edge preserving
+	denoiseSigmaDepth  = 0.05
+)
+
+// atrousKernel is the 1D B3 spline used (spread further at each pass) by Denoise.
+var atrousKernel = [5]float64{1.0 / 16, 1.0 / 4, 3.0 / 8, 1.0 / 4, 1.0 / 16}
+
+// Denoise returns a filtered copy of the color image using passes iterations of edge-aware
+// à-trous wavelet filtering (5x5 kernel with holes of 2^pass pixels between its taps), so
+// low sample count noise gets smoothed without blurring across edges: neighbors only
+// contribute when their color is close and when they are on the same surface according to
+// the normal and depth guide images (as produced by Tracer.RenderAOV). Either guide can be nil.
+func Denoise(colorImg, normal, depth *image.RGBA, passes int) *image.RGBA {
+	b := colorImg.Bounds()
+	w, h := b.Dx(), b.Dy()
+	cur := make([]ColorF, w*h)
+	normals := make([]Vec3, w*h)
+	hit := make([]bool, w*h)
+	depths := make([]float64, w*h)
+	for y := range h {
+		for x := range w {
+			i := y*w + x
+			c := colorImg.RGBAAt(b.Min.X+x, b.Min.Y+y)
+			cur[i] = ColorF{float64(c.R) / 255, float64(c.G) / 255, float64(c.B) / 255}
+			hit[i] = true
+			if normal != nil {
+				n := normal.RGBAAt(b.Min.X+x, b.Min.Y+y)
+				hit[i] = n.A != 0
+				normals[i] = Vec3{float64(n.R)/127.5 - 1, float64(n.G)/127.5 - 1, float64(n.B)/127.5 - 1}
+			}
+			if depth != nil {
+				depths[i] = float64(depth.RGBAAt(b.Min.X+x, b.Min.Y+y).R) / 255
+			}
+		}
+	}
+	next := make([]ColorF, w*h)
+	sigmaColor := denoiseSigmaColor
+	for pass := range passes {
+		step := 1 << pass
+		for y := range h {
+			for x := range w {
+				i := y*w + x
+				var sum ColorF
+				weights := 0.0
+				for ky := -2; ky <= 2; ky++ {
+					qy := y + ky*step
+					if qy < 0 || qy >= h {
+						continue
+					}
+					for kx := -2; kx <= 2; kx++ {
+						qx := x + kx*step
+						if qx < 0 || qx >= w {
+							continue
+						}
+						j := qy*w + qx
+						weight := atrousKernel[kx+2] * atrousKernel[ky+2]
+						weight *= math.Exp(-LengthSquared(Sub(cur[i], cur[j])) / (sigmaColor * sigmaColor))
+						if normal != nil {
+							weight *= normalWeight(hit[i], hit[j], normals[i], normals[j])
+						}
+						if depth != nil {
+							weight *= math.Exp(-math.Abs(depths[i]-depths[j]) / denoiseSigmaDepth)
+						}
+						sum = AddScaled(sum, cur[j], weight)
+						weights += weight
+					}
+				}
+				next[i] = SDiv(sum, weights) // weights > 0: the center pixel always has weight 9/64.
+			}
+		}
+		cur, next = next, cur
+		sigmaColor /= 2
+	}
+	out := image.NewRGBA(b)
+	for i, c := range cur {
+		out.Pix[4*i] = toByte(c.x)
+		out.Pix[4*i+1] = toByte(c.y)
+		out.Pix[4*i+2] = toByte(c.z)
+		out.Pix[4*i+3] = 255
+	}
+	return out
+}
+
+// normalWeight is the edge stopping function for the normals of 2 pixels:
+// background pixels (not hit) only blend together.
+func normalWeight(hitA, hitB bool, a, b Vec3) float64 {
+	if hitA != hitB {
+		return 0
+	}
+	if !hitA {
+		return 1
+	}
+	return math.Pow(max(0, Dot(a, b)), denoiseNormalPower)
+}
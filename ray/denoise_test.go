@@ -0,0 +1,76 @@
+package ray
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// noisyTwoPlanes returns a noisy gray image split in 2 surfaces: left (dark, normal facing
+// the camera) and right (bright, normal facing up), with the matching guide images.
+func noisyTwoPlanes(w, h int) (colorImg, normal, depth *image.RGBA) {
+	rng := RandForTests()
+	colorImg = image.NewRGBA(image.Rect(0, 0, w, h))
+	normal = image.NewRGBA(colorImg.Bounds())
+	depth = image.NewRGBA(colorImg.Bounds())
+	for y := range h {
+		for x := range w {
+			base, n := 0.25, color.RGBA{128, 128, 255, 255}
+			if x >= w/2 {
+				base, n = 0.75, color.RGBA{128, 255, 128, 255}
+			}
+			v := toByte(base + rng.Float64Range(-0.15, 0.15))
+			colorImg.SetRGBA(x, y, color.RGBA{v, v, v, 255})
+			normal.SetRGBA(x, y, n)
+			depth.SetRGBA(x, y, color.RGBA{128, 128, 128, 255})
+		}
+	}
+	return colorImg, normal, depth
+}
+
+// columnStats returns the mean and variance of the red channel of the given columns.
+func columnStats(img *image.RGBA, xStart, xEnd int) (mean, vari float64) {
+	var values []float64
+	for y := img.Bounds().Min.Y; y < img.Bounds().Max.Y; y++ {
+		for x := xStart; x < xEnd; x++ {
+			values = append(values, float64(img.RGBAAt(x, y).R)/255)
+		}
+	}
+	sum2 := 0.0
+	for _, v := range values {
+		mean += v
+		sum2 += v * v
+	}
+	n := float64(len(values))
+	mean /= n
+	return mean, sum2/n - mean*mean
+}
+
+func TestDenoise(t *testing.T) {
+	const w, h = 64, 32
+	noisy, normal, depth := noisyTwoPlanes(w, h)
+	denoised := Denoise(noisy, normal, depth, 4)
+	if denoised.Bounds() != noisy.Bounds() {
+		t.Fatalf("Denoise() bounds = %v, want %v", denoised.Bounds(), noisy.Bounds())
+	}
+	// Flat regions (away from the edge) are much smoother.
+	_, before := columnStats(noisy, 0, w/2-4)
+	mean, after := columnStats(denoised, 0, w/2-4)
+	if after > before/4 {
+		t.Errorf("left variance %v -> %v, want at least 4x less", before, after)
+	}
+	if mean < 0.22 || mean > 0.28 {
+		t.Errorf("left mean = %v, want about 0.25", mean)
+	}
+	// The normal discontinuity stays sharp: the columns next to the edge keep their side's level.
+	if leftEdge, _ := columnStats(denoised, w/2-1, w/2); leftEdge > 0.3 {
+		t.Errorf("column left of the edge = %v, want about 0.25 (not blurred)", leftEdge)
+	}
+	if rightEdge, _ := columnStats(denoised, w/2, w/2+1); rightEdge < 0.7 {
+		t.Errorf("column right of the edge = %v, want about 0.75 (not blurred)", rightEdge)
+	}
+	// 0 passes is a copy.
+	if same := Denoise(noisy, nil, nil, 0); string(same.Pix) != string(noisy.Pix) {
+		t.Error("Denoise() with 0 passes should return an identical copy")
+	}
+}
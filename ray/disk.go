@@ -0,0 +1,129 @@
+package ray
+
+import "math"
+
+// Disk is a flat disk of the given Radius around Center, facing Normal.
+// Use NewDisk to create one, as it needs precomputed values.
+type Disk struct {
+	Center, Normal Vec3
+	Radius         float64
+	Mat            Material
+	// OneSided disks can only be hit from the front (see Quad.OneSided).
+	OneSided bool
+	// Computed fields (initialized by NewDisk)
+	d    float64 // plane equation: Dot(Normal, p) = d
+	u, v Vec3    // unit tangents, for the (U, V) texture coordinates
+	bbox AABB
+}
+
+// NewDisk creates a Disk centered on center with the given radius. The front face is the
+// one normal points to (normal doesn't need to be unit length).
+func NewDisk(center, normal Vec3, radius float64, mat Material) *Disk {
+	n := Unit(normal)
+	u, v := orthonormalBasis(n)
+	// Extent along each axis is radius * sin(angle between the normal and that axis).
+	extent := Vec3{
+		radius * math.Sqrt(math.Max(0, 1-n.x*n.x)),
+		radius * math.Sqrt(math.Max(0, 1-n.y*n.y)),
+		radius * math.Sqrt(math.Max(0, 1-n.z*n.z)),
+	}
+	return &Disk{
+		Center: center,
+		Normal: n,
+		Radius: radius,
+		Mat:    mat,
+		d:      Dot(n, center),
+		u:      u,
+		v:      v,
+		bbox:   NewAABB(Sub(center, extent), Add(center, extent)).Pad(quadPadding),
+	}
+}
+
+func (dk *Disk) Hit(r *Ray, i Interval, hr *HitRecord) bool {
+	if dk.OneSided && Dot(dk.Normal, r.Direction) > 0 {
+		return false
+	}
+	t, ok := planeHit(dk.Normal, dk.d, r, i)
+	if !ok {
+		return false
+	}
+	intersection := r.At(t)
+	offset := Sub(intersection, dk.Center)
+	if LengthSquared(offset) > dk.Radius*dk.Radius {
+		return false
+	}
+	hr.Point = intersection
+	hr.T = t
+	// Planar coordinates mapped so that the disk fits in [0,1].
+	hr.U = 0.5 + 0.5*Dot(offset, dk.u)/dk.Radius
+	hr.V = 0.5 + 0.5*Dot(offset, dk.v)/dk.Radius
+	hr.Tangent = dk.u
+	hr.SetFaceNormal(r, dk.Normal)
+	hr.Mat = dk.Mat
+	return true
+}
+
+func (dk *Disk) BoundingBox() AABB {
+	return dk.bbox
+}
+
+// Plane is an infinite plane through Point, facing Normal, e.g. for a ground or a backdrop.
+// Use NewPlane to create one, as it needs precomputed values.
+// Its bounding box is infinite, except along the normal for axis aligned planes.
+type Plane struct {
+	Point, Normal Vec3
+	Mat           Material
+	// Computed fields (initialized by NewPlane)
+	d    float64 // plane equation: Dot(Normal, p) = d
+	u, v Vec3    // unit tangents, for the (U, V) texture coordinates
+	bbox AABB
+}
+
+// NewPlane creates the Plane through point perpendicular to normal (which doesn't need
+// to be unit length). The front face is the one normal points to.
+func NewPlane(point, normal Vec3, mat Material) *Plane {
+	n := Unit(normal)
+	u, v := orthonormalBasis(n)
+	bbox := UniverseAABB
+	switch {
+	case n.y == 0 && n.z == 0:
+		bbox.X = Interval{Start: point.x, End: point.x}
+	case n.x == 0 && n.z == 0:
+		bbox.Y = Interval{Start: point.y, End: point.y}
+	case n.x == 0 && n.y == 0:
+		bbox.Z = Interval{Start: point.z, End: point.z}
+	}
+	return &Plane{Point: point, Normal: n, Mat: mat, d: Dot(n, point), u: u, v: v, bbox: bbox.Pad(quadPadding)}
+}
+
+func (p *Plane) Hit(r *Ray, i Interval, hr *HitRecord) bool {
+	t, ok := planeHit(p.Normal, p.d, r, i)
+	if !ok {
+		return false
+	}
+	hr.Point = r.At(t)
+	hr.T = t
+	// Unbounded planar coordinates (in world units from Point): use a wrapping texture
+	// or a spatial one like CheckerTexture.
+	offset := Sub(hr.Point, p.Point)
+	hr.U, hr.V = Dot(offset, p.u), Dot(offset, p.v)
+	hr.Tangent = p.u
+	hr.SetFaceNormal(r, p.Normal)
+	hr.Mat = p.Mat
+	return true
+}
+
+func (p *Plane) BoundingBox() AABB {
+	return p.bbox
+}
+
+// planeHit returns the ray parameter where r crosses the plane Dot(normal, p) = d,
+// if it's within i (and the ray isn't parallel to the plane).
+func planeHit(normal Vec3, d float64, r *Ray, i Interval) (float64, bool) {
+	denom := Dot(normal, r.Direction)
+	if math.Abs(denom) < 1e-8 {
+		return 0, false
+	}
+	t := (d - Dot(normal, r.Origin)) / denom
+	return t, i.Surrounds(t)
+}
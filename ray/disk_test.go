@@ -0,0 +1,97 @@
+package ray
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDiskHit(t *testing.T) {
+	rnd := RandForTests()
+	mat := Lambertian{Albedo: ColorF{1, 0, 0}}
+	// Disk of radius 1 in the z=-2 plane, facing the camera (+Z).
+	disk := NewDisk(Vec3{0, 0, -2}, Vec3{0, 0, 3}, 1, mat)
+	tests := []struct {
+		name string
+		dir  Vec3
+		hit  bool
+	}{
+		{"center", Vec3{0, 0, -1}, true},
+		{"inside the rim", Vec3{0.49, 0, -1}, true},
+		{"outside the rim", Vec3{0.36, 0.36, -1}, false}, // inside the bounding square
+		{"parallel to the plane", Vec3{1, 0, 0}, false},
+		{"pointing away", Vec3{0, 0, 1}, false},
+	}
+	for _, tt := range tests {
+		hit, rec := testHit(disk, NewRay(rnd, Vec3{0, 0, 0}, tt.dir), FrontEpsilon)
+		if hit != tt.hit {
+			t.Errorf("%s: Hit() = %v, want %v", tt.name, hit, tt.hit)
+			continue
+		}
+		if hit && (rec.Normal != (Vec3{0, 0, 1}) || !rec.FrontFace || rec.Mat != mat) {
+			t.Errorf("%s: hit record = %+v, want front face with normal {0, 0, 1}", tt.name, rec)
+		}
+	}
+	_, rec := testHit(disk, NewRay(rnd, Vec3{0, 0, 0}, Vec3{0, 0, -1}), FrontEpsilon)
+	if math.Abs(rec.U-0.5) > 1e-12 || math.Abs(rec.V-0.5) > 1e-12 {
+		t.Errorf("center (U, V) = (%v, %v), want (0.5, 0.5)", rec.U, rec.V)
+	}
+}
+
+func TestDiskOneSided(t *testing.T) {
+	disk := NewDisk(Vec3{0, 0, -2}, Vec3{0, 0, 1}, 1, Lambertian{})
+	disk.OneSided = true
+	if hit, _ := testHit(disk, NewRay(RandForTests(), Vec3{0, 0, 0}, Vec3{0, 0, -1}), FrontEpsilon); !hit {
+		t.Errorf("Hit() on the front face = false, want true")
+	}
+	if hit, _ := testHit(disk, NewRay(RandForTests(), Vec3{0, 0, -3}, Vec3{0, 0, 1}), FrontEpsilon); hit {
+		t.Errorf("Hit() on the back face = true, want false")
+	}
+}
+
+func TestDiskBoundingBox(t *testing.T) {
+	flat := NewDisk(Vec3{1, 2, 3}, Vec3{0, 1, 0}, 2, Lambertian{})
+	want := NewAABB(Vec3{-1, 2, 1}, Vec3{3, 2, 5}).Pad(quadPadding)
+	if box := flat.BoundingBox(); box != want {
+		t.Errorf("BoundingBox() = %v, want %v", box, want)
+	}
+	tilted := NewDisk(Vec3{0, 0, 0}, Vec3{1, 1, 0}, 1, Lambertian{})
+	box := tilted.BoundingBox()
+	s := math.Sqrt(0.5)
+	if math.Abs(box.X.End-s) > 1e-12 || math.Abs(box.Y.End-s) > 1e-12 || box.Z.End != 1 {
+		t.Errorf("tilted BoundingBox() = %v, want x, y within %v and z within 1", box, s)
+	}
+}
+
+func TestPlaneHit(t *testing.T) {
+	rnd := RandForTests()
+	ground := NewPlane(Vec3{0, -1, 0}, Vec3{0, 1, 0}, Lambertian{})
+	// Far away hits, from above and below.
+	hit, rec := testHit(ground, NewRay(rnd, Vec3{0, 0, 0}, Vec3{1000, -1, 0}), FrontEpsilon)
+	if !hit || math.Abs(rec.T-1) > 1e-12 || !rec.FrontFace {
+		t.Errorf("Hit() from above = %v, %+v, want front face hit at t=1", hit, rec)
+	}
+	hit, rec = testHit(ground, NewRay(rnd, Vec3{0, -2, 0}, Vec3{0, 1, -1000}), FrontEpsilon)
+	if !hit || rec.FrontFace || rec.Normal != (Vec3{0, -1, 0}) {
+		t.Errorf("Hit() from below = %v, %+v, want back face hit", hit, rec)
+	}
+	if hit, _ = testHit(ground, NewRay(rnd, Vec3{0, 0, 0}, Vec3{0, 1, 0}), FrontEpsilon); hit {
+		t.Errorf("Hit() going up = true, want false")
+	}
+}
+
+func TestPlaneBoundingBox(t *testing.T) {
+	ground := NewPlane(Vec3{5, -1, 7}, Vec3{0, 2, 0}, Lambertian{})
+	box := ground.BoundingBox()
+	if box.X != Universe || box.Z != Universe || !box.Y.Contains(-1) || box.Y.Length() > 2*quadPadding {
+		t.Errorf("ground BoundingBox() = %v, want thin around y=-1", box)
+	}
+	if box := NewPlane(Vec3{}, Vec3{1, 1, 1}, Lambertian{}).BoundingBox(); box != UniverseAABB {
+		t.Errorf("tilted plane BoundingBox() = %v, want UniverseAABB", box)
+	}
+	// Still hit through a Scene (which checks every object) and a Translate.
+	scene := &Scene{Objects: []Hittable{&Translate{Object: ground, Offset: Vec3{0, 0.5, 0}}}}
+	hit, rec := testHit(scene, NewRay(RandForTests(), Vec3{0, 0, 0}, Vec3{0, -1, 0}), FrontEpsilon)
+	if !hit || math.Abs(rec.T-0.5) > 1e-12 {
+		t.Errorf("translated plane Hit() = %v at t=%v, want hit at 0.5", hit, rec.T)
+	}
+}
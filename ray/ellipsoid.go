@@ -0,0 +1,67 @@
+package ray
+
+import "math"
+
+// Ellipsoid is an axis aligned ellipsoid: a sphere with a different radius along each
+// axis. Use NewEllipsoid to create one, as it needs precomputed values.
+type Ellipsoid struct {
+	Center Vec3
+	Radii  Vec3 // along X, Y and Z, all > 0
+	Mat    Material
+	// Computed fields (initialized by NewEllipsoid)
+	scale     Vec3 // world to sphere space: Radii.x/Radii per axis (so 1,1,1 for a sphere)
+	radiusSq  float64
+	invRadius float64
+}
+
+// NewEllipsoid creates the ellipsoid centered on center with the given radii.
+func NewEllipsoid(center, radii Vec3, mat Material) *Ellipsoid {
+	return &Ellipsoid{
+		Center:    center,
+		Radii:     radii,
+		Mat:       mat,
+		scale:     Vec3{1, radii.x / radii.y, radii.x / radii.z},
+		radiusSq:  radii.x * radii.x,
+		invRadius: 1 / radii.x,
+	}
+}
+
+// Hit scales the ray along the axes so the ellipsoid becomes a sphere of radius Radii.x
+// (keeping the same t along the ray), intersects that sphere like Sphere.Hit does, and
+// maps the normal back with the inverse transpose of the scaling. With equal radii the
+// scale is exactly 1 and the hits are the same as the Sphere's (but for the normal's rounding).
+func (e *Ellipsoid) Hit(r *Ray, i Interval, hr *HitRecord) bool {
+	oc := Mul(Sub(e.Center, r.Origin), e.scale)
+	dir := Mul(r.Direction, e.scale)
+	h := Dot(dir, oc)
+	c := LengthSquared(oc) - e.radiusSq
+	if c > 0 && h <= 0 && i.Start >= 0 {
+		return false // origin outside of the ellipsoid and going away from it: both roots are behind
+	}
+	a := LengthSquared(dir)
+	discriminant := h*h - a*c
+	if discriminant < 0 {
+		return false
+	}
+	sqrtD := math.Sqrt(discriminant)
+	root := (h - sqrtD) / a
+	if !i.Surrounds(root) {
+		root = (h + sqrtD) / a
+		if !i.Surrounds(root) {
+			return false
+		}
+	}
+	hr.Point = r.At(root)
+	hr.T = root
+	sphereNormal := SMul(Mul(Sub(hr.Point, e.Center), e.scale), e.invRadius) // unit, in sphere space
+	hr.SetFaceNormal(r, Unit(Mul(sphereNormal, e.scale)))
+	hr.U, hr.V = SphereUV(sphereNormal)
+	// The sphere's tangent (see Sphere.Hit), back to world space.
+	hr.Tangent = Vec3{sphereNormal.z / e.scale.x, 0, -sphereNormal.x / e.scale.z}
+	hr.Mat = e.Mat
+	return true
+}
+
+func (e *Ellipsoid) BoundingBox() AABB {
+	return NewAABB(Sub(e.Center, e.Radii), Add(e.Center, e.Radii))
+}
@@ -0,0 +1,95 @@
+package ray
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEllipsoidEqualRadiiIsSphere(t *testing.T) {
+	rng := RandForTests()
+	for range 20 {
+		center := RandomInRange(rng, Interval{-3, 3})
+		radius := 0.2 + rng.Float64()
+		mat := Lambertian{Albedo: Random(rng)}
+		sphere := NewSphere(center, radius, mat)
+		ellipsoid := NewEllipsoid(center, Vec3{radius, radius, radius}, mat)
+		if ellipsoid.BoundingBox() != sphere.BoundingBox() {
+			t.Errorf("BoundingBox() = %v, want %v", ellipsoid.BoundingBox(), sphere.BoundingBox())
+		}
+		for range 50 {
+			origin := RandomInRange(rng, Interval{-5, 5})
+			r := NewRay(rng, origin, Sub(AddScaled(center, RandomUnitVector(rng), radius*1.2), origin)) // mostly hits
+			var want, got HitRecord
+			hit := sphere.Hit(r, FrontEpsilon, &want)
+			if ellipsoidHit := ellipsoid.Hit(r, FrontEpsilon, &got); ellipsoidHit != hit {
+				t.Fatalf("Ellipsoid Hit() = %v, want %v", ellipsoidHit, hit)
+			}
+			if !hit {
+				continue
+			}
+			if got.T != want.T || got.Point != want.Point || got.U != want.U || got.V != want.V ||
+				got.Tangent != want.Tangent || got.FrontFace != want.FrontFace || got.Mat != want.Mat {
+				t.Fatalf("Ellipsoid Hit() = %+v, want %+v", got, want)
+			}
+			// Only the normal is renormalized (Sphere's is off by rounding errors).
+			if !Equal(got.Normal, want.Normal, 1e-12) {
+				t.Errorf("Ellipsoid normal = %v, want %v", got.Normal, want.Normal)
+			}
+		}
+	}
+}
+
+func TestEllipsoidHit(t *testing.T) {
+	center, radii := Vec3{1, 2, 3}, Vec3{2, 1, 0.5}
+	e := NewEllipsoid(center, radii, Lambertian{})
+	tests := []struct {
+		origin, dir Vec3
+		wantT       float64
+		wantNormal  Vec3
+		wantFront   bool
+		wantMiss    bool
+	}{
+		{Vec3{6, 2, 3}, Vec3{-1, 0, 0}, 3, Vec3{1, 0, 0}, true, false},
+		{Vec3{1, -2, 3}, Vec3{0, 2, 0}, 1.5, Vec3{0, -1, 0}, true, false},
+		{Vec3{1, 2, 3}, Vec3{0, 0, 1}, 0.5, Vec3{0, 0, -1}, false, false}, // from inside: normal against the ray
+		{Vec3{1, 3.5, 3}, Vec3{1, 0, 0}, 0, Vec3{}, false, true},
+	}
+	for _, tt := range tests {
+		r := NewRay(RandForTests(), tt.origin, tt.dir)
+		var hr HitRecord
+		hit := e.Hit(r, FrontEpsilon, &hr)
+		if hit == tt.wantMiss {
+			t.Errorf("Hit(%v, %v) = %v, want %v", tt.origin, tt.dir, hit, !tt.wantMiss)
+			continue
+		}
+		if !hit {
+			continue
+		}
+		if math.Abs(hr.T-tt.wantT) > 1e-12 || !Equal(hr.Normal, tt.wantNormal, 1e-12) || hr.FrontFace != tt.wantFront {
+			t.Errorf("Hit(%v, %v) = t %v normal %v front %v, want %v %v %v",
+				tt.origin, tt.dir, hr.T, hr.Normal, hr.FrontFace, tt.wantT, tt.wantNormal, tt.wantFront)
+		}
+	}
+	// Off axis, the hit point is on the surface and the normal is the gradient of the
+	// implicit equation sum(((p-center)/radii)^2) = 1, not the direction from the center.
+	rng := RandForTests()
+	for range 100 {
+		origin := AddScaled(center, RandomUnitVector(rng), 5)
+		r := NewRay(rng, origin, Sub(Add(center, RandomInRange(rng, Interval{-0.3, 0.3})), origin))
+		var hr HitRecord
+		if !e.Hit(r, FrontEpsilon, &hr) {
+			t.Fatalf("Hit() missed a ray toward the center")
+		}
+		q := Vec3{(hr.Point.x - center.x) / radii.x, (hr.Point.y - center.y) / radii.y, (hr.Point.z - center.z) / radii.z}
+		if d := LengthSquared(q); math.Abs(d-1) > 1e-12 {
+			t.Errorf("hit point %v isn't on the surface: %v", hr.Point, d)
+		}
+		gradient := Unit(Vec3{q.x / radii.x, q.y / radii.y, q.z / radii.z})
+		if !Equal(hr.Normal, gradient, 1e-12) {
+			t.Errorf("normal at %v = %v, want %v", hr.Point, hr.Normal, gradient)
+		}
+		if math.Abs(Dot(hr.Tangent, hr.Normal)) > 1e-12 {
+			t.Errorf("tangent %v isn't perpendicular to the normal %v", hr.Tangent, hr.Normal)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package ray
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+)
+
+// OutputEncoding selects the transfer function used to convert the (tone mapped) linear
+// colors to the 8 bit values of the rendered image.
+type OutputEncoding int
+
+const (
+	// EncodingSRGB is the standard sRGB curve (default, what images are normally expected to be).
+	EncodingSRGB OutputEncoding = iota
+	// EncodingLinear stores the linear values as is, e.g. for further processing.
+	EncodingLinear
+	// EncodingGamma is a pure power law with the Tracer's Gamma (2.2 by default).
+	EncodingGamma
+)
+
+// DefaultGamma is used for EncodingGamma when Tracer.Gamma isn't set.
+const DefaultGamma = 2.2
+
+var outputEncodingNames = []string{"srgb", "linear", "gamma"}
+
+func (e OutputEncoding) String() string {
+	if e < 0 || int(e) >= len(outputEncodingNames) {
+		return fmt.Sprintf("OutputEncoding(%d)", int(e))
+	}
+	return outputEncodingNames[e]
+}
+
+// ParseOutputEncoding returns the OutputEncoding for the given name (case insensitive):
+// one of "srgb", "linear" or "gamma".
+func ParseOutputEncoding(name string) (OutputEncoding, error) {
+	for i, n := range outputEncodingNames {
+		if strings.EqualFold(name, n) {
+			return OutputEncoding(i), nil
+		}
+	}
+	return EncodingSRGB, fmt.Errorf("unknown output encoding %q, should be one of %v", name, outputEncodingNames)
+}
+
+// Encode converts the linear color c, using gamma for EncodingGamma (DefaultGamma if <= 0).
+func (e OutputEncoding) Encode(c ColorF, gamma float64) color.RGBA {
+	switch e {
+	case EncodingLinear:
+		return c.ToRGBALinear()
+	case EncodingGamma:
+		if gamma <= 0 {
+			gamma = DefaultGamma
+		}
+		return c.ToGamma(gamma)
+	default:
+		return c.ToSRGBA()
+	}
+}
@@ -0,0 +1,81 @@
+package ray
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestOutputEncodingMidGray(t *testing.T) {
+	tests := []struct {
+		encoding OutputEncoding
+		gamma    float64
+		gray     float64
+		expected uint8
+	}{
+		{EncodingSRGB, 0, 0.5, 188},
+		{EncodingSRGB, 0, 0.18, 118},
+		{EncodingLinear, 0, 0.5, 128},
+		{EncodingLinear, 0, 0.18, 46},
+		{EncodingGamma, 0, 0.5, 186}, // DefaultGamma 2.2
+		{EncodingGamma, 2.2, 0.18, 117},
+		{EncodingGamma, 1, 0.5, 128}, // same as linear
+		{EncodingGamma, 2.4, 0.5, 191},
+		{OutputEncoding(42), 0, 0.5, 188}, // unknown is sRGB
+	}
+	for _, tt := range tests {
+		c := ColorF{tt.gray, tt.gray, tt.gray}
+		expected := color.RGBA{tt.expected, tt.expected, tt.expected, 255}
+		if got := tt.encoding.Encode(c, tt.gamma); got != expected {
+			t.Errorf("%v.Encode(%v, %v) = %v, want %v", tt.encoding, c, tt.gamma, got, expected)
+		}
+	}
+}
+
+func TestOutputEncodingClamps(t *testing.T) {
+	for _, e := range []OutputEncoding{EncodingSRGB, EncodingLinear, EncodingGamma} {
+		if got := e.Encode(ColorF{-1, 0, 7}, 0); got != (color.RGBA{0, 0, 255, 255}) {
+			t.Errorf("%v.Encode({-1 0 7}) = %v, want {0 0 255 255}", e, got)
+		}
+	}
+}
+
+func TestParseOutputEncoding(t *testing.T) {
+	for _, e := range []OutputEncoding{EncodingSRGB, EncodingLinear, EncodingGamma} {
+		parsed, err := ParseOutputEncoding(e.String())
+		if err != nil || parsed != e {
+			t.Errorf("ParseOutputEncoding(%q) = %v, %v; want %v", e.String(), parsed, err, e)
+		}
+	}
+	if e, err := ParseOutputEncoding("sRGB"); err != nil || e != EncodingSRGB {
+		t.Errorf("ParseOutputEncoding(\"sRGB\") = %v, %v; want srgb", e, err)
+	}
+	if _, err := ParseOutputEncoding("log"); err == nil {
+		t.Error("Expected error for unknown output encoding")
+	}
+	if s := OutputEncoding(-1).String(); s != "OutputEncoding(-1)" {
+		t.Errorf("String() = %q, want \"OutputEncoding(-1)\"", s)
+	}
+}
+
+func TestRender_OutputEncoding(t *testing.T) {
+	gray := ColorF{0.5, 0.5, 0.5}
+	scene := &Scene{Objects: []Hittable{}, Background: AmbientLight{ColorA: gray, ColorB: gray}}
+	for _, tt := range []struct {
+		encoding OutputEncoding
+		gamma    float64
+		expected uint8
+	}{
+		{EncodingSRGB, 0, 188},
+		{EncodingLinear, 0, 128},
+		{EncodingGamma, 0, 186},
+		{EncodingGamma, 1, 128},
+	} {
+		tracer := New(2, 2)
+		tracer.OutputEncoding = tt.encoding
+		tracer.Gamma = tt.gamma
+		img := tracer.Render(scene)
+		if c := img.RGBAAt(1, 1); c.R != tt.expected || c.G != tt.expected || c.B != tt.expected {
+			t.Errorf("%v (gamma %v) pixel = %v, want gray %d", tt.encoding, tt.gamma, c, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package ray
+
+// EnvironmentMap is a Background using an equirectangular (latitude/longitude) image
+// of the surroundings, e.g. a photo of the sky, seen in reflections and lighting the scene.
+type EnvironmentMap struct {
+	Image *ImageTexture
+	// Intensity multiplies the image colors (1 when 0), to use it as a brighter light source.
+	Intensity float64
+	// Path of the image file, when loaded with NewEnvironmentMap (used to save scenes).
+	Path string
+}
+
+// NewEnvironmentMap loads an equirectangular PNG or JPEG image as an EnvironmentMap.
+func NewEnvironmentMap(path string) (*EnvironmentMap, error) {
+	img, err := NewImageTexture(path)
+	if err != nil {
+		return nil, err
+	}
+	img.Wrap = true // no seam where the longitude wraps around
+	return &EnvironmentMap{Image: img, Path: path}, nil
+}
+
+// Hit returns the color of the environment in the ray's direction: the longitude maps
+// to u (x in the image) and the latitude to v (y), straight up being the top row.
+func (em *EnvironmentMap) Hit(r *Ray) ColorF {
+	u, v := SphereUV(Unit(r.Direction))
+	// Wrap is for the longitude only: keep the latitude within the first and last rows'
+	// centers so the filtering doesn't blend the top (sky) with the bottom (ground).
+	if h := float64(em.Image.height); h > 0 {
+		v = Interval{Start: 0.5 / h, End: 1 - 0.5/h}.Clamp(v)
+	}
+	c := em.Image.Value(u, v, r.Direction)
+	if em.Intensity != 0 {
+		c = SMul(c, em.Intensity)
+	}
+	return c
+}
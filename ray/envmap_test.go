@@ -0,0 +1,107 @@
+package ray
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// skyGround is an equirectangular map with a white sky (top half) and a red ground.
+func skyGround() *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, 8, 4))
+	for y := range 4 {
+		for x := range 8 {
+			c := color.RGBA{255, 255, 255, 255}
+			if y >= 2 {
+				c = color.RGBA{255, 0, 0, 255}
+			}
+			img.SetRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestEnvironmentMapHit(t *testing.T) {
+	em := &EnvironmentMap{Image: NewImageTextureFromImage(skyGround())}
+	em.Image.Wrap = true
+	rnd := RandForTests()
+	tests := []struct {
+		name     string
+		dir      Vec3
+		expected ColorF
+	}{
+		{"up", Vec3{0, 1, 0}, ColorF{1, 1, 1}},
+		{"up and forward", Vec3{0, 2, -1}, ColorF{1, 1, 1}},
+		{"down", Vec3{0, -1, 0}, ColorF{1, 0, 0}},
+		{"down and sideways", Vec3{-1, -3, 0.5}, ColorF{1, 0, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if c := em.Hit(NewRay(rnd, Vec3{}, tt.dir)); !Equal(c, tt.expected, 1e-9) {
+				t.Errorf("Hit(%v) = %v, want %v", tt.dir, c, tt.expected)
+			}
+		})
+	}
+	em.Intensity = 3
+	if c := em.Hit(NewRay(rnd, Vec3{}, Vec3{0, -1, 0})); !Equal(c, ColorF{3, 0, 0}, 1e-9) {
+		t.Errorf("Hit() with intensity 3 = %v, want {3 0 0}", c)
+	}
+}
+
+func TestEnvironmentMapReflection(t *testing.T) {
+	em := &EnvironmentMap{Image: NewImageTextureFromImage(skyGround())}
+	scene := &Scene{
+		Objects:    []Hittable{&Sphere{Center: Vec3{0, 0, -3}, Radius: 1, Mat: Metal{Albedo: ColorF{1, 1, 1}}}},
+		Background: em,
+	}
+	rnd := RandForTests()
+	// Top of the mirror sphere reflects the sky, bottom reflects the ground.
+	if c := scene.RayColor(NewRay(rnd, Vec3{}, Vec3{0, 0.3, -3}), 5); !Equal(c, ColorF{1, 1, 1}, 1e-9) {
+		t.Errorf("top reflection = %v, want the white sky", c)
+	}
+	if c := scene.RayColor(NewRay(rnd, Vec3{}, Vec3{0, -0.3, -3}), 5); !Equal(c, ColorF{1, 0, 0}, 1e-9) {
+		t.Errorf("bottom reflection = %v, want the red ground", c)
+	}
+}
+
+func TestNewEnvironmentMap(t *testing.T) {
+	fname := filepath.Join(t.TempDir(), "env.png")
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, skyGround()); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(fname, buf.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	em, err := NewEnvironmentMap(fname)
+	if err != nil {
+		t.Fatalf("NewEnvironmentMap() error: %v", err)
+	}
+	if !em.Image.Wrap || em.Path != fname {
+		t.Errorf("NewEnvironmentMap() = %+v, want wrapping image and path %q", em, fname)
+	}
+	// Save and load back through the scene format.
+	em.Intensity = 2
+	buf.Reset()
+	if err = SaveScene(&buf, &Scene{Background: em}, nil); err != nil {
+		t.Fatalf("SaveScene() error: %v", err)
+	}
+	loaded, _, err := LoadScene(&buf)
+	if err != nil {
+		t.Fatalf("LoadScene() error: %v", err)
+	}
+	lem, ok := loaded.Background.(*EnvironmentMap)
+	if !ok || lem.Path != fname || lem.Intensity != 2 || len(lem.Image.pixels) != 8*4 {
+		t.Errorf("loaded background = %+v, want environment map from %q", loaded.Background, fname)
+	}
+	if _, err = NewEnvironmentMap(filepath.Join(t.TempDir(), "missing.png")); err == nil {
+		t.Error("Expected error for missing file")
+	}
+	if err = SaveScene(&buf, &Scene{Background: &EnvironmentMap{}}, nil); err == nil {
+		t.Error("Expected error saving an environment map without path")
+	}
+}
@@ -0,0 +1,58 @@
+package ray
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Filter selects how the samples of a pixel are weighted, according to their distance
+// to the pixel center, when averaging multiple rays per pixel (reconstruction filter).
+// The filter radius is the Tracer's RayRadius: increasing it to 1 or more lets the
+// samples spread into the neighboring pixels, which reduces aliasing at edges.
+// It applies to Render (including adaptive sampling); RenderProgressive keeps a plain average.
+type Filter int
+
+const (
+	// FilterBox weights all the samples equally (default, plain average).
+	FilterBox Filter = iota
+	// FilterTent weights the samples linearly from 1 at the center to 0 at the radius,
+	// separately on each axis (so it covers the square of the stratified samples).
+	FilterTent
+	// FilterGaussian weights the samples by a gaussian of standard deviation radius/2.
+	FilterGaussian
+)
+
+var filterNames = []string{"box", "tent", "gaussian"}
+
+func (f Filter) String() string {
+	if f < 0 || int(f) >= len(filterNames) {
+		return fmt.Sprintf("Filter(%d)", int(f))
+	}
+	return filterNames[f]
+}
+
+// ParseFilter returns the Filter for the given name (case insensitive):
+// one of "box", "tent" or "gaussian".
+func ParseFilter(name string) (Filter, error) {
+	for i, n := range filterNames {
+		if strings.EqualFold(name, n) {
+			return Filter(i), nil
+		}
+	}
+	return FilterBox, fmt.Errorf("unknown filter %q, should be one of %v", name, filterNames)
+}
+
+// Weight returns the weight of a sample at offset (dx, dy) from the pixel center
+// for a filter of the given radius.
+func (f Filter) Weight(dx, dy, radius float64) float64 {
+	switch f {
+	case FilterTent:
+		return max(0, 1-math.Abs(dx)/radius) * max(0, 1-math.Abs(dy)/radius)
+	case FilterGaussian:
+		sigma := radius / 2
+		return math.Exp(-(dx*dx + dy*dy) / (2 * sigma * sigma))
+	default:
+		return 1
+	}
+}
@@ -0,0 +1,83 @@
+package ray
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+func TestFilterWeight(t *testing.T) {
+	tests := []struct {
+		f        Filter
+		dx, dy   float64
+		expected float64
+	}{
+		{FilterBox, 0, 0, 1},
+		{FilterBox, 0.4, -0.5, 1},
+		{FilterTent, 0, 0, 1},
+		{FilterTent, 0.25, 0, 0.5},
+		{FilterTent, 0.25, -0.25, 0.25},
+		{FilterTent, 0.5, 0, 0},
+		{FilterTent, 0, -0.7, 0},
+		{FilterGaussian, 0, 0, 1},
+		{FilterGaussian, 0.25, 0, math.Exp(-0.5)}, // one sigma
+		{FilterGaussian, 0.3, 0.4, math.Exp(-2)},  // two sigmas
+		{Filter(42), 0.3, 0.4, 1},                 // unknown is box
+	}
+	for _, tt := range tests {
+		if got := tt.f.Weight(tt.dx, tt.dy, 0.5); math.Abs(got-tt.expected) > 1e-12 {
+			t.Errorf("%v.Weight(%v, %v, 0.5) = %v, want %v", tt.f, tt.dx, tt.dy, got, tt.expected)
+		}
+	}
+}
+
+func TestParseFilter(t *testing.T) {
+	for _, f := range []Filter{FilterBox, FilterTent, FilterGaussian} {
+		parsed, err := ParseFilter(f.String())
+		if err != nil || parsed != f {
+			t.Errorf("ParseFilter(%q) = %v, %v; want %v", f.String(), parsed, err, f)
+		}
+	}
+	if f, err := ParseFilter("Gaussian"); err != nil || f != FilterGaussian {
+		t.Errorf("ParseFilter(\"Gaussian\") = %v, %v; want gaussian", f, err)
+	}
+	if _, err := ParseFilter("mitchell"); err == nil {
+		t.Error("Expected error for unknown filter")
+	}
+	if s := Filter(-1).String(); s != "Filter(-1)" {
+		t.Errorf("String() = %q, want \"Filter(-1)\"", s)
+	}
+}
+
+func TestRender_Filter(t *testing.T) {
+	// Uniform background: the weights are normalized so every filter gives the same color.
+	gray := ColorF{0.5, 0.5, 0.5}
+	uniform := &Scene{Objects: []Hittable{}, Background: AmbientLight{ColorA: gray, ColorB: gray}}
+	scene := DefaultScene()
+	camera := *RichSceneCamera()
+	render := func(s *Scene, f Filter, adaptive bool) []byte {
+		rt := New(16, 8)
+		rt.Seed = 42
+		rt.Camera = camera
+		rt.NumRaysPerPixel = 16
+		rt.RayRadius = 1
+		rt.Sampler = SamplerStratified
+		rt.Filter = f
+		if adaptive {
+			rt.AdaptiveThreshold = 0.01
+		}
+		return bytes.Clone(rt.Render(s).Pix)
+	}
+	for _, adaptive := range []bool{false, true} {
+		box := render(uniform, FilterBox, adaptive)
+		for _, f := range []Filter{FilterTent, FilterGaussian} {
+			if got := render(uniform, f, adaptive); !bytes.Equal(got, box) {
+				t.Errorf("%v (adaptive %v) on a uniform background differs from the box filter", f, adaptive)
+			}
+			// But not on edges.
+			if got := render(scene, f, adaptive); bytes.Equal(got, render(scene, FilterBox, adaptive)) {
+				t.Errorf("%v (adaptive %v) should differ from the box filter on the spheres' edges", f, adaptive)
+			}
+		}
+	}
+}
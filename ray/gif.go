@@ -0,0 +1,46 @@
+package ray
+
+import (
+	"errors"
+	"image"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"io"
+)
+
+// RenderSnapshots is RenderProgressive calling onFrame with a copy of the image after each
+// pass, so the frames can be kept (e.g. for EncodeGIF, to show the noise clearing up) while
+// the tracer keeps updating its own image. Returning false from onFrame stops early.
+func (t *Tracer) RenderSnapshots(scene *Scene, onFrame func(frame *image.RGBA, pass int) bool) *image.RGBA {
+	return t.RenderProgressive(scene, func(img *image.RGBA, pass int) bool {
+		return onFrame(cloneRGBA(img), pass)
+	})
+}
+
+// cloneRGBA returns a copy of img, with its own pixels.
+func cloneRGBA(img *image.RGBA) *image.RGBA {
+	c := *img
+	c.Pix = append([]byte(nil), img.Pix...)
+	return &c
+}
+
+// EncodeGIF writes the frames as an animated GIF that loops forever, each shown for delay
+// hundredths of a second. Colors are reduced to the web safe palette with dithering.
+func EncodeGIF(frames []*image.RGBA, delay int, w io.Writer) error {
+	if len(frames) == 0 {
+		return errors.New("no frames to encode as gif")
+	}
+	anim := &gif.GIF{
+		Image: make([]*image.Paletted, len(frames)),
+		Delay: make([]int, len(frames)),
+	}
+	for i, frame := range frames {
+		b := frame.Bounds()
+		p := image.NewPaletted(b, palette.WebSafe)
+		draw.FloydSteinberg.Draw(p, b, frame, b.Min)
+		anim.Image[i] = p
+		anim.Delay[i] = delay
+	}
+	return gif.EncodeAll(w, anim)
+}
@@ -0,0 +1,84 @@
+package ray
+
+import (
+	"bytes"
+	"image"
+	"image/gif"
+	"slices"
+	"testing"
+)
+
+func TestRenderSnapshots(t *testing.T) {
+	tracer := New(16, 12)
+	tracer.Seed = 42
+	tracer.NumRaysPerPixel = 4
+	var frames []*image.RGBA
+	img := tracer.RenderSnapshots(nil, func(frame *image.RGBA, pass int) bool {
+		if pass != len(frames)+1 {
+			t.Errorf("pass = %d, want %d", pass, len(frames)+1)
+		}
+		frames = append(frames, frame)
+		return true
+	})
+	if len(frames) != 4 {
+		t.Fatalf("got %d frames, want 4", len(frames))
+	}
+	// The frames are snapshots: still different from each other after the render.
+	for i := 1; i < len(frames); i++ {
+		if frames[i] == frames[i-1] || slices.Equal(frames[i].Pix, frames[i-1].Pix) {
+			t.Errorf("frame %d is the same as frame %d", i, i-1)
+		}
+		if frames[i-1] == img {
+			t.Errorf("frame %d is the tracer's image, not a copy", i-1)
+		}
+	}
+	if !slices.Equal(frames[3].Pix, img.Pix) {
+		t.Error("last frame differs from the final image")
+	}
+}
+
+func TestRenderSnapshots_Stop(t *testing.T) {
+	tracer := New(8, 8)
+	tracer.NumRaysPerPixel = 10
+	n := 0
+	tracer.RenderSnapshots(nil, func(_ *image.RGBA, pass int) bool {
+		n++
+		return pass < 3
+	})
+	if n != 3 {
+		t.Errorf("onFrame called %d times, want 3", n)
+	}
+}
+
+func TestEncodeGIF(t *testing.T) {
+	tracer := New(16, 12)
+	tracer.Seed = 7
+	tracer.NumRaysPerPixel = 3
+	var frames []*image.RGBA
+	tracer.RenderSnapshots(nil, func(frame *image.RGBA, _ int) bool {
+		frames = append(frames, frame)
+		return true
+	})
+	var buf bytes.Buffer
+	if err := EncodeGIF(frames, 20, &buf); err != nil {
+		t.Fatalf("EncodeGIF() error: %v", err)
+	}
+	anim, err := gif.DecodeAll(&buf)
+	if err != nil {
+		t.Fatalf("decoding the gif: %v", err)
+	}
+	if len(anim.Image) != 3 {
+		t.Fatalf("gif has %d frames, want 3", len(anim.Image))
+	}
+	for i, img := range anim.Image {
+		if img.Bounds() != frames[i].Bounds() {
+			t.Errorf("frame %d bounds = %v, want %v", i, img.Bounds(), frames[i].Bounds())
+		}
+		if anim.Delay[i] != 20 {
+			t.Errorf("frame %d delay = %d, want 20", i, anim.Delay[i])
+		}
+	}
+	if err := EncodeGIF(nil, 10, &buf); err == nil {
+		t.Error("EncodeGIF(nil) should fail")
+	}
+}
@@ -0,0 +1,202 @@
+package ray
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ImageFormats are the supported output formats, see ImageFormat.
+var ImageFormats = []string{"png", "jpeg", "ppm", "hdr"}
+
+// ImageFormat returns the image format (one of ImageFormats) to use for the given file name,
+// based on its extension. Unknown or missing extensions default to "png".
+func ImageFormat(fname string) string {
+	switch strings.ToLower(filepath.Ext(fname)) {
+	case ".jpg", ".jpeg":
+		return "jpeg"
+	case ".ppm":
+		return "ppm"
+	case ".hdr":
+		return "hdr"
+	default:
+		return "png"
+	}
+}
+
+// EncodeImage writes img to w in the given format (see ImageFormat). jpegQuality is
+// only used for jpeg, 0 means jpeg.DefaultQuality.
+func EncodeImage(w io.Writer, img image.Image, format string, jpegQuality int) error {
+	switch format {
+	case "png":
+		return png.Encode(w, img)
+	case "jpeg":
+		if jpegQuality <= 0 {
+			jpegQuality = jpeg.DefaultQuality
+		}
+		return jpeg.Encode(w, img, &jpeg.Options{Quality: min(jpegQuality, 100)})
+	case "ppm":
+		return WritePPM(w, img)
+	case "hdr":
+		return errors.New("hdr needs the linear colors, not an 8 bit image: use WriteHDR instead")
+	default:
+		return fmt.Errorf("unknown image format %q, should be one of %v", format, ImageFormats)
+	}
+}
+
+// SaveImage saves img to fname, picking the encoder from the file extension
+// (.jpg/.jpeg for JPEG with the given quality, .ppm for PPM, PNG otherwise).
+func SaveImage(img image.Image, fname string, jpegQuality int) error {
+	return SaveImageAs(img, fname, ImageFormat(fname), jpegQuality)
+}
+
+// SavePPM saves img to fname as a binary (P6) PPM, see WritePPM.
+func SavePPM(fname string, img *image.RGBA) error {
+	return SaveImageAs(img, fname, "ppm", 0)
+}
+
+// SaveImageAs saves img to fname in the given format (see EncodeImage), whatever its extension.
+func SaveImageAs(img image.Image, fname, format string, jpegQuality int) error {
+	f, err := os.Create(fname)
+	if err != nil {
+		return fmt.Errorf("could not create %s file %q: %w", strings.ToUpper(format), fname, err)
+	}
+	if err = EncodeImage(f, img, format, jpegQuality); err != nil {
+		f.Close()
+		return fmt.Errorf("could not encode %s to file %q: %w", strings.ToUpper(format), fname, err)
+	}
+	return f.Close()
+}
+
+// LoadImage reads a PNG, JPEG or binary (P6, as written by WritePPM) PPM image file, e.g.
+// a reference render for CompareImages.
+func LoadImage(fname string) (*image.RGBA, error) {
+	f, err := os.Open(fname)
+	if err != nil {
+		return nil, fmt.Errorf("could not open image %q: %w", fname, err)
+	}
+	defer f.Close()
+	var img image.Image
+	if ImageFormat(fname) == "ppm" {
+		img, err = ReadPPM(f)
+	} else {
+		img, _, err = image.Decode(f)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not decode image %q: %w", fname, err)
+	}
+	if rgba, ok := img.(*image.RGBA); ok {
+		return rgba, nil
+	}
+	b := img.Bounds()
+	rgba := image.NewRGBA(b)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			rgba.Set(x, y, img.At(x, y))
+		}
+	}
+	return rgba, nil
+}
+
+// ReadPPM reads a binary (P6) PPM with 8 bits per channel, as written by WritePPM
+// (comments in the header aren't supported).
+func ReadPPM(r io.Reader) (*image.RGBA, error) {
+	br := bufio.NewReader(r)
+	var magic string
+	var w, h, maxVal int
+	if _, err := fmt.Fscan(br, &magic, &w, &h, &maxVal); err != nil {
+		return nil, fmt.Errorf("invalid PPM header: %w", err)
+	}
+	if magic != "P6" || maxVal != 255 || w <= 0 || h <= 0 {
+		return nil, fmt.Errorf("unsupported PPM %s %dx%d with max value %d, only P6 with 255 is", magic, w, h, maxVal)
+	}
+	if _, err := br.ReadByte(); err != nil { // single whitespace before the pixels
+		return nil, err
+	}
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	rgb := make([]byte, 3*w)
+	for y := range h {
+		if _, err := io.ReadFull(br, rgb); err != nil {
+			return nil, fmt.Errorf("truncated PPM at line %d: %w", y, err)
+		}
+		row := img.Pix[y*img.Stride:]
+		for x := range w {
+			row[4*x], row[4*x+1], row[4*x+2], row[4*x+3] = rgb[3*x], rgb[3*x+1], rgb[3*x+2], 255
+		}
+	}
+	return img, nil
+}
+
+// WritePPM writes img to w as a binary (P6) PPM with 8 bits per channel, the format of the
+// "Ray Tracing in One Weekend" reference code (in its binary form), for byte level comparisons.
+// Alpha is ignored.
+func WritePPM(w io.Writer, img image.Image) error {
+	b := img.Bounds()
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "P6\n%d %d\n255\n", b.Dx(), b.Dy())
+	rgba, isRGBA := img.(*image.RGBA)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			var c color.RGBA
+			if isRGBA {
+				c = rgba.RGBAAt(x, y)
+			} else {
+				c, _ = color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)
+			}
+			_ = bw.WriteByte(c.R)
+			_ = bw.WriteByte(c.G)
+			_ = bw.WriteByte(c.B)
+		}
+	}
+	return bw.Flush()
+}
+
+// WriteHDR writes the w x h linear colors of buf (row by row, top first, e.g. from
+// Tracer.HDRBuffer) to out in the Radiance RGBE (.hdr) format, without clamping.
+// Negative components are written as 0. Scanlines are not run length encoded.
+func WriteHDR(out io.Writer, buf []ColorF, w, h int) error {
+	if w <= 0 || h <= 0 || len(buf) != w*h {
+		return fmt.Errorf("invalid HDR buffer: %d pixels for %dx%d", len(buf), w, h)
+	}
+	bw := bufio.NewWriter(out)
+	fmt.Fprintf(bw, "#?RADIANCE\nFORMAT=32-bit_rle_rgbe\n\n-Y %d +X %d\n", h, w)
+	for _, c := range buf {
+		rgbe := toRGBE(c)
+		_, _ = bw.Write(rgbe[:])
+	}
+	return bw.Flush()
+}
+
+// toRGBE encodes c as 3 mantissas sharing the exponent of the largest component.
+func toRGBE(c ColorF) [4]byte {
+	r, g, b := max(c.x, 0), max(c.y, 0), max(c.z, 0)
+	v := max(r, g, b)
+	if v < 1e-32 {
+		return [4]byte{}
+	}
+	m, e := math.Frexp(v)
+	scale := m * 256 / v
+	return [4]byte{byte(r * scale), byte(g * scale), byte(b * scale), byte(e + 128)}
+}
+
+// SaveHDR saves the w x h linear colors of buf to fname in the Radiance RGBE format, see WriteHDR.
+func SaveHDR(fname string, buf []ColorF, w, h int) error {
+	f, err := os.Create(fname)
+	if err != nil {
+		return fmt.Errorf("could not create HDR file %q: %w", fname, err)
+	}
+	if err = WriteHDR(f, buf, w, h); err != nil {
+		f.Close()
+		return fmt.Errorf("could not write HDR to file %q: %w", fname, err)
+	}
+	return f.Close()
+}
@@ -0,0 +1,232 @@
+package ray
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestImageFormat(t *testing.T) {
+	tests := []struct {
+		fname    string
+		expected string
+	}{
+		{"out.png", "png"},
+		{"out.PNG", "png"},
+		{"out.jpg", "jpeg"},
+		{"dir.v2/out.JPEG", "jpeg"},
+		{"out", "png"},
+		{"out.gif", "png"},
+		{"out.Hdr", "hdr"},
+		{"out.ppm", "ppm"},
+	}
+	for _, tt := range tests {
+		if got := ImageFormat(tt.fname); got != tt.expected {
+			t.Errorf("ImageFormat(%q) = %q, want %q", tt.fname, got, tt.expected)
+		}
+	}
+}
+
+func TestSaveImage(t *testing.T) {
+	img := skyGround()
+	dir := t.TempDir()
+	sizes := map[int]int{}
+	for _, tt := range []struct {
+		fname   string
+		quality int
+		format  string
+	}{
+		{"out.png", 0, "png"},
+		{"low.jpg", 10, "jpeg"},
+		{"high.jpeg", 100, "jpeg"},
+	} {
+		fname := filepath.Join(dir, tt.fname)
+		if err := SaveImage(img, fname, tt.quality); err != nil {
+			t.Fatalf("SaveImage(%q) error: %v", tt.fname, err)
+		}
+		data, err := os.ReadFile(fname)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded, format, err := image.Decode(bytes.NewReader(data))
+		if err != nil || format != tt.format || decoded.Bounds() != img.Bounds() {
+			t.Errorf("SaveImage(%q) decoded as %q %v (%v), want %q %v", tt.fname, format, decoded, err, tt.format, img.Bounds())
+		}
+		sizes[tt.quality] = len(data)
+	}
+	if sizes[10] >= sizes[100] {
+		t.Errorf("jpeg quality 10 size %d should be smaller than quality 100 size %d", sizes[10], sizes[100])
+	}
+	if err := SaveImage(img, filepath.Join(dir, "missing", "out.png"), 0); err == nil {
+		t.Error("Expected error saving to a missing directory")
+	}
+	if err := EncodeImage(&bytes.Buffer{}, img, "gif", 0); err == nil {
+		t.Error("Expected error for unknown format")
+	}
+	if err := SaveImage(img, filepath.Join(dir, "out.hdr"), 0); err == nil {
+		t.Error("Expected error saving an 8 bit image as hdr")
+	}
+}
+
+func TestSavePPM(t *testing.T) {
+	img := skyGround()
+	img.SetRGBA(1, 0, color.RGBA{10, 20, 30, 0}) // alpha is ignored
+	fname := filepath.Join(t.TempDir(), "out.ppm")
+	if err := SavePPM(fname, img); err != nil {
+		t.Fatalf("SavePPM() error: %v", err)
+	}
+	data, err := os.ReadFile(fname)
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := "P6\n8 4\n255\n"
+	if !bytes.HasPrefix(data, []byte(header)) || len(data) != len(header)+3*8*4 {
+		t.Fatalf("SavePPM() wrote %d bytes starting with %q, want header %q and %d pixel bytes",
+			len(data), data[:min(len(data), len(header))], header, 3*8*4)
+	}
+	pixels := data[len(header):]
+	for i, expected := range map[int][3]byte{0: {255, 255, 255}, 1: {10, 20, 30}, 8*2 + 3: {255, 0, 0}} {
+		if got := [3]byte(pixels[3*i : 3*i+3]); got != expected {
+			t.Errorf("pixel %d = %v, want %v", i, got, expected)
+		}
+	}
+	// Same bytes for a non RGBA image and through the extension based SaveImage.
+	var buf bytes.Buffer
+	if err = EncodeImage(&buf, image.NewNRGBA(image.Rect(0, 0, 2, 1)), "ppm", 0); err != nil {
+		t.Fatalf("EncodeImage(ppm) error: %v", err)
+	}
+	if got := buf.String(); got != "P6\n2 1\n255\n\x00\x00\x00\x00\x00\x00" {
+		t.Errorf("EncodeImage(ppm) of a black NRGBA = %q", got)
+	}
+	fname2 := filepath.Join(t.TempDir(), "out2.ppm")
+	if err = SaveImage(img, fname2, 0); err != nil {
+		t.Fatalf("SaveImage() error: %v", err)
+	}
+	if data2, _ := os.ReadFile(fname2); !bytes.Equal(data, data2) {
+		t.Errorf("SaveImage(.ppm) differs from SavePPM()")
+	}
+}
+
+func TestLoadImage(t *testing.T) {
+	img := skyGround()
+	img.SetRGBA(1, 0, color.RGBA{10, 20, 30, 255})
+	dir := t.TempDir()
+	for _, name := range []string{"ref.png", "ref.ppm"} {
+		fname := filepath.Join(dir, name)
+		if err := SaveImage(img, fname, 0); err != nil {
+			t.Fatalf("SaveImage(%q) error: %v", name, err)
+		}
+		loaded, err := LoadImage(fname)
+		if err != nil {
+			t.Fatalf("LoadImage(%q) error: %v", name, err)
+		}
+		if loaded.Bounds() != img.Bounds() || !bytes.Equal(loaded.Pix, img.Pix) {
+			t.Errorf("LoadImage(%q) = %v, want the saved image", name, loaded.Pix)
+		}
+	}
+	if _, err := LoadImage(filepath.Join(dir, "missing.png")); err == nil {
+		t.Error("Expected error loading a missing file")
+	}
+	for _, bad := range []string{"P3\n2 1\n255\n", "P6\n2 1\n65535\n", "P6\n2 1\n255\n\x00\x00"} {
+		if _, err := ReadPPM(strings.NewReader(bad)); err == nil {
+			t.Errorf("Expected error reading PPM %q", bad)
+		}
+	}
+}
+
+// readHDR decodes the flat (not run length encoded) RGBE files written by WriteHDR.
+func readHDR(t *testing.T, data []byte) ([]ColorF, int, int) {
+	t.Helper()
+	header, pixels, found := bytes.Cut(data, []byte("\n\n"))
+	if !found || !bytes.HasPrefix(header, []byte("#?RADIANCE\n")) {
+		t.Fatalf("bad HDR header %q", header)
+	}
+	resolution, pixels, _ := bytes.Cut(pixels, []byte("\n"))
+	var w, h int
+	if _, err := fmt.Sscanf(string(resolution), "-Y %d +X %d", &h, &w); err != nil {
+		t.Fatalf("bad HDR resolution %q: %v", resolution, err)
+	}
+	if len(pixels) != 4*w*h {
+		t.Fatalf("HDR has %d bytes of pixels, want %d", len(pixels), 4*w*h)
+	}
+	buf := make([]ColorF, w*h)
+	for i := range buf {
+		p := pixels[4*i : 4*i+4]
+		if p[3] == 0 {
+			continue
+		}
+		f := math.Ldexp(1, int(p[3])-128-8)
+		buf[i] = ColorF{float64(p[0]) * f, float64(p[1]) * f, float64(p[2]) * f}
+	}
+	return buf, w, h
+}
+
+func TestWriteHDR(t *testing.T) {
+	buf := []ColorF{{0, 0, 0}, {1, 0.5, 0.25}, {12, 3, 0}, {-1, 0.001, 0.002}, {1000, 2000, 4000}, {0.1, 0.1, 0.1}}
+	var out bytes.Buffer
+	if err := WriteHDR(&out, buf, 3, 2); err != nil {
+		t.Fatalf("WriteHDR() error: %v", err)
+	}
+	got, w, h := readHDR(t, out.Bytes())
+	if w != 3 || h != 2 {
+		t.Fatalf("readHDR() size = %dx%d, want 3x2", w, h)
+	}
+	for i, c := range buf {
+		want := ColorF{max(c.x, 0), max(c.y, 0), max(c.z, 0)}
+		// 8 bits of mantissa relative to the largest component.
+		tolerance := max(want.x, want.y, want.z) / 128
+		if !Equal(got[i], want, tolerance) {
+			t.Errorf("pixel %d = %v, want %v (+/- %g)", i, got[i], want, tolerance)
+		}
+	}
+	if err := WriteHDR(&out, buf, 2, 2); err == nil {
+		t.Error("Expected error for mismatched buffer size")
+	}
+}
+
+func TestTracerKeepHDR(t *testing.T) {
+	scene := &Scene{
+		Objects:    []Hittable{&Sphere{Center: Vec3{0, 0, -1}, Radius: 0.5, Mat: DiffuseLight{Emit: ColorF{4, 2, 1}}}},
+		Background: NoBackground,
+	}
+	rt := New(16, 8)
+	rt.Seed = 42
+	rt.VerticalFoV = 20
+	img := rt.Render(scene)
+	if rt.HDRBuffer() != nil {
+		t.Errorf("HDRBuffer() should be nil without KeepHDR")
+	}
+	rt.KeepHDR = true
+	img2 := rt.Render(scene)
+	if !bytes.Equal(img.Pix, img2.Pix) {
+		t.Errorf("KeepHDR should not change the 8 bit image")
+	}
+	hdr := rt.HDRBuffer()
+	if len(hdr) != 16*8 {
+		t.Fatalf("HDRBuffer() has %d pixels, want %d", len(hdr), 16*8)
+	}
+	// Center pixel sees the emitter unclamped, where the 8 bit image is saturated.
+	if c := hdr[4*16+8]; !Equal(c, ColorF{4, 2, 1}, 1e-9) {
+		t.Errorf("center HDR pixel = %v, want {4 2 1}", c)
+	}
+	fname := filepath.Join(t.TempDir(), "out.hdr")
+	if err := SaveHDR(fname, hdr, 16, 8); err != nil {
+		t.Fatalf("SaveHDR() error: %v", err)
+	}
+	data, err := os.ReadFile(fname)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _, _ := readHDR(t, data); !Equal(got[4*16+8], ColorF{4, 2, 1}, 1e-9) {
+		t.Errorf("saved center pixel = %v, want {4 2 1}", got[4*16+8])
+	}
+	if err = SaveHDR(filepath.Join(t.TempDir(), "missing", "out.hdr"), hdr, 16, 8); err == nil {
+		t.Error("Expected error saving to a missing directory")
+	}
+}
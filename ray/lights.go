@@ -0,0 +1,99 @@
+package ray
+
+import "math"
+
+// Light is a light source that isn't part of the scene geometry (Scene.Lights): diffuse
+// surfaces get its contribution directly through a shadow ray (next event estimation),
+// which converges much faster than waiting for random bounces to find the light.
+type Light interface {
+	// Illuminate returns the unit direction from point p toward the light, the distance
+	// to the light (+Inf when infinitely far) and the light color arriving at p.
+	Illuminate(p Vec3) (toLight Vec3, distance float64, color ColorF)
+}
+
+// DirectionalLight is an infinitely far light, like the sun: all its rays are parallel.
+type DirectionalLight struct {
+	// Direction the light travels in (e.g. {0, -1, 0} for light coming straight down).
+	Direction Vec3
+	// Color (intensity) of the light: a white diffuse surface facing the light
+	// reflects exactly that color.
+	Color ColorF
+}
+
+func (dl DirectionalLight) Illuminate(_ Vec3) (Vec3, float64, ColorF) {
+	return Unit(Neg(dl.Direction)), math.Inf(1), dl.Color
+}
+
+// PointLight is a light emitting equally in all directions from Position, with
+// inverse square falloff: the light at distance d is Color * Intensity / d^2.
+type PointLight struct {
+	Position Vec3
+	Color    ColorF
+	// Intensity multiplies Color (1 when 0).
+	Intensity float64
+}
+
+func (pl PointLight) Illuminate(p Vec3) (Vec3, float64, ColorF) {
+	return pointIlluminate(pl.Position, pl.Color, pl.Intensity, p)
+}
+
+func pointIlluminate(position Vec3, color ColorF, intensity float64, p Vec3) (Vec3, float64, ColorF) {
+	toLight := Sub(position, p)
+	distance2 := LengthSquared(toLight)
+	distance := math.Sqrt(distance2)
+	if intensity == 0 {
+		intensity = 1
+	}
+	return SDiv(toLight, distance), distance, SMul(color, intensity/distance2)
+}
+
+// SpotLight is a PointLight restricted to a cone around Direction: full intensity up to
+// ConeAngle - Falloff degrees from the axis, smoothly fading to nothing at ConeAngle.
+type SpotLight struct {
+	Position  Vec3
+	Direction Vec3 // Axis of the cone, pointing away from the light
+	Color     ColorF
+	// Intensity multiplies Color (1 when 0).
+	Intensity float64
+	// ConeAngle is the half angle of the cone of light, in degrees.
+	ConeAngle float64
+	// Falloff is the width, in degrees, of the soft edge inside the cone (0 for a sharp edge).
+	Falloff float64
+}
+
+func (sl SpotLight) Illuminate(p Vec3) (Vec3, float64, ColorF) {
+	toLight, distance, color := pointIlluminate(sl.Position, sl.Color, sl.Intensity, p)
+	cosAngle := -Dot(toLight, Unit(sl.Direction))
+	cosOuter := math.Cos(sl.ConeAngle * math.Pi / 180)
+	cosInner := math.Cos(math.Max(0, sl.ConeAngle-sl.Falloff) * math.Pi / 180)
+	switch {
+	case cosAngle < cosOuter:
+		return toLight, distance, ColorF{}
+	case cosAngle < cosInner:
+		f := (cosAngle - cosOuter) / (cosInner - cosOuter)
+		return toLight, distance, SMul(color, f*f*(3-2*f)) // smoothstep
+	default:
+		return toLight, distance, color
+	}
+}
+
+// directLight returns the light from s.Lights reaching the diffuse surface hit in hr
+// (with the given albedo), checking for occlusion with shadow rays (ignoring hits closer than epsilon).
+func (s *Scene) directLight(r *Ray, hr *HitRecord, albedo ColorF, epsilon float64, scratch *pathScratch) ColorF {
+	shadowHr, shadowRay := &scratch.shadowHr, &scratch.shadowRay
+	total := ColorF{0, 0, 0}
+	for _, light := range s.Lights {
+		toLight, distance, color := light.Illuminate(hr.Point)
+		cosTheta := Dot(hr.Normal, toLight)
+		if cosTheta <= 0 || color == (ColorF{}) {
+			continue // light is behind the surface (or doesn't reach this point)
+		}
+		*shadowRay = Ray{Rand: r.Rand, Origin: hr.Point, Direction: toLight, Time: r.Time, Channel: r.Channel}
+		scratch.shadowRays++
+		if s.Hit(shadowRay, Interval{Start: epsilon, End: distance}, shadowHr) {
+			continue // in the shadow
+		}
+		total = AddScaled(total, color, cosTheta)
+	}
+	return Mul(albedo, total)
+}
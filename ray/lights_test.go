@@ -0,0 +1,206 @@
+package ray
+
+import (
+	"math"
+	"testing"
+)
+
+// sunScene is a single sphere on a plane, lit only by a sun straight above.
+func sunScene() *Scene {
+	ground := Lambertian{Albedo: ColorF{0.5, 0.5, 0.5}}
+	return &Scene{
+		Objects: []Hittable{
+			NewQuad(Vec3{-10, 0, -10}, Vec3{20, 0, 0}, Vec3{0, 0, 20}, ground),
+			&Sphere{Center: Vec3{0, 1, 0}, Radius: 1, Mat: Lambertian{Albedo: ColorF{0.8, 0.2, 0.2}}},
+		},
+		Background: NoBackground,
+		Lights:     []Light{DirectionalLight{Direction: Vec3{0, -1, 0}, Color: ColorF{1, 1, 1}}},
+	}
+}
+
+func TestDirectionalLightIlluminate(t *testing.T) {
+	dl := DirectionalLight{Direction: Vec3{0, -2, 0}, Color: ColorF{1, 0.5, 0.25}}
+	toLight, distance, color := dl.Illuminate(Vec3{5, 6, 7})
+	if toLight != (Vec3{0, 1, 0}) || !math.IsInf(distance, 1) || color != dl.Color {
+		t.Errorf("Illuminate() = %v, %v, %v; want {0 1 0}, +Inf, %v", toLight, distance, color, dl.Color)
+	}
+}
+
+func TestDirectionalLightHardShadow(t *testing.T) {
+	scene := sunScene()
+	rnd := RandForTests()
+	// Depth 1: only the direct light, no bounce, so exact values.
+	groundColor := func(x, z float64) ColorF {
+		target := Vec3{x, 0, z}
+		origin := Vec3{x + 10, 0.5, z} // grazing, not to have the sphere in the way
+		return scene.RayColor(NewRay(rnd, origin, Sub(target, origin)), 1)
+	}
+	lit := ColorF{0.5, 0.5, 0.5}
+	tests := []struct {
+		name     string
+		x, z     float64
+		expected ColorF
+	}{
+		{"far from sphere", 5, 5, lit},
+		{"under the sphere", 0.3, -0.2, ColorF{}},
+		{"just inside the shadow", 0.99, 0, ColorF{}},
+		{"just outside the shadow", 1.01, 0, lit},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if c := groundColor(tt.x, tt.z); !Equal(c, tt.expected, 1e-9) {
+				t.Errorf("ground color at (%v, %v) = %v, want %v", tt.x, tt.z, c, tt.expected)
+			}
+		})
+	}
+	// Top of the sphere faces the sun, its side at 60 degrees gets half the light.
+	top := scene.RayColor(NewRay(rnd, Vec3{0, 10, 0}, Vec3{0, -1, 0}), 1)
+	if !Equal(top, ColorF{0.8, 0.2, 0.2}, 1e-9) {
+		t.Errorf("sphere top = %v, want its albedo", top)
+	}
+	side := Vec3{math.Sin(math.Pi / 3), 1 + math.Cos(math.Pi/3), 0}
+	origin := Add(side, Vec3{5, 0, 0})
+	if c := scene.RayColor(NewRay(rnd, origin, Sub(side, origin)), 1); !Equal(c, ColorF{0.4, 0.1, 0.1}, 1e-9) {
+		t.Errorf("sphere side = %v, want half its albedo", c)
+	}
+}
+
+func TestDirectionalLightOnlyDiffuse(t *testing.T) {
+	// Metal doesn't get direct light (only what its reflection sees).
+	scene := &Scene{
+		Objects:    []Hittable{&Sphere{Center: Vec3{0, 0, -2}, Radius: 1, Mat: Metal{Albedo: ColorF{1, 1, 1}}}},
+		Background: NoBackground,
+		Lights:     []Light{DirectionalLight{Direction: Vec3{0, 0, -1}, Color: ColorF{1, 1, 1}}},
+	}
+	if c := scene.RayColor(NewRay(RandForTests(), Vec3{0, 0, 0}, Vec3{0, 0, -1}), 1); c != (ColorF{}) {
+		t.Errorf("metal color = %v, want black", c)
+	}
+}
+
+func TestRender_DirectionalLight(t *testing.T) {
+	tracer := New(20, 20)
+	tracer.Position = Vec3{0, 6, 0.01}
+	tracer.LookAt = Vec3{0, 0, 0}
+	tracer.VerticalFoV = 60
+	img := tracer.Render(sunScene())
+	// Looking straight down: the sphere hides its own shadow, the ground around is lit.
+	if r, _, _, _ := img.At(0, 0).RGBA(); r == 0 {
+		t.Error("corner (ground) should be lit")
+	}
+	if r, g, _, _ := img.At(10, 10).RGBA(); r <= g {
+		t.Errorf("center should be the red sphere, got %v", img.At(10, 10))
+	}
+}
+
+func TestPointLightIlluminate(t *testing.T) {
+	tests := []struct {
+		name      string
+		light     PointLight
+		p         Vec3
+		toLight   Vec3
+		distance  float64
+		intensity ColorF
+	}{
+		{"default intensity", PointLight{Position: Vec3{0, 2, 0}, Color: ColorF{1, 1, 1}}, Vec3{}, Vec3{0, 1, 0}, 2,
+			ColorF{0.25, 0.25, 0.25}},
+		{"inverse square", PointLight{Position: Vec3{0, 0, 0}, Color: ColorF{1, 0.5, 0}, Intensity: 8}, Vec3{4, 0, 0},
+			Vec3{-1, 0, 0}, 4, ColorF{0.5, 0.25, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			toLight, distance, color := tt.light.Illuminate(tt.p)
+			if toLight != tt.toLight || distance != tt.distance || color != tt.intensity {
+				t.Errorf("Illuminate(%v) = %v, %v, %v; want %v, %v, %v",
+					tt.p, toLight, distance, color, tt.toLight, tt.distance, tt.intensity)
+			}
+		})
+	}
+}
+
+func TestSpotLightIlluminate(t *testing.T) {
+	spot := SpotLight{
+		Position: Vec3{0, 1, 0}, Direction: Vec3{0, -1, 0}, Color: ColorF{1, 1, 1},
+		ConeAngle: 45, Falloff: 15,
+	}
+	// Point on the ground at the given angle from the spot's axis.
+	at := func(angle float64) ColorF {
+		p := Vec3{math.Tan(angle * math.Pi / 180), 0, 0}
+		_, distance, color := spot.Illuminate(p)
+		return SMul(color, distance*distance) // undo the inverse square falloff
+	}
+	tests := []struct {
+		angle    float64
+		expected float64
+	}{
+		{0, 1},
+		{29, 1},
+		{37.5, 0.5}, // middle of the soft edge (about, the interpolation is on the cosine)
+		{46, 0},
+		{80, 0},
+	}
+	for _, tt := range tests {
+		if c := at(tt.angle); math.Abs(c.x-tt.expected) > 0.1 {
+			t.Errorf("spot light at %v degrees = %v, want %v", tt.angle, c.x, tt.expected)
+		}
+	}
+	// Sharp edge without falloff.
+	spot.Falloff = 0
+	if c := at(44.9); math.Abs(c.x-1) > 1e-9 {
+		t.Errorf("sharp spot light inside the cone = %v, want 1", c.x)
+	}
+}
+
+func TestPointLightShadow(t *testing.T) {
+	scene := sunScene()
+	scene.Lights = []Light{PointLight{Position: Vec3{0, 4, 0}, Color: ColorF{1, 1, 1}, Intensity: 16}}
+	rnd := RandForTests()
+	groundColor := func(x float64) ColorF {
+		target := Vec3{x, 0, 0}
+		origin := Vec3{x + 10, 0.5, 0}
+		return scene.RayColor(NewRay(rnd, origin, Sub(target, origin)), 1)
+	}
+	// Closer to the light than the sphere's shadow edge (x ~ 1.5 for a light at height 4).
+	if c := groundColor(1.3); c != (ColorF{}) {
+		t.Errorf("ground under the sphere = %v, want black", c)
+	}
+	// Lit point: 0.5 albedo * 16 / d^2 * cos(theta).
+	x := 3.0
+	d2 := x*x + 16
+	want := 0.5 * 16 / d2 * 4 / math.Sqrt(d2)
+	if c := groundColor(x); math.Abs(c.x-want) > 1e-9 {
+		t.Errorf("lit ground = %v, want %v", c.x, want)
+	}
+}
+
+func TestRender_NoAcneOnBigGroundSphere(t *testing.T) {
+	// RichScene's ground lit by a grazing sun, direct light only: every ground pixel is lit
+	// unless shadow rays hit the ground they start from.
+	scene := &Scene{
+		Objects:    []Hittable{NewSphere(Vec3{0, -1000, 0}, 1000, Lambertian{Albedo: ColorF{0.5, 0.5, 0.5}})},
+		Background: NoBackground,
+		Lights:     []Light{DirectionalLight{Direction: Vec3{-0.3, -0.02, -1}, Color: ColorF{1, 1, 1}}},
+	}
+	acne := func(epsilon float64) int {
+		rt := New(200, 100)
+		rt.Seed = 42
+		rt.MaxDepth = 1
+		rt.ShadowEpsilon = epsilon
+		rt.Camera = Camera{Position: Vec3{13, 2, 3}, LookAt: Vec3{0, 0, -100}, VerticalFoV: 60}
+		img := rt.Render(scene)
+		dark := 0
+		for y := 60; y < 100; y++ { // below the horizon
+			for x := range 200 {
+				if img.RGBAAt(x, y).R == 0 {
+					dark++
+				}
+			}
+		}
+		return dark
+	}
+	if n := acne(0); n != 0 {
+		t.Errorf("%d dark ground pixels with the default ShadowEpsilon, want none", n)
+	}
+	if n := acne(1e-12); n == 0 {
+		t.Errorf("expected some acne with a too small ShadowEpsilon")
+	}
+}
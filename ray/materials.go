@@ -1,66 +1,318 @@
 package ray
 
-import "math"
+import (
+	"math"
+
+	"fortio.org/rand"
+)
 
 type Material interface {
-	Scatter(rIn *Ray, rec *HitRecord) (bool, ColorF, *Ray)
+	// Scatter returns whether the incoming ray is scattered, and if so the attenuation and
+	// the scattered ray (by value, which carries on rIn's random source and time).
+	Scatter(rIn *Ray, rec *HitRecord) (bool, ColorF, Ray)
+	// Emitted returns the light emitted by the material (black for non light sources).
+	Emitted() ColorF
 }
 
 type Lambertian struct {
 	Albedo ColorF
+	// Tex, when set, is used instead of the solid Albedo color.
+	Tex Texture
+	// BookScatter uses the normal plus a random unit vector for the scattered direction,
+	// as in "Ray Tracing in One Weekend", instead of RandomCosineDirection. Both are cosine
+	// distributed but consume the random numbers differently (e.g. to compare images with
+	// the book's C++ output).
+	BookScatter bool
+	// NormalMap, when set, perturbs the shading normal (see ApplyNormalMap).
+	NormalMap Texture
+}
+
+// DiffuseMaterial is implemented by diffuse materials, which get direct light from Scene.Lights.
+type DiffuseMaterial interface {
+	Material
+	// DiffuseAlbedo returns the diffuse color at the hit point.
+	DiffuseAlbedo(rec *HitRecord) ColorF
 }
 
-func (l Lambertian) Scatter(rIn *Ray, rec *HitRecord) (bool, ColorF, *Ray) {
+func (l Lambertian) DiffuseAlbedo(rec *HitRecord) ColorF {
+	if l.Tex != nil {
+		return l.Tex.Value(rec.U, rec.V, rec.Point)
+	}
+	return l.Albedo
+}
+
+func (l Lambertian) Scatter(rIn *Ray, rec *HitRecord) (bool, ColorF, Ray) {
+	albedo := l.DiffuseAlbedo(rec)
+	if !l.BookScatter {
+		return true, albedo, Ray{Rand: rIn.Rand, Origin: rec.Point, Direction: RandomCosineDirection(rIn.Rand, rec.Normal), Time: rIn.Time, Channel: rIn.Channel}
+	}
 	scatterDirection := Add(rec.Normal, RandomUnitVector(rIn.Rand))
 	// Catch degenerate scatter direction
 	if NearZero(scatterDirection) {
 		scatterDirection = rec.Normal
 	}
-	scattered := NewRay(rIn.Rand, rec.Point, scatterDirection)
-	return true, l.Albedo, scattered
+	return true, albedo, Ray{Rand: rIn.Rand, Origin: rec.Point, Direction: scatterDirection, Time: rIn.Time, Channel: rIn.Channel}
+}
+
+func (l Lambertian) Emitted() ColorF {
+	return ColorF{}
+}
+
+func (l Lambertian) normalMap() Texture {
+	return l.NormalMap
 }
 
 type Metal struct {
 	Albedo ColorF
-	Fuzz   float64
+	// Tex, when set, is used instead of the solid Albedo color (e.g. rusted or patterned metal).
+	Tex Texture
+	// Fuzz perturbs the mirror reflection by a random vector of that length.
+	// Kept for compatibility, Roughness is preferred (Fuzz is ignored when it is set).
+	Fuzz float64
+	// Roughness in [0,1] samples the reflection from a GGX (Trowbridge-Reitz) microfacet
+	// distribution around the mirror direction. 0 is a perfect mirror.
+	Roughness float64
+	// NormalMap, when set, perturbs the shading normal (see ApplyNormalMap).
+	NormalMap Texture
 }
 
-func (m Metal) Scatter(rIn *Ray, rec *HitRecord) (bool, ColorF, *Ray) {
-	reflected := Reflect(Unit(rIn.Direction), rec.Normal)
-	if m.Fuzz > 0.0 {
-		reflected = Add(reflected, SMul(RandomUnitVector(rIn.Rand), m.Fuzz))
+func (m Metal) Scatter(rIn *Ray, rec *HitRecord) (bool, ColorF, Ray) {
+	unitDirection := Unit(rIn.Direction)
+	var reflected Vec3
+	switch {
+	case m.Roughness > 0:
+		reflected = reflectGGX(rIn.Rand, unitDirection, rec.Normal, m.Roughness)
+	case m.Fuzz > 0.0:
+		reflected = Add(Reflect(unitDirection, rec.Normal), SMul(RandomUnitVector(rIn.Rand), m.Fuzz))
+	default:
+		reflected = Reflect(unitDirection, rec.Normal)
+	}
+	if Dot(reflected, rec.Normal) > 0 {
+		return true, m.albedo(rec), Ray{Rand: rIn.Rand, Origin: rec.Point, Direction: reflected, Time: rIn.Time, Channel: rIn.Channel}
 	}
-	scattered := NewRay(rIn.Rand, rec.Point, reflected)
-	if Dot(scattered.Direction, rec.Normal) > 0 {
-		return true, m.Albedo, scattered
+	return false, ColorF{}, Ray{}
+}
+
+// albedo returns the reflection attenuation at the hit point.
+func (m Metal) albedo(rec *HitRecord) ColorF {
+	if m.Tex != nil {
+		return m.Tex.Value(rec.U, rec.V, rec.Point)
 	}
-	return false, ColorF{}, nil
+	return m.Albedo
+}
+
+func (m Metal) Emitted() ColorF {
+	return ColorF{}
+}
+
+func (m Metal) normalMap() Texture {
+	return m.NormalMap
+}
+
+// normalMapped is implemented by materials with an optional NormalMap, which the
+// tracer applies (see ApplyNormalMap) to the hit record before shading it.
+type normalMapped interface {
+	normalMap() Texture
+}
+
+// ApplyNormalMap replaces rec.Normal by the normal read from the normal map texture in
+// tangent space: the usual encoding where the color (r, g, b) in [0,1] maps to the
+// direction (2r-1, 2g-1, 2b-1) along (Tangent, Normal x Tangent, Normal). So the flat
+// color (0.5, 0.5, 1) leaves the normal unchanged. When the hit has no Tangent, an
+// arbitrary one is used, which is only fine for rotationally symmetric maps.
+func ApplyNormalMap(normalMap Texture, rec *HitRecord) {
+	c := normalMap.Value(rec.U, rec.V, rec.Point)
+	n := rec.Normal
+	var t, b Vec3
+	if tangent := Sub(rec.Tangent, SMul(n, Dot(n, rec.Tangent))); NearZero(tangent) {
+		t, b = orthonormalBasis(n)
+	} else {
+		t = Unit(tangent)
+		b = Cross(n, t)
+	}
+	rec.Normal = Unit(AddMultiple(SMul(t, 2*c.x-1), SMul(b, 2*c.y-1), SMul(n, 2*c.z-1)))
+}
+
+// ggxMaxTries is how many microfacet normals reflectGGX samples before giving up
+// (the caller then sees a direction below the surface and absorbs the ray).
+const ggxMaxTries = 8
+
+// reflectGGX reflects the unit direction v about a microfacet normal sampled from the GGX
+// distribution (alpha = roughness^2) around the surface normal n, rejecting samples
+// that would go below the surface.
+func reflectGGX(rng rand.Rand, v, n Vec3, roughness float64) Vec3 {
+	alpha2 := roughness * roughness * roughness * roughness
+	u, w := orthonormalBasis(n)
+	var reflected Vec3
+	for range ggxMaxTries {
+		reflected = Reflect(v, ggxNormal(rng, u, w, n, alpha2))
+		if Dot(reflected, n) > 0 {
+			break
+		}
+	}
+	return reflected
+}
+
+// ggxNormal returns a microfacet normal sampled from the GGX distribution (alpha2 is
+// roughness^4) around the unit normal n, with (u, w, n) an orthonormal basis.
+func ggxNormal(rng rand.Rand, u, w, n Vec3, alpha2 float64) Vec3 {
+	xi1, xi2 := rng.Float64(), rng.Float64()
+	cosTheta := math.Sqrt((1 - xi1) / (1 + (alpha2-1)*xi1))
+	sinTheta := math.Sqrt(1 - cosTheta*cosTheta)
+	phi := 2 * math.Pi * xi2
+	return AddMultiple(SMul(u, sinTheta*math.Cos(phi)), SMul(w, sinTheta*math.Sin(phi)), SMul(n, cosTheta))
+}
+
+// orthonormalBasis returns two unit vectors u, v such that (u, v, n) is an orthonormal
+// basis, for the unit vector n.
+func orthonormalBasis(n Vec3) (u, v Vec3) {
+	a := Vec3{1, 0, 0}
+	if math.Abs(n.x) > 0.9 {
+		a = Vec3{0, 1, 0}
+	}
+	v = Unit(Cross(n, a))
+	u = Cross(v, n)
+	return u, v
 }
 
 type Dielectric struct {
 	RefIdx float64
+	// Absorption is the extinction coefficient per unit distance traveled inside the
+	// material (Beer-Lambert law), for tinted glass. Zero (default) means clear glass.
+	// For instance {0, 0.5, 0.5} absorbs green and blue, so the glass looks red and
+	// more so the thicker it is.
+	Absorption ColorF
+	// Roughness in [0,1] refracts and reflects off a microfacet normal sampled from a GGX
+	// distribution (like Metal.Roughness) instead of the surface normal: frosted glass.
+	Roughness float64
+	// Tint, when set, multiplies the light refracted into the object (so once per crossing
+	// of a closed object, independently of its thickness unlike Absorption).
+	Tint ColorF
+	// Dispersion, when > 0, makes the refractive index depend on the wavelength following
+	// Cauchy's equation n = A + Dispersion/λ² (λ in µm, e.g. 0.0042 for crown glass and
+	// 0.01 for flint), with RefIdx the index for green light. See ChannelRefIdx.
+	//
+	// The first dispersive hit of a path picks one color channel at random (weighted by 3
+	// so the image stays unbiased) and stores it in Ray.Channel: from then on the path
+	// refracts with that channel's index and carries only that channel. So white light
+	// gets split into colors (prisms, rainbows) without tracing 3 rays, at the cost of
+	// more noise on dispersive objects.
+	Dispersion float64
+}
+
+// dispersionWavelengths are the wavelengths (in µm) used for the red, green and blue
+// channels of dispersive materials.
+var dispersionWavelengths = [3]float64{0.65, 0.55, 0.45}
+
+// ChannelRefIdx returns the refractive index for the color channel (0 for red, 1 for
+// green, 2 for blue) according to Dispersion: RefIdx for green, lower for red and higher
+// for blue. It's RefIdx for all channels when Dispersion is 0.
+func (d Dielectric) ChannelRefIdx(channel int) float64 {
+	green := dispersionWavelengths[1]
+	lambda := dispersionWavelengths[channel]
+	return d.RefIdx + d.Dispersion*(1/(lambda*lambda)-1/(green*green))
 }
 
-func (d Dielectric) Scatter(rIn *Ray, rec *HitRecord) (bool, ColorF, *Ray) {
+func (d Dielectric) Scatter(rIn *Ray, rec *HitRecord) (bool, ColorF, Ray) {
 	attenuation := ColorF{1.0, 1.0, 1.0}
+	refIdx := d.RefIdx
+	channel := rIn.Channel
+	if d.Dispersion > 0 && channel == 0 {
+		// First dispersive hit of the path: pick its channel, the others are dropped.
+		channel = 1 + rIn.IntN(3)
+		attenuation = ColorF{}
+		switch channel {
+		case 1:
+			attenuation.x = 3
+		case 2:
+			attenuation.y = 3
+		default:
+			attenuation.z = 3
+		}
+	}
+	if d.Dispersion > 0 {
+		refIdx = d.ChannelRefIdx(channel - 1)
+	}
 	var refractionRatio float64
 	if rec.FrontFace {
-		refractionRatio = 1.0 / d.RefIdx
+		refractionRatio = 1.0 / refIdx
 	} else {
-		refractionRatio = d.RefIdx
+		refractionRatio = refIdx
+		// Hitting the back face: the incoming ray went through the inside of the object.
+		if d.Absorption != (ColorF{}) {
+			attenuation = Mul(attenuation, BeerLambert(d.Absorption, rec.T*Length(rIn.Direction)))
+		}
 	}
 	unitDirection := Unit(rIn.Direction)
-	cosTheta := math.Min(Dot(Neg(unitDirection), rec.Normal), 1.0)
+	var direction Vec3
+	var refracted, found bool
+	if d.Roughness > 0 {
+		// Retry microfacets that would send the ray to the wrong side of the surface.
+		alpha2 := d.Roughness * d.Roughness * d.Roughness * d.Roughness
+		u, w := orthonormalBasis(rec.Normal)
+		for range ggxMaxTries {
+			h := ggxNormal(rIn.Rand, u, w, rec.Normal, alpha2)
+			direction, refracted = refractOrReflect(rIn.Rand, unitDirection, h, refractionRatio)
+			if found = (Dot(direction, rec.Normal) < 0) == refracted; found {
+				break
+			}
+		}
+	}
+	if !found {
+		direction, refracted = refractOrReflect(rIn.Rand, unitDirection, rec.Normal, refractionRatio)
+	}
+	if refracted && rec.FrontFace && d.Tint != (ColorF{}) {
+		attenuation = Mul(attenuation, d.Tint)
+	}
+	return true, attenuation, Ray{Rand: rIn.Rand, Origin: rec.Point, Direction: direction, Time: rIn.Time, Channel: channel}
+}
+
+// refractOrReflect returns the direction of the unit vector v hitting the surface of
+// normal n (facing v), picking between reflection and refraction with the Schlick
+// approximation, and whether it's refracted.
+func refractOrReflect(rng rand.Rand, v, n Vec3, refractionRatio float64) (Vec3, bool) {
+	cosTheta := math.Min(Dot(Neg(v), n), 1.0)
 	sinTheta := math.Sqrt(1.0 - cosTheta*cosTheta)
 	cannotRefract := (refractionRatio*sinTheta > 1.0)
-	var direction Vec3
-	if cannotRefract || Reflectance(cosTheta, refractionRatio) > rIn.Float64() {
-		direction = Reflect(unitDirection, rec.Normal)
-	} else {
-		direction = Refract(unitDirection, rec.Normal, refractionRatio)
+	if cannotRefract || Reflectance(cosTheta, refractionRatio) > rng.Float64() {
+		return Reflect(v, n), false
+	}
+	return Refract(v, n, refractionRatio), true
+}
+
+func (d Dielectric) Emitted() ColorF {
+	return ColorF{}
+}
+
+// MixMaterial is a probabilistic blend of two materials: each ray scatters off B with
+// probability Ratio and off A otherwise, e.g. {A: Lambertian, B: Metal, Ratio: 0.2} for a
+// 20% metallic surface. It's a cheap approximation of coated surfaces, not a layered BSDF.
+// It doesn't get direct light from Scene.Lights (even if A or B is diffuse).
+type MixMaterial struct {
+	A, B  Material
+	Ratio float64
+}
+
+func (mm MixMaterial) Scatter(rIn *Ray, rec *HitRecord) (bool, ColorF, Ray) {
+	if rIn.Float64() < mm.Ratio {
+		return mm.B.Scatter(rIn, rec)
+	}
+	return mm.A.Scatter(rIn, rec)
+}
+
+// Emitted is the blend of both materials' emission, in proportion of Ratio.
+func (mm MixMaterial) Emitted() ColorF {
+	return AddScaled(SMul(mm.A.Emitted(), 1-mm.Ratio), mm.B.Emitted(), mm.Ratio)
+}
+
+// BeerLambert returns the fraction of light transmitted through distance of a medium
+// with the given absorption coefficients: exp(-absorption * distance) per channel.
+func BeerLambert(absorption ColorF, distance float64) ColorF {
+	return ColorF{
+		math.Exp(-absorption.x * distance),
+		math.Exp(-absorption.y * distance),
+		math.Exp(-absorption.z * distance),
 	}
-	scattered := NewRay(rIn.Rand, rec.Point, direction)
-	return true, attenuation, scattered
 }
 
 func Reflectance(cosine, refIdx float64) float64 {
@@ -69,3 +321,16 @@ func Reflectance(cosine, refIdx float64) float64 {
 	r0 *= r0
 	return r0 + (1-r0)*math.Pow((1-cosine), 5)
 }
+
+// DiffuseLight is a light source material: it emits Emit and doesn't scatter.
+type DiffuseLight struct {
+	Emit ColorF
+}
+
+func (dl DiffuseLight) Scatter(_ *Ray, _ *HitRecord) (bool, ColorF, Ray) {
+	return false, ColorF{}, Ray{}
+}
+
+func (dl DiffuseLight) Emitted() ColorF {
+	return dl.Emit
+}
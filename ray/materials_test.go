@@ -2,6 +2,7 @@ package ray
 
 import (
 	"math"
+	"slices"
 	"testing"
 )
 
@@ -22,9 +23,7 @@ func TestLambertianScatter(t *testing.T) {
 	if attenuation != lambertian.Albedo {
 		t.Errorf("Expected attenuation %v, got %v", lambertian.Albedo, attenuation)
 	}
-	if scattered == nil {
-		t.Error("Expected scattered ray to be non-nil")
-	} else if scattered.Origin != rec.Point {
+	if scattered.Origin != rec.Point {
 		t.Errorf("Expected scattered origin %v, got %v", rec.Point, scattered.Origin)
 	}
 }
@@ -47,9 +46,6 @@ func TestMetalScatter(t *testing.T) {
 	if attenuation != metal.Albedo {
 		t.Errorf("Expected attenuation %v, got %v", metal.Albedo, attenuation)
 	}
-	if scattered == nil {
-		t.Fatal("Expected scattered ray")
-	}
 	if scattered.Origin != rec.Point {
 		t.Errorf("Expected scattered origin %v, got %v", rec.Point, scattered.Origin)
 	}
@@ -70,9 +66,6 @@ func TestMetalScatterWithFuzz(t *testing.T) {
 	if !didScatter {
 		t.Error("Expected metal to scatter")
 	}
-	if scattered == nil {
-		t.Fatal("Expected scattered ray")
-	}
 	// With fuzz, direction should be perturbed
 	if scattered.Origin != rec.Point {
 		t.Errorf("Expected scattered origin %v, got %v", rec.Point, scattered.Origin)
@@ -110,6 +103,92 @@ func TestMetalScatterAbsorbedWhenReflectionBelowSurface(t *testing.T) {
 	}
 }
 
+func TestMetalRoughnessZeroIsMirror(t *testing.T) {
+	rnd := RandForTests()
+	rayDir := Unit(Vec3{1, -1, 0.5})
+	ray := NewRay(rnd, Vec3{0, 2, 0}, rayDir)
+	rec := &HitRecord{Point: Vec3{1, 1, 0}, Normal: Vec3{0, 1, 0}}
+	expected := Reflect(rayDir, rec.Normal)
+	for _, metal := range []Metal{{Albedo: ColorF{1, 1, 1}}, {Albedo: ColorF{1, 1, 1}, Roughness: 0}} {
+		didScatter, _, scattered := metal.Scatter(ray, rec)
+		if !didScatter || scattered.Direction != expected {
+			t.Errorf("%+v Scatter() = %v, %v; want perfect mirror %v", metal, didScatter, scattered, expected)
+		}
+	}
+}
+
+func TestMetalTexture(t *testing.T) {
+	albedo := ColorF{0.7, 0.5, 0.3}
+	flat := Metal{Albedo: albedo, Roughness: 0.4}
+	textured := Metal{Albedo: ColorF{1, 0, 1}, Tex: SolidColor{Albedo: albedo}, Roughness: 0.4} // Albedo ignored
+	rec := &HitRecord{Point: Vec3{1, 1, 0}, Normal: Vec3{0, 1, 0}, U: 0.3, V: 0.6}
+	rayDir := Unit(Vec3{1, -1, 0.2})
+	rndFlat, rndTex := RandForTests(), RandForTests()
+	for i := range 100 {
+		s1, a1, r1 := flat.Scatter(NewRay(rndFlat, Vec3{0, 2, 0}, rayDir), rec)
+		s2, a2, r2 := textured.Scatter(NewRay(rndTex, Vec3{0, 2, 0}, rayDir), rec)
+		if s1 != s2 || a1 != a2 || r1.Direction != r2.Direction || r1.Origin != r2.Origin {
+			t.Fatalf("%d: textured Scatter() = %v, %v, %v; want the flat albedo's %v, %v, %v",
+				i, s2, a2, r2.Direction, s1, a1, r1.Direction)
+		}
+	}
+	// The attenuation follows the texture at the hit point.
+	checker := Metal{Tex: CheckerTexture{Scale: 1, Even: ColorF{1, 1, 1}, Odd: ColorF{0.2, 0.1, 0}}}
+	for _, p := range []Vec3{{0.5, 0, 0.5}, {1.5, 0, 0.5}} {
+		rec := &HitRecord{Point: p, Normal: Vec3{0, 1, 0}}
+		_, attenuation, _ := checker.Scatter(NewRay(RandForTests(), Vec3{0, 1, 0}, Vec3{0.1, -1, 0}), rec)
+		if want := checker.Tex.Value(0, 0, p); attenuation != want {
+			t.Errorf("attenuation at %v = %v, want %v", p, attenuation, want)
+		}
+	}
+}
+
+func TestMetalRoughness(t *testing.T) {
+	rayDir := Unit(Vec3{1, -1, 0})
+	rec := &HitRecord{Point: Vec3{1, 1, 0}, Normal: Vec3{0, 1, 0}}
+	mirror := Reflect(rayDir, rec.Normal)
+	// Average cosine between the scattered rays and the mirror direction.
+	spread := func(roughness float64) float64 {
+		rnd := RandForTests()
+		metal := Metal{Albedo: ColorF{1, 1, 1}, Roughness: roughness, Fuzz: 5} // Fuzz ignored
+		sum, n := 0.0, 0
+		for range 1000 {
+			didScatter, _, scattered := metal.Scatter(NewRay(rnd, Vec3{0, 2, 0}, rayDir), rec)
+			if !didScatter {
+				continue
+			}
+			if Dot(scattered.Direction, rec.Normal) <= 0 {
+				t.Fatalf("roughness %v: scattered %v below the surface", roughness, scattered.Direction)
+			}
+			sum += Dot(Unit(scattered.Direction), mirror)
+			n++
+		}
+		return sum / float64(n)
+	}
+	smooth, medium, rough := spread(0.05), spread(0.3), spread(0.8)
+	if smooth < 0.99 {
+		t.Errorf("roughness 0.05 average cosine to mirror = %v, want close to 1", smooth)
+	}
+	if medium >= smooth || rough >= medium {
+		t.Errorf("average cosine to mirror should decrease with roughness: %v, %v, %v", smooth, medium, rough)
+	}
+}
+
+func TestOrthonormalBasis(t *testing.T) {
+	for _, n := range []Vec3{{0, 1, 0}, {1, 0, 0}, {0, 0, -1}, Unit(Vec3{1, 2, 3})} {
+		u, v := orthonormalBasis(n)
+		if math.Abs(Length(u)-1) > 1e-12 || math.Abs(Length(v)-1) > 1e-12 {
+			t.Errorf("orthonormalBasis(%v) = %v, %v; want unit vectors", n, u, v)
+		}
+		if math.Abs(Dot(u, v)) > 1e-12 || math.Abs(Dot(u, n)) > 1e-12 || math.Abs(Dot(v, n)) > 1e-12 {
+			t.Errorf("orthonormalBasis(%v) = %v, %v; want orthogonal vectors", n, u, v)
+		}
+		if !Equal(Cross(u, v), n, 1e-12) {
+			t.Errorf("orthonormalBasis(%v) = %v, %v; want u x v = n", n, u, v)
+		}
+	}
+}
+
 func TestDielectricScatterFrontFace(t *testing.T) {
 	rnd := RandForTests()
 	dielectric := Dielectric{RefIdx: 1.5}
@@ -130,9 +209,6 @@ func TestDielectricScatterFrontFace(t *testing.T) {
 	if attenuation != expected {
 		t.Errorf("Expected attenuation %v, got %v", expected, attenuation)
 	}
-	if scattered == nil {
-		t.Fatal("Expected scattered ray")
-	}
 	if scattered.Origin != rec.Point {
 		t.Errorf("Expected scattered origin %v, got %v", rec.Point, scattered.Origin)
 	}
@@ -158,7 +234,7 @@ func TestDielectricScatterBackFace(t *testing.T) {
 	if attenuation != expected {
 		t.Errorf("Expected attenuation %v, got %v", expected, attenuation)
 	}
-	if scattered != nil && scattered.Origin != rec.Point {
+	if scattered.Origin != rec.Point {
 		t.Errorf("Expected scattered origin %v, got %v", rec.Point, scattered.Origin)
 	}
 }
@@ -195,13 +271,193 @@ func TestDielectricScatterVariousAngles(t *testing.T) {
 			if attenuation != (ColorF{1, 1, 1}) {
 				t.Errorf("Expected white attenuation, got %v", attenuation)
 			}
-			if scattered == nil || scattered.Origin != rec.Point {
+			if scattered.Origin != rec.Point {
 				t.Error("Expected valid scattered ray from hit point")
 			}
 		})
 	}
 }
 
+func TestDielectricRoughness(t *testing.T) {
+	rayDir := Unit(Vec3{1, -1, 0})
+	rec := &HitRecord{Point: Vec3{0, 0, 0}, Normal: Vec3{0, 1, 0}, FrontFace: true}
+	refracted, reflected := Refract(rayDir, rec.Normal, 1/1.5), Reflect(rayDir, rec.Normal)
+	for _, roughness := range []float64{0, 0.5} {
+		dielectric := Dielectric{RefIdx: 1.5, Roughness: roughness}
+		ray := NewRay(RandForTests(), Vec3{-1, 1, 0}, rayDir)
+		distinct := make(map[Vec3]bool)
+		for range 100 {
+			_, _, scattered := dielectric.Scatter(ray, rec)
+			d := scattered.Direction
+			distinct[d] = true
+			if roughness == 0 && !Equal(d, refracted, 1e-12) && !Equal(d, reflected, 1e-12) {
+				t.Fatalf("Roughness 0: direction %v, want refracted %v or reflected %v", d, refracted, reflected)
+			}
+			if math.Abs(Length(d)-1) > 1e-9 || d.y == 0 {
+				t.Errorf("Roughness %v: direction %v, want unit and not along the surface", roughness, d)
+			}
+		}
+		if roughness > 0 && len(distinct) < 90 {
+			t.Errorf("Roughness %v: %d distinct directions out of 100, want varied directions", roughness, len(distinct))
+		}
+	}
+}
+
+func TestDielectricTint(t *testing.T) {
+	tint := ColorF{0.2, 0.6, 1}
+	dielectric := Dielectric{RefIdx: 1.5, Tint: tint}
+	for _, frontFace := range []bool{true, false} {
+		// Straight on, so mostly refracted (only ~4% reflected).
+		ray := NewRay(RandForTests(), Vec3{0, 1, 0}, Vec3{0, -1, 0})
+		rec := &HitRecord{Normal: Vec3{0, 1, 0}, FrontFace: frontFace}
+		for range 100 {
+			_, attenuation, scattered := dielectric.Scatter(ray, rec)
+			want := ColorF{1, 1, 1}
+			if frontFace && scattered.Direction.y < 0 {
+				want = tint // refracted into the object
+			}
+			if attenuation != want {
+				t.Fatalf("front face %v, direction %v: attenuation %v, want %v", frontFace, scattered.Direction, attenuation, want)
+			}
+		}
+	}
+}
+
+func TestDielectricChannelRefIdx(t *testing.T) {
+	flint := Dielectric{RefIdx: 1.6, Dispersion: 0.01}
+	r, g, b := flint.ChannelRefIdx(0), flint.ChannelRefIdx(1), flint.ChannelRefIdx(2)
+	if g != 1.6 || r >= g || b <= g {
+		t.Errorf("ChannelRefIdx() = %v, %v, %v, want red < 1.6 = green < blue", r, g, b)
+	}
+	if clear := (Dielectric{RefIdx: 1.5}); clear.ChannelRefIdx(0) != 1.5 || clear.ChannelRefIdx(2) != 1.5 {
+		t.Errorf("ChannelRefIdx() without Dispersion = %v, %v, want 1.5", clear.ChannelRefIdx(0), clear.ChannelRefIdx(2))
+	}
+}
+
+func TestDielectricDispersionThroughSphere(t *testing.T) {
+	// White light going through a flint glass ball, off center, exits bent toward the
+	// axis, blue more than green more than red.
+	ball := NewSphere(Vec3{0, 0, 0}, 1, Dielectric{RefIdx: 1.6, Dispersion: 0.02})
+	var exitY [3]float64
+	var counts [3]int
+	rng := RandForTests()
+	var rec HitRecord
+	for range 3000 {
+		r := NewRay(rng, Vec3{-5, 0.5, 0}, Vec3{1, 0, 0})
+		throughput := ColorF{1, 1, 1}
+		refractions, hits := 0, 0
+		for ball.Hit(r, FrontEpsilon, &rec) {
+			hits++
+			_, attenuation, scattered := rec.Mat.Scatter(r, &rec)
+			if Dot(scattered.Direction, rec.Normal) < 0 {
+				refractions++
+			}
+			throughput = Mul(throughput, attenuation)
+			r = &scattered
+		}
+		if hits != 2 || refractions != 2 {
+			continue // reflected
+		}
+		for c, v := range throughput.Components() {
+			if v > 0 {
+				if v != 3 {
+					t.Fatalf("throughput = %v, want 3 in a single channel", throughput)
+				}
+				exitY[c] += Unit(r.Direction).y
+				counts[c]++
+			}
+		}
+	}
+	for c := range 3 {
+		if counts[c] < 100 {
+			t.Fatalf("only %d rays went through in channel %d", counts[c], c)
+		}
+		exitY[c] /= float64(counts[c])
+	}
+	if !(exitY[0] < 0 && exitY[1] < exitY[0] && exitY[2] < exitY[1]) {
+		t.Errorf("exit direction y (red, green, blue) = %v, want negative and decreasing", exitY)
+	}
+}
+
+func TestDielectricDispersionNoise(t *testing.T) {
+	// The channel is picked once per path: going through the ball (or bouncing inside)
+	// keeps it, so the throughput is always 3 in one channel and the per channel second
+	// moment is 3 (it would be 9 if each hit picked again and dropped mismatched paths).
+	ball := NewSphere(Vec3{0, 0, 0}, 1, Dielectric{RefIdx: 1.6, Dispersion: 0.02})
+	rng := RandForTests()
+	var rec HitRecord
+	var mean, moment2 ColorF
+	const n = 3000
+	for range n {
+		r := NewRay(rng, Vec3{-5, 0.5, 0}, Vec3{1, 0, 0})
+		throughput := ColorF{1, 1, 1}
+		for ball.Hit(r, FrontEpsilon, &rec) {
+			_, attenuation, scattered := rec.Mat.Scatter(r, &rec)
+			throughput = Mul(throughput, attenuation)
+			r = &scattered
+		}
+		mean = Add(mean, throughput)
+		moment2 = Add(moment2, Mul(throughput, throughput))
+	}
+	mean = SMul(mean, 1.0/n)
+	moment2 = SMul(moment2, 1.0/n)
+	for c := range 3 {
+		if m := mean.Components()[c]; math.Abs(m-1) > 0.1 {
+			t.Errorf("channel %d mean throughput = %v, want ~1 (unbiased)", c, m)
+		}
+		if m2 := moment2.Components()[c]; math.Abs(m2-3) > 0.3 {
+			t.Errorf("channel %d throughput second moment = %v, want ~3 (single pick)", c, m2)
+		}
+	}
+}
+
+func TestDielectricAbsorption(t *testing.T) {
+	rnd := RandForTests()
+	glass := Dielectric{RefIdx: 1.5, Absorption: ColorF{0, 0.5, 1}}
+	// Ray that traveled 2 units inside (direction length 2 and T 1) and exits.
+	ray := NewRay(rnd, Vec3{0, 0, 0}, Vec3{0, 0, -2})
+	rec := &HitRecord{Point: Vec3{0, 0, -2}, Normal: Vec3{0, 0, 1}, T: 1}
+	_, attenuation, _ := glass.Scatter(ray, rec)
+	expected := ColorF{1, math.Exp(-1), math.Exp(-2)}
+	if !Equal(attenuation, expected, 1e-12) {
+		t.Errorf("back face attenuation = %v, want %v", attenuation, expected)
+	}
+	// Entering the glass: nothing absorbed yet.
+	rec.FrontFace = true
+	if _, attenuation, _ = glass.Scatter(ray, rec); attenuation != (ColorF{1, 1, 1}) {
+		t.Errorf("front face attenuation = %v, want white", attenuation)
+	}
+}
+
+func TestDielectricAbsorptionThickness(t *testing.T) {
+	// A thicker glass sphere should be more strongly tinted (red here).
+	transmitted := func(radius float64) ColorF {
+		rnd := RandForTests()
+		glass := Dielectric{RefIdx: 1.5, Absorption: ColorF{0, 0.5, 0.5}}
+		scene := &Scene{
+			Objects:    []Hittable{&Sphere{Center: Vec3{0, 0, -5}, Radius: radius, Mat: glass}},
+			Background: AmbientLight{ColorA: ColorF{1, 1, 1}, ColorB: ColorF{1, 1, 1}},
+		}
+		sum := ColorF{}
+		const n = 200
+		for range n {
+			sum = Add(sum, scene.RayColor(NewRay(rnd, Vec3{0, 0, 0}, Vec3{0, 0, -1}), 10))
+		}
+		return SDiv(sum, n)
+	}
+	thin, thick := transmitted(0.5), transmitted(2)
+	if thin.x < 0.99 || thick.x < 0.99 {
+		t.Errorf("red should go through: thin %v, thick %v", thin, thick)
+	}
+	if thick.y >= thin.y || thick.z >= thin.z {
+		t.Errorf("thick glass %v should be more tinted than thin glass %v", thick, thin)
+	}
+	// Straight through the center: exp(-0.5 * diameter) plus some reflections off the surface.
+	if want := math.Exp(-0.5 * 4); thick.y < want || thick.y > want+0.1 {
+		t.Errorf("thick glass green = %v, want about %v", thick.y, want)
+	}
+}
+
 func TestReflectance(t *testing.T) {
 	tests := []struct {
 		cosine float64
@@ -229,3 +485,167 @@ func TestReflectance(t *testing.T) {
 		}
 	}
 }
+
+func TestDiffuseLight(t *testing.T) {
+	rnd := RandForTests()
+	light := DiffuseLight{Emit: ColorF{4, 4, 4}}
+	ray := NewRay(rnd, Vec3{0, 0, 0}, Vec3{0, 0, -1})
+	rec := &HitRecord{
+		Point:  Vec3{0, 0, -1},
+		Normal: Vec3{0, 0, 1},
+	}
+
+	didScatter, _, scattered := light.Scatter(ray, rec)
+
+	if didScatter {
+		t.Error("Expected light not to scatter")
+	}
+	if scattered != (Ray{}) {
+		t.Errorf("Expected the zero scattered ray, got %v", scattered)
+	}
+	if light.Emitted() != light.Emit {
+		t.Errorf("Expected emitted %v, got %v", light.Emit, light.Emitted())
+	}
+}
+
+func TestNonLightMaterialsDontEmit(t *testing.T) {
+	for _, mat := range []Material{
+		Lambertian{Albedo: ColorF{1, 1, 1}},
+		Metal{Albedo: ColorF{1, 1, 1}},
+		Dielectric{RefIdx: 1.5},
+	} {
+		if e := mat.Emitted(); e != (ColorF{}) {
+			t.Errorf("%T.Emitted() = %v, want black", mat, e)
+		}
+	}
+}
+
+func TestScatterKeepsRandAndTime(t *testing.T) {
+	rnd := RandForTests()
+	ray := NewRay(rnd, Vec3{0, 2, 0}, Unit(Vec3{1, -1, 0}))
+	ray.Time = 0.7
+	rec := &HitRecord{Point: Vec3{1, 1, 0}, Normal: Vec3{0, 1, 0}, FrontFace: true, T: 1}
+	for _, mat := range []Material{
+		Lambertian{Albedo: ColorF{0.5, 0.5, 0.5}},
+		Metal{Albedo: ColorF{0.9, 0.9, 0.9}, Roughness: 0.3},
+		Dielectric{RefIdx: 1.5},
+	} {
+		didScatter, _, scattered := mat.Scatter(ray, rec)
+		if !didScatter || scattered.Rand != rnd || scattered.Time != ray.Time || scattered.Origin != rec.Point {
+			t.Errorf("%T Scatter() = %v, %+v; want the incoming ray's Rand and Time from %v", mat, didScatter, scattered, rec.Point)
+		}
+	}
+}
+
+func TestLambertianBookScatter(t *testing.T) {
+	rec := &HitRecord{Point: Vec3{1, 0, 0}, Normal: Vec3{0, 1, 0}}
+	for _, book := range []bool{false, true} {
+		lambertian := Lambertian{Albedo: ColorF{0.5, 0.5, 0.5}, BookScatter: book}
+		rnd := RandForTests()
+		ray := NewRay(rnd, Vec3{0, 1, 0}, Vec3{1, -1, 0})
+		sumCos := 0.0
+		const n = 20000
+		for range n {
+			_, _, scattered := lambertian.Scatter(ray, rec)
+			cos := Dot(Unit(scattered.Direction), rec.Normal)
+			if cos < 0 {
+				t.Fatalf("BookScatter %v: scattered %v below the surface", book, scattered.Direction)
+			}
+			sumCos += cos
+		}
+		// Both are cosine distributed.
+		if mean := sumCos / n; math.Abs(mean-2.0/3) > 0.01 {
+			t.Errorf("BookScatter %v: mean cosine = %v, want 2/3", book, mean)
+		}
+	}
+}
+
+func TestApplyNormalMap(t *testing.T) {
+	s := math.Sqrt(0.5)
+	tests := []struct {
+		name  string
+		color ColorF
+		want  Vec3
+	}{
+		{"flat", ColorF{0.5, 0.5, 1}, Vec3{0, 1, 0}},
+		{"toward tangent", ColorF{0.5 + 0.5*s, 0.5, 0.5 + 0.5*s}, Vec3{s, s, 0}},
+		{"toward bitangent", ColorF{0.5, 1, 0.5}, Vec3{0, 0, -1}}, // normal x tangent
+	}
+	for _, tt := range tests {
+		rec := &HitRecord{Normal: Vec3{0, 1, 0}, Tangent: Vec3{2, 0, 0}}
+		ApplyNormalMap(SolidColor{Albedo: tt.color}, rec)
+		if !Equal(rec.Normal, tt.want, 1e-9) {
+			t.Errorf("%s: ApplyNormalMap() normal = %v, want %v", tt.name, rec.Normal, tt.want)
+		}
+	}
+}
+
+func TestRender_NormalMap(t *testing.T) {
+	s := math.Sqrt(0.5)
+	render := func(normalMap Texture) (*Tracer, float64) {
+		floor := NewQuad(Vec3{-5, 0, 5}, Vec3{10, 0, 0}, Vec3{0, 0, -10}, // tangent is +x
+			Lambertian{Albedo: ColorF{1, 1, 1}, NormalMap: normalMap})
+		scene := &Scene{
+			Objects:    []Hittable{floor},
+			Background: NoBackground,
+			Lights:     []Light{DirectionalLight{Direction: Vec3{-1, -1, 0}, Color: ColorF{1, 1, 1}}},
+		}
+		rt := New(8, 8)
+		rt.Seed = 42
+		rt.MaxDepth = 1 // direct light only
+		rt.KeepHDR = true
+		rt.Camera = Camera{Position: Vec3{0, 3, 0}, LookAt: Vec3{0, 0, 0}, Up: Vec3{0, 0, -1}, VerticalFoV: 30}
+		rt.Render(scene)
+		mean := 0.0
+		for _, c := range rt.HDRBuffer() {
+			mean += c.x
+		}
+		return rt, mean / 64
+	}
+	plain, plainMean := render(nil)
+	flat, flatMean := render(SolidColor{Albedo: ColorF{0.5, 0.5, 1}})
+	if !slices.Equal(plain.HDRBuffer(), flat.HDRBuffer()) {
+		t.Errorf("flat normal map changed the image: mean %v vs %v", flatMean, plainMean)
+	}
+	if math.Abs(plainMean-s) > 1e-9 {
+		t.Errorf("mean without normal map = %v, want cos(45°) %v", plainMean, s)
+	}
+	// Tilting the normals toward the light makes the floor brighter, away from it darker.
+	if _, toward := render(SolidColor{Albedo: ColorF{0.5 + 0.5*s, 0.5, 0.5 + 0.5*s}}); math.Abs(toward-1) > 1e-9 {
+		t.Errorf("mean with normals tilted toward the light = %v, want 1", toward)
+	}
+	if _, away := render(SolidColor{Albedo: ColorF{0.5 - 0.5*s, 0.5, 0.5 + 0.5*s}}); away > 1e-9 {
+		t.Errorf("mean with normals tilted away from the light = %v, want 0", away)
+	}
+}
+
+func TestMixMaterialRatio(t *testing.T) {
+	a := Lambertian{Albedo: ColorF{1, 0, 0}}
+	b := Lambertian{Albedo: ColorF{0, 0, 1}}
+	rec := &HitRecord{Point: Vec3{0, 0, -1}, Normal: Vec3{0, 0, 1}}
+	for _, ratio := range []float64{0, 0.2, 0.5, 0.9, 1} {
+		mix := MixMaterial{A: a, B: b, Ratio: ratio}
+		r := NewRay(RandForTests(), Vec3{0, 0, 0}, Vec3{0, 0, -1})
+		const n = 10000
+		countB := 0
+		for range n {
+			scattered, attenuation, _ := mix.Scatter(r, rec)
+			if !scattered {
+				t.Fatalf("MixMaterial of Lambertians didn't scatter")
+			}
+			if attenuation == b.Albedo {
+				countB++
+			}
+		}
+		if got := float64(countB) / n; math.Abs(got-ratio) > 0.015 {
+			t.Errorf("Ratio %v: fraction of rays scattered by B = %v, want %v", ratio, got, ratio)
+		}
+	}
+}
+
+func TestMixMaterialEmitted(t *testing.T) {
+	mix := MixMaterial{A: DiffuseLight{Emit: ColorF{4, 4, 4}}, B: Lambertian{}, Ratio: 0.25}
+	if got, want := mix.Emitted(), (ColorF{3, 3, 3}); got != want {
+		t.Errorf("Emitted() = %v, want %v", got, want)
+	}
+}
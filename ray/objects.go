@@ -14,6 +14,11 @@ type HitRecord struct {
 	T         float64
 	Mat       Material
 	FrontFace bool
+	// U, V are the surface coordinates of the hit point (in [0,1]), for textures.
+	U, V float64
+	// Tangent is the direction of increasing U on the surface (not necessarily unit length),
+	// for normal mapping. Zero when the object doesn't provide it.
+	Tangent Vec3
 }
 
 func (hr *HitRecord) SetFaceNormal(r *Ray, outwardNormal Vec3) {
@@ -27,11 +32,38 @@ func (hr *HitRecord) SetFaceNormal(r *Ray, outwardNormal Vec3) {
 
 type Hittable interface {
 	Hit(r *Ray, interval Interval, hr *HitRecord) bool
+	// BoundingBox returns the axis-aligned box enclosing the object.
+	BoundingBox() AABB
 }
 
+// Background is the color (light) seen by rays that don't hit any object.
+type Background interface {
+	Hit(r *Ray) ColorF
+}
+
+// BackgroundFunc adapts a function of the (unit) ray direction to a Background, for
+// procedural skies: e.g. Scene{Background: BackgroundFunc(func(dir Vec3) ColorF { ... })}.
+type BackgroundFunc func(dir Vec3) ColorF
+
+func (f BackgroundFunc) Hit(r *Ray) ColorF {
+	return f(Unit(r.Direction))
+}
+
+// NoBackground is a pure black background, for scenes only lit by emissive objects.
+var NoBackground = AmbientLight{}
+
 type Scene struct {
-	Objects    []Hittable
-	Background AmbientLight
+	Objects []Hittable
+	// Background is the light coming from rays escaping the scene. When nil,
+	// Tracer.Render will use DefaultBackground(); set it to NoBackground for a black void.
+	Background Background
+	// Lights are sampled directly (with shadow rays) from diffuse surfaces.
+	Lights []Light
+	// Emitters are emissive objects, also part of Objects, toward which diffuse surfaces
+	// send half of their scattered rays (importance sampling). This greatly reduces the
+	// noise of scenes lit by small emitters, without changing the expected image
+	// (see Tracer.SamplingStrategy for the alternatives).
+	Emitters []Emitter
 }
 
 func (s *Scene) Hit(r *Ray, interval Interval, hr *HitRecord) (hitAnything bool) {
@@ -45,28 +77,178 @@ func (s *Scene) Hit(r *Ray, interval Interval, hr *HitRecord) (hitAnything bool)
 	return hitAnything
 }
 
+// HitObject returns the closest object hit by r (in front of its origin), along with the
+// hit details, for instance to select the object under a pixel (see Tracer.PrimaryRay).
+// For nested groups, the top level object (the group) is returned.
+func (s *Scene) HitObject(r *Ray) (Hittable, *HitRecord, bool) {
+	var hr HitRecord
+	var closest Hittable
+	closestSoFar := FrontEpsilon.End
+	for _, object := range s.Objects {
+		if object.Hit(r, Interval{Start: FrontEpsilon.Start, End: closestSoFar}, &hr) {
+			closest = object
+			closestSoFar = hr.T
+		}
+	}
+	if closest == nil {
+		return nil, nil, false
+	}
+	return closest, &hr, true
+}
+
+// BoundingBox returns the union of the bounding boxes of all the objects in the scene.
+func (s *Scene) BoundingBox() AABB {
+	box := EmptyAABB
+	for _, object := range s.Objects {
+		box = UnionAABB(box, object.BoundingBox())
+	}
+	return box
+}
+
+// FiniteBoundingBox is like BoundingBox but skips the unbounded objects (e.g. Plane), so
+// the result can be used to frame the scene (see Camera.Frame). It's EmptyAABB when all
+// the objects are unbounded.
+func (s *Scene) FiniteBoundingBox() AABB {
+	box := EmptyAABB
+	for _, object := range s.Objects {
+		if b := object.BoundingBox(); b.IsFinite() {
+			box = UnionAABB(box, b)
+		}
+	}
+	return box
+}
+
 // RayColor is the main function for computing the color of a ray (thus a pixel).
+// It follows the ray's bounces iteratively (up to depth of them), accumulating the
+// emitted light weighted by the product of the attenuations so far (throughput).
 func (s *Scene) RayColor(r *Ray, depth int) ColorF {
-	if depth <= 0 {
-		return ColorF{0, 0, 0}
-	}
-	hr := &HitRecord{}
-	if hit := s.Hit(r, FrontEpsilon, hr); hit {
-		if didScatter, attenuation, scattered := hr.Mat.Scatter(r, hr); didScatter {
-			return Mul(attenuation, s.RayColor(scattered, depth-1))
+	return s.RayColorRussianRoulette(r, depth, 0)
+}
+
+// RayColorRussianRoulette is RayColor with Russian roulette path termination once
+// minDepth bounces have been done (0 or less disables it): the path survives with a
+// probability equal to the throughput's largest channel (capped at 0.95) and the
+// survivors are boosted by the inverse of that probability, so the result stays
+// unbiased. Dim paths get cut early which saves time, at the cost of some extra noise.
+func (s *Scene) RayColorRussianRoulette(r *Ray, depth, minDepth int) ColorF {
+	return s.rayColor(r, depth, minDepth, FrontEpsilon.Start, 0, &pathScratch{})
+}
+
+// RayColorDebug is RayColor also returning the hit records along the path, in bounce
+// order (with the shading normal, after normal mapping), to diagnose a misbehaving
+// pixel (e.g. with Tracer.PrimaryRay): where each bounce hit, facing which way and on
+// which material. The path ends after the last one when the ray then missed everything,
+// wasn't scattered or depth was reached.
+func (s *Scene) RayColorDebug(r *Ray, depth int) (ColorF, []HitRecord) {
+	scratch := &pathScratch{recordPath: true}
+	c := s.rayColor(r, depth, 0, FrontEpsilon.Start, 0, scratch)
+	return c, scratch.path
+}
+
+// pathScratch holds the records needed while following a path. They escape to the heap
+// (through the Hittable and Material interfaces) so the Tracer reuses one per tile instead
+// of allocating new ones for every path.
+type pathScratch struct {
+	hr        HitRecord
+	shadowHr  HitRecord
+	shadowRay Ray
+	ray       Ray // current ray of the path (after the first bounce)
+	// Densities for Scene.Emitters sampling
+	cosinePDF   CosinePDF
+	emittersPDF EmittersPDF
+	// Counters for Tracer.Stats: paths followed, rays traced along them (including the
+	// primary ones) and how many hit something, and shadow rays.
+	paths, rays, hits, shadowRays int64
+	// Hits along the path, when recordPath is set (RayColorDebug).
+	recordPath bool
+	path       []HitRecord
+	// How diffuse surfaces sample their scattered rays (Tracer.SamplingStrategy).
+	sampling Sampling
+}
+
+// rayColor is RayColorRussianRoulette using the given scratch records, ignoring hits
+// closer than epsilon (along the ray) to avoid self intersections. When clampIndirect is
+// > 0 the luminance of the light gathered after the first bounce is capped to it.
+func (s *Scene) rayColor(r *Ray, depth, minDepth int, epsilon, clampIndirect float64, scratch *pathScratch) ColorF {
+	front := Interval{Start: epsilon, End: math.Inf(1)}
+	hr := &scratch.hr
+	color := ColorF{0, 0, 0}
+	direct := color // what reached the camera without bouncing (valid once bounce > 0)
+	throughput := ColorF{1, 1, 1}
+	scratch.paths++
+	bounce := 0
+	for ; bounce < depth; bounce++ {
+		scratch.rays++
+		if !s.Hit(r, front, hr) {
+			if s.Background != nil {
+				color = Add(color, Mul(throughput, s.Background.Hit(r)))
+			}
+			break
+		}
+		scratch.hits++
+		color = Add(color, Mul(throughput, hr.Mat.Emitted()))
+		if nm, ok := hr.Mat.(normalMapped); ok && nm.normalMap() != nil {
+			ApplyNormalMap(nm.normalMap(), hr)
 		}
-		return ColorF{0, 0, 0}
+		if scratch.recordPath {
+			scratch.path = append(scratch.path, *hr)
+		}
+		dm, diffuse := hr.Mat.(DiffuseMaterial)
+		if diffuse && len(s.Lights) > 0 {
+			color = Add(color, Mul(throughput, s.directLight(r, hr, dm.DiffuseAlbedo(hr), epsilon, scratch)))
+		}
+		if bounce == 0 {
+			// Whichever way the path ends, all the light gathered so far is direct.
+			direct = color
+		}
+		var didScatter bool
+		var attenuation ColorF
+		var scattered Ray
+		if diffuse && len(s.Emitters) > 0 && scratch.sampling != SamplingBRDF {
+			didScatter, attenuation, scattered = s.scatterTowardEmitters(r, hr, dm.DiffuseAlbedo(hr), scratch)
+		} else {
+			didScatter, attenuation, scattered = hr.Mat.Scatter(r, hr)
+		}
+		if !didScatter {
+			break
+		}
+		throughput = Mul(throughput, attenuation)
+		if minDepth > 0 && bounce+1 >= minDepth {
+			p := math.Min(0.95, math.Max(throughput.x, math.Max(throughput.y, throughput.z)))
+			if scattered.Float64() >= p {
+				break
+			}
+			throughput = SDiv(throughput, p)
+		}
+		scratch.ray = scattered
+		r = &scratch.ray
+	}
+	if clampIndirect <= 0 || bounce == 0 {
+		return color
 	}
-	// later we can allow not having a background (put back the nil check) but for now it's the only light source
-	return s.Background.Hit(r)
+	indirect := Sub(color, direct)
+	if l := Luminance(indirect); l > clampIndirect {
+		return Add(direct, SMul(indirect, clampIndirect/l))
+	}
+	return color
 }
 
 type AmbientLight struct {
 	ColorA, ColorB ColorF
+	// Optional sun disk: rays within SunRadius (angular radius, in degrees) of SunDirection
+	// get SunColor instead of the gradient. SunColor is emitted light so it's typically much
+	// brighter than 1 (e.g. 20 or more) to give sharp highlights and shadows. No sun when
+	// SunDirection is zero.
+	SunDirection Vec3
+	SunRadius    float64
+	SunColor     ColorF
 }
 
 func (al AmbientLight) Hit(r *Ray) ColorF {
 	unit := Unit(r.Direction)
+	if al.SunDirection != (Vec3{}) && Dot(unit, Unit(al.SunDirection)) >= math.Cos(al.SunRadius*math.Pi/180) {
+		return al.SunColor
+	}
 	a := 0.5 * (unit.Y() + 1.0)
 	blend := Add(SMul(al.ColorA, 1.0-a), SMul(al.ColorB, a))
 	return blend
@@ -76,13 +258,29 @@ type Sphere struct {
 	Center Vec3
 	Radius float64
 	Mat    Material
+	// Computed by NewSphere (zero for spheres made from a literal, which Hit then computes).
+	radiusSq  float64
+	invRadius float64
+}
+
+// NewSphere returns a sphere with its intersection constants precomputed, which makes
+// Hit a bit faster than for a Sphere literal. Radius shouldn't be changed afterwards.
+func NewSphere(center Vec3, radius float64, mat Material) *Sphere {
+	return &Sphere{Center: center, Radius: radius, Mat: mat, radiusSq: radius * radius, invRadius: 1 / radius}
 }
 
 func (s *Sphere) Hit(r *Ray, i Interval, hr *HitRecord) bool {
+	radiusSq, invRadius := s.radiusSq, s.invRadius
+	if invRadius == 0 {
+		radiusSq, invRadius = s.Radius*s.Radius, 1/s.Radius
+	}
 	oc := Sub(s.Center, r.Origin)
-	a := LengthSquared(r.Direction)
 	h := Dot(r.Direction, oc)
-	c := LengthSquared(oc) - s.Radius*s.Radius
+	c := LengthSquared(oc) - radiusSq
+	if c > 0 && h <= 0 && i.Start >= 0 {
+		return false // origin outside of the sphere and going away from it: both roots are behind
+	}
+	a := LengthSquared(r.Direction)
 	discriminant := h*h - a*c
 	if discriminant < 0 {
 		return false
@@ -95,12 +293,58 @@ func (s *Sphere) Hit(r *Ray, i Interval, hr *HitRecord) bool {
 			return false
 		}
 	}
+	s.record(r, root, invRadius, hr)
+	return true
+}
+
+// record fills hr for a hit of ray r at root, which is shared with SphereGroup.
+func (s *Sphere) record(r *Ray, root, invRadius float64, hr *HitRecord) {
 	hr.Point = r.At(root)
 	hr.T = root
-	outwardNormal := SDiv(Sub(hr.Point, s.Center), s.Radius)
+	outwardNormal := SMul(Sub(hr.Point, s.Center), invRadius)
 	hr.SetFaceNormal(r, outwardNormal)
+	hr.U, hr.V = SphereUV(outwardNormal)
+	hr.Tangent = Vec3{outwardNormal.z, 0, -outwardNormal.x} // derivative of the point by SphereUV's u
 	hr.Mat = s.Mat
-	return true
+}
+
+// MovingSphere is a sphere moving linearly from Center at time 0 to Center1 at time 1.
+// Combined with the Camera's shutter interval (within [0, 1]), it renders motion blurred.
+type MovingSphere struct {
+	Center  Vec3
+	Center1 Vec3
+	Radius  float64
+	Mat     Material
+}
+
+// CenterAt returns the center of the sphere at the given time (extrapolated outside of [0, 1]).
+func (ms *MovingSphere) CenterAt(time float64) Vec3 {
+	return AddScaled(ms.Center, Sub(ms.Center1, ms.Center), time)
+}
+
+func (ms *MovingSphere) Hit(r *Ray, i Interval, hr *HitRecord) bool {
+	s := Sphere{Center: ms.CenterAt(r.Time), Radius: ms.Radius, Mat: ms.Mat}
+	return s.Hit(r, i, hr)
+}
+
+// BoundingBox returns the box enclosing the sphere for times between 0 and 1.
+func (ms *MovingSphere) BoundingBox() AABB {
+	s0 := Sphere{Center: ms.Center, Radius: ms.Radius}
+	s1 := Sphere{Center: ms.Center1, Radius: ms.Radius}
+	return UnionAABB(s0.BoundingBox(), s1.BoundingBox())
+}
+
+// SphereUV returns the texture coordinates of point p on the unit sphere centered at the origin:
+// u is the angle around the Y axis (from X=-1), v the angle from Y=-1 to Y=+1, both mapped to [0,1].
+func SphereUV(p Vec3) (u, v float64) {
+	theta := math.Acos(-p.y)
+	phi := math.Atan2(-p.z, p.x) + math.Pi
+	return phi / (2 * math.Pi), theta / math.Pi
+}
+
+func (s *Sphere) BoundingBox() AABB {
+	rvec := Vec3{s.Radius, s.Radius, s.Radius}
+	return NewAABB(Sub(s.Center, rvec), Add(s.Center, rvec))
 }
 
 func DefaultBackground() AmbientLight {
@@ -113,63 +357,74 @@ func DefaultScene() *Scene {
 	ground := Lambertian{Albedo: ColorF{0.7, 0.8, 0.1}}
 	center := Lambertian{Albedo: ColorF{0.1, 0.2, 0.5}}
 	//		left := Metal{Albedo: ColorF{0.8, 0.8, 0.8}, Fuzz: 0}
-	left := Dielectric{1.5}
-	bubble := Dielectric{1.0 / 1.5}
+	left := Dielectric{RefIdx: 1.5}
+	bubble := Dielectric{RefIdx: 1.0 / 1.5}
 	right := Metal{Albedo: ColorF{1, .8, .8}, Fuzz: 0.05}
 	return &Scene{
 		// Default scene with two spheres.
 		Objects: []Hittable{
-			&Sphere{Center: Vec3{0, 0, -1.2}, Radius: 0.5, Mat: center},
-			&Sphere{Center: Vec3{0, -100.5, -1}, Radius: 100, Mat: ground},
-			&Sphere{Center: Vec3{-1.0, 0, -1}, Radius: 0.5, Mat: left},
-			&Sphere{Center: Vec3{-1.0, 0, -1}, Radius: 0.4, Mat: bubble},
-			&Sphere{Center: Vec3{1.0, 0, -1}, Radius: 0.5, Mat: right},
+			NewSphere(Vec3{0, 0, -1.2}, 0.5, center),
+			NewSphere(Vec3{0, -100.5, -1}, 100, ground),
+			NewSphere(Vec3{-1.0, 0, -1}, 0.5, left),
+			NewSphere(Vec3{-1.0, 0, -1}, 0.4, bubble),
+			NewSphere(Vec3{1.0, 0, -1}, 0.5, right),
 		},
 		Background: DefaultBackground(),
 	}
 }
 
+// RichScene is the final scene of "Ray Tracing in One Weekend": a grid of small random
+// spheres around 3 big ones. It's fully determined by rng's sequence, which is consumed
+// serially in a fixed order, matching the C++ reference: for each grid cell (a then b,
+// from -11 to 10), the material choice, then the center x and z offsets, then (only
+// for spheres far enough from the big metal one) the material's parameters: 6 draws for
+// diffuse (2 random colors multiplied), 4 for metal (albedo then fuzz), none for glass.
+// So for seed 7 there are 486 objects, like the C++ version.
 func RichScene(rng rand.Rand) *Scene {
 	ground := Lambertian{Albedo: ColorF{0.5, 0.5, 0.5}}
 	world := &Scene{}
-	world.Objects = append(world.Objects, &Sphere{Center: Vec3{0, -1000, 0}, Radius: 1000, Mat: ground})
+	world.Objects = append(world.Objects, NewSphere(Vec3{0, -1000, 0}, 1000, ground))
 
 	for a := -11; a < 11; a++ {
 		for b := -11; b < 11; b++ {
 			chooseMat := rng.Float64()
-			center := Vec3{float64(a) + 0.9*rng.Float64(), 0.2, float64(b) + 0.9*rng.Float64()}
+			// Explicit statements so the order of the draws doesn't rely on expression evaluation,
+			// and explicit float64() rounding so the offsets aren't fused (FMA) on some platforms,
+			// which could change which spheres are too close to the metal one.
+			dx := rng.Float64()
+			dz := rng.Float64()
+			center := Vec3{float64(a) + float64(0.9*dx), 0.2, float64(b) + float64(0.9*dz)}
 
 			if Length(center.Minus(XYZ(4, 0.2, 0))) > 0.9 {
 				var sphereMaterial Material
 				switch {
 				case chooseMat < 0.8:
 					// diffuse
-					albedo := Mul(Random(rng), Random(rng))
-					sphereMaterial = Lambertian{Albedo: albedo}
-					world.Objects = append(world.Objects, &Sphere{Center: center, Radius: 0.2, Mat: sphereMaterial})
+					c1 := Random(rng)
+					c2 := Random(rng)
+					sphereMaterial = Lambertian{Albedo: Mul(c1, c2)}
 				case chooseMat < 0.95:
 					// metal
 					albedo := RandomInRange(rng, Interval{0.5, 1.0})
 					fuzz := rng.Float64() * 0.5
 					sphereMaterial = Metal{Albedo: albedo, Fuzz: fuzz}
-					world.Objects = append(world.Objects, &Sphere{Center: center, Radius: 0.2, Mat: sphereMaterial})
 				default:
 					// glass
 					sphereMaterial = Dielectric{RefIdx: 1.5}
-					world.Objects = append(world.Objects, &Sphere{Center: center, Radius: 0.2, Mat: sphereMaterial})
 				}
+				world.Objects = append(world.Objects, NewSphere(center, 0.2, sphereMaterial))
 			}
 		}
 	}
 
 	material1 := Dielectric{RefIdx: 1.5}
-	world.Objects = append(world.Objects, &Sphere{Center: Vec3{0, 1, 0}, Radius: 1.0, Mat: material1})
+	world.Objects = append(world.Objects, NewSphere(Vec3{0, 1, 0}, 1.0, material1))
 
 	material2 := Lambertian{Albedo: ColorF{0.4, 0.2, 0.1}}
-	world.Objects = append(world.Objects, &Sphere{Center: Vec3{-4, 1, 0}, Radius: 1.0, Mat: material2})
+	world.Objects = append(world.Objects, NewSphere(Vec3{-4, 1, 0}, 1.0, material2))
 
 	material3 := Metal{Albedo: ColorF{0.7, 0.6, 0.5}, Fuzz: 0.0}
-	world.Objects = append(world.Objects, &Sphere{Center: Vec3{4, 1, 0}, Radius: 1.0, Mat: material3})
+	world.Objects = append(world.Objects, NewSphere(Vec3{4, 1, 0}, 1.0, material3))
 
 	return world
 }
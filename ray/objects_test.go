@@ -2,7 +2,10 @@ package ray
 
 import (
 	"math"
+	"reflect"
 	"testing"
+
+	"fortio.org/rand"
 )
 
 // Test helper to preserve the original return pattern (bool, *HitRecord).
@@ -107,7 +110,7 @@ func TestSphereHitNormal(t *testing.T) {
 	}
 	// Normal should point outward (in +X direction)
 	expectedNormal := Vec3{1, 0, 0}
-	if Length(Sub(rec.Normal, expectedNormal)) > 1e-10 {
+	if !Equal(rec.Normal, expectedNormal, 1e-10) {
 		t.Errorf("Expected normal %v, got %v", expectedNormal, rec.Normal)
 	}
 }
@@ -159,6 +162,66 @@ func TestSphereHitInterval(t *testing.T) {
 	}
 }
 
+func TestNewSphereSameAsLiteral(t *testing.T) {
+	rnd := RandForTests()
+	literal := &Sphere{Center: Vec3{0.5, 0, -3}, Radius: 0.7, Mat: Lambertian{}}
+	sphere := NewSphere(literal.Center, literal.Radius, literal.Mat)
+	for range 1000 {
+		ray := NewRay(rnd, Vec3{0, 0, 0}, RandomUnitVector(rnd))
+		for _, i := range []Interval{FrontEpsilon, Universe} {
+			hit1, rec1 := testHit(literal, ray, i)
+			hit2, rec2 := testHit(sphere, ray, i)
+			if hit1 != hit2 || hit1 && (math.Abs(rec1.T-rec2.T) > 1e-12 || !Equal(rec1.Normal, rec2.Normal, 1e-12)) {
+				t.Fatalf("NewSphere().Hit(%v, %v) = %v %+v, literal Sphere gives %v %+v", ray.Direction, i, hit2, rec2, hit1, rec1)
+			}
+		}
+	}
+}
+
+func TestSphereBehindRay(t *testing.T) {
+	rnd := RandForTests()
+	sphere := NewSphere(Vec3{0, 0, 5}, 1, Lambertian{})
+	ray := NewRay(rnd, Vec3{0, 0, 0}, Vec3{0, 0, -1})
+	if hit, _ := testHit(sphere, ray, FrontEpsilon); hit {
+		t.Error("Expected no hit for a sphere behind the ray")
+	}
+	// But found with an interval reaching backwards.
+	if hit, rec := testHit(sphere, ray, Universe); !hit || rec.T != -6 {
+		t.Errorf("Hit(Universe) = %v, t=%v; want hit at t=-6", hit, rec.T)
+	}
+}
+
+func BenchmarkSphereHit(b *testing.B) {
+	rnd := RandForTests()
+	sphere := NewSphere(Vec3{0, 0, -1}, 0.5, Lambertian{})
+	rays := make([]Ray, 256)
+	for i := range rays {
+		// Mix of hits and misses, as in a scene.
+		rays[i] = *NewRay(rnd, Vec3{0, 0, 0}, Vec3{rnd.Float64() - 0.5, rnd.Float64() - 0.5, -1})
+	}
+	var hr HitRecord
+	i := 0
+	for b.Loop() {
+		sphere.Hit(&rays[i&255], FrontEpsilon, &hr)
+		i++
+	}
+}
+
+func BenchmarkSphereHitLiteral(b *testing.B) {
+	rnd := RandForTests()
+	sphere := &Sphere{Center: Vec3{0, 0, -1}, Radius: 0.5, Mat: Lambertian{}}
+	rays := make([]Ray, 256)
+	for i := range rays {
+		rays[i] = *NewRay(rnd, Vec3{0, 0, 0}, Vec3{rnd.Float64() - 0.5, rnd.Float64() - 0.5, -1})
+	}
+	var hr HitRecord
+	i := 0
+	for b.Loop() {
+		sphere.Hit(&rays[i&255], FrontEpsilon, &hr)
+		i++
+	}
+}
+
 func TestSceneHitSingleObject(t *testing.T) {
 	rnd := RandForTests()
 	sphere := &Sphere{
@@ -393,3 +456,237 @@ func TestRayColorMaterialAbsorption(t *testing.T) {
 		}
 	}
 }
+
+func TestRayColorEmissive(t *testing.T) {
+	rnd := RandForTests()
+	emit := ColorF{2, 1, 0.5}
+	light := &Sphere{Center: Vec3{0, 0, -2}, Radius: 0.5, Mat: DiffuseLight{Emit: emit}}
+	scene := &Scene{Objects: []Hittable{light}}
+	ray := NewRay(rnd, Vec3{0, 0, 0}, Vec3{0, 0, -1})
+
+	if color := scene.RayColor(ray, 5); color != emit {
+		t.Errorf("Expected light color %v, got %v", emit, color)
+	}
+}
+
+func TestRayColorLitByEmissive(t *testing.T) {
+	// Dark scene (black background): a diffuse floor is only lit by a big light above it.
+	rnd := RandForTests()
+	floor := NewQuad(Vec3{-10, 0, -10}, Vec3{0, 0, 20}, Vec3{20, 0, 0}, Lambertian{Albedo: ColorF{0.8, 0.8, 0.8}})
+	light := NewQuad(Vec3{-10, 1, -10}, Vec3{20, 0, 0}, Vec3{0, 0, 20}, DiffuseLight{Emit: ColorF{1, 1, 1}})
+	scene := &Scene{Objects: []Hittable{floor, light}}
+	var sum ColorF
+	for range 100 {
+		ray := NewRay(rnd, Vec3{0, 0.5, 0}, Vec3{0, -1, 0})
+		sum = Add(sum, scene.RayColor(ray, 5))
+	}
+	if sum.x <= 0 || sum.y <= 0 || sum.z <= 0 {
+		t.Errorf("Expected floor to be lit by the light, got %v", sum)
+	}
+	// Without the light the floor is black.
+	scene.Objects = scene.Objects[:1]
+	ray := NewRay(rnd, Vec3{0, 0.5, 0}, Vec3{0, -1, 0})
+	if color := scene.RayColor(ray, 5); color != (ColorF{}) {
+		t.Errorf("Expected black without light, got %v", color)
+	}
+}
+
+func TestRayColorNoBackground(t *testing.T) {
+	rnd := RandForTests()
+	ray := NewRay(rnd, Vec3{0, 0, 0}, Vec3{0, 1, -1})
+	for _, scene := range []*Scene{
+		{Objects: []Hittable{}},
+		{Objects: []Hittable{}, Background: NoBackground},
+	} {
+		if color := scene.RayColor(ray, 5); color != (ColorF{}) {
+			t.Errorf("Expected black for background %v, got %v", scene.Background, color)
+		}
+	}
+}
+
+func TestMovingSphere(t *testing.T) {
+	rnd := RandForTests()
+	ms := &MovingSphere{Center: Vec3{0, 0, -2}, Center1: Vec3{0, 2, -2}, Radius: 0.5, Mat: Lambertian{}}
+	if c := ms.CenterAt(0.5); c != (Vec3{0, 1, -2}) {
+		t.Errorf("CenterAt(0.5) = %v, want {0, 1, -2}", c)
+	}
+	ray := NewRay(rnd, Vec3{0, 0, 0}, Vec3{0, 0, -1})
+	// At time 0 the sphere is in front of the ray, at time 1 it moved away.
+	if hit, rec := testHit(ms, ray, FrontEpsilon); !hit || math.Abs(rec.T-1.5) > 1e-10 {
+		t.Errorf("Expected hit at t=1.5 at time 0, got %v %v", hit, rec.T)
+	}
+	ray.Time = 1
+	if hit, _ := testHit(ms, ray, FrontEpsilon); hit {
+		t.Error("Expected no hit at time 1")
+	}
+	ray = NewRay(rnd, Vec3{0, 2, 0}, Vec3{0, 0, -1})
+	ray.Time = 1
+	if hit, _ := testHit(ms, ray, FrontEpsilon); !hit {
+		t.Error("Expected hit at new position at time 1")
+	}
+	expected := NewAABB(Vec3{-0.5, -0.5, -2.5}, Vec3{0.5, 2.5, -1.5})
+	if box := ms.BoundingBox(); box != expected {
+		t.Errorf("BoundingBox() = %v, want %v", box, expected)
+	}
+}
+
+func TestScatterPreservesTime(t *testing.T) {
+	rec := &HitRecord{Point: Vec3{0, 0, -1}, Normal: Vec3{0, 0, 1}, FrontFace: true}
+	for _, mat := range []Material{
+		Lambertian{Albedo: ColorF{1, 1, 1}},
+		Metal{Albedo: ColorF{1, 1, 1}},
+		Dielectric{RefIdx: 1.5},
+	} {
+		ray := NewRay(RandForTests(), Vec3{0, 0, 0}, Vec3{0, 0, -1})
+		ray.Time = 0.42
+		didScatter, _, scattered := mat.Scatter(ray, rec)
+		if !didScatter {
+			t.Fatalf("%T: expected scatter", mat)
+		}
+		if scattered.Time != ray.Time {
+			t.Errorf("%T: scattered time = %v, want %v", mat, scattered.Time, ray.Time)
+		}
+	}
+}
+
+func TestSceneHitObject(t *testing.T) {
+	near := &Sphere{Center: Vec3{0, 0, -2}, Radius: 0.5, Mat: Lambertian{}}
+	far := &Sphere{Center: Vec3{0, 0, -5}, Radius: 2, Mat: Metal{}}
+	side := &Sphere{Center: Vec3{4, 0, -5}, Radius: 1, Mat: Dielectric{RefIdx: 1.5}}
+	group := &Scene{Objects: []Hittable{side}}
+	scene := &Scene{Objects: []Hittable{far, near, group}}
+	rnd := RandForTests()
+	tests := []struct {
+		name     string
+		dir      Vec3
+		expected Hittable
+		t        float64
+	}{
+		{"closest of two", Vec3{0, 0, -1}, near, 1.5},
+		{"only the far one", Vec3{0, 1.5, -5}, far, 0},
+		{"group", Vec3{4, 0, -5}, group, 0},
+		{"miss", Vec3{0, 1, 0}, nil, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj, hr, ok := scene.HitObject(NewRay(rnd, Vec3{}, tt.dir))
+			if ok != (tt.expected != nil) || obj != tt.expected {
+				t.Fatalf("HitObject() = %v, %v; want %v", obj, ok, tt.expected)
+			}
+			if !ok {
+				if hr != nil {
+					t.Errorf("HitObject() record = %v, want nil on miss", hr)
+				}
+				return
+			}
+			if tt.t != 0 && math.Abs(hr.T-tt.t) > 1e-9 {
+				t.Errorf("HitObject() T = %v, want %v", hr.T, tt.t)
+			}
+		})
+	}
+}
+
+func TestRichSceneDeterministic(t *testing.T) {
+	scene := RichScene(rand.New(7))
+	if got := len(scene.Objects); got != 486 {
+		t.Errorf("len(RichScene(seed 7).Objects) = %d, want 486 (like the C++ version)", got)
+	}
+	if again := RichScene(rand.New(7)); !reflect.DeepEqual(scene, again) {
+		t.Errorf("RichScene(seed 7) differs between 2 calls")
+	}
+	// Last of the small spheres: checks the whole sequence of draws was consumed in order.
+	last := scene.Objects[len(scene.Objects)-4].(*Sphere)
+	want := Vec3{10.276910679552405, 0.2, 10.811403724560382}
+	if !Equal(last.Center, want, 1e-12) {
+		t.Errorf("last small sphere center = %v, want %v", last.Center, want)
+	}
+	if m, ok := last.Mat.(Metal); !ok || math.Abs(m.Fuzz-0.0250855872485809) > 1e-12 {
+		t.Errorf("last small sphere material = %#v, want Metal with fuzz 0.025", last.Mat)
+	}
+}
+
+func TestAmbientLightSun(t *testing.T) {
+	sky := DefaultBackground()
+	sun := ColorF{50, 45, 40}
+	sky.SunDirection, sky.SunRadius, sky.SunColor = Vec3{1, 1, 0}, 2, sun
+	tests := []struct {
+		name      string
+		direction Vec3
+		want      ColorF
+	}{
+		{"toward sun", Vec3{3, 3, 0}, sun},
+		{"within radius", Vec3{1, 1, 0.03}, sun},
+		{"outside radius", Vec3{1, 1, 0.1}, DefaultBackground().Hit(&Ray{Direction: Vec3{1, 1, 0.1}})},
+		{"opposite", Vec3{-1, -1, 0}, DefaultBackground().Hit(&Ray{Direction: Vec3{-1, -1, 0}})},
+	}
+	for _, tt := range tests {
+		if got := sky.Hit(&Ray{Direction: tt.direction}); got != tt.want {
+			t.Errorf("%s: Hit(%v) = %v, want %v", tt.name, tt.direction, got, tt.want)
+		}
+	}
+}
+
+func TestBackgroundFunc(t *testing.T) {
+	// Black sky with a bright horizon band.
+	var got []Vec3
+	haze := BackgroundFunc(func(dir Vec3) ColorF {
+		got = append(got, dir)
+		return SMul(ColorF{1, 0.9, 0.8}, 1-math.Abs(dir.Y()))
+	})
+	scene := &Scene{Background: haze}
+	if c := scene.RayColor(&Ray{Direction: Vec3{0, 0, -2}}, 1); c != (ColorF{1, 0.9, 0.8}) {
+		t.Errorf("RayColor() toward the horizon = %v, want the haze color", c)
+	}
+	if c := scene.RayColor(&Ray{Direction: Vec3{0, 3, 0}}, 1); c != (ColorF{}) {
+		t.Errorf("RayColor() straight up = %v, want black", c)
+	}
+	if want := []Vec3{{0, 0, -1}, {0, 1, 0}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("BackgroundFunc called with %v, want unit directions %v", got, want)
+	}
+	// Default background when not set.
+	tracer := New(2, 2)
+	empty := &Scene{}
+	tracer.Render(empty)
+	if empty.Background != DefaultBackground() {
+		t.Errorf("Background after Render() = %v, want DefaultBackground()", empty.Background)
+	}
+}
+
+func TestRayColorDebug(t *testing.T) {
+	// A mirror floor reflecting the ray up into a light.
+	mirror := Metal{Albedo: ColorF{0.5, 0.5, 0.5}}
+	light := DiffuseLight{Emit: ColorF{2, 4, 6}}
+	scene := &Scene{Objects: []Hittable{
+		NewQuad(Vec3{-5, 0, -5}, Vec3{0, 0, 10}, Vec3{10, 0, 0}, mirror),
+		NewSphere(Vec3{2, 2, 0}, 0.5, light),
+	}}
+	r := NewRay(RandForTests(), Vec3{-2, 2, 0}, Vec3{1, -1, 0})
+	c, path := scene.RayColorDebug(r, 10)
+	if want := (ColorF{1, 2, 3}); !Equal(c, want, 1e-12) {
+		t.Errorf("RayColorDebug() color = %v, want %v", c, want)
+	}
+	if rc := scene.RayColor(NewRay(RandForTests(), Vec3{-2, 2, 0}, Vec3{1, -1, 0}), 10); rc != c {
+		t.Errorf("RayColorDebug() color = %v, want RayColor's %v", c, rc)
+	}
+	if len(path) != 2 {
+		t.Fatalf("RayColorDebug() path has %d hits, want 2: %+v", len(path), path)
+	}
+	tests := []struct {
+		point, normal Vec3
+		mat           Material
+	}{
+		{Vec3{0, 0, 0}, Vec3{0, 1, 0}, mirror},
+		{Vec3{2 - 0.5*math.Sqrt(0.5), 2 - 0.5*math.Sqrt(0.5), 0}, Unit(Vec3{-1, -1, 0}), light},
+	}
+	for i, tt := range tests {
+		hr := path[i]
+		if !Equal(hr.Point, tt.point, 1e-9) || !Equal(hr.Normal, tt.normal, 1e-9) || hr.Mat != tt.mat || !hr.FrontFace {
+			t.Errorf("hit %d = %v normal %v on %T (front %v), want %v normal %v on %T",
+				i, hr.Point, hr.Normal, hr.Mat, hr.FrontFace, tt.point, tt.normal, tt.mat)
+		}
+	}
+	// Depth limits the path too.
+	if _, path := scene.RayColorDebug(NewRay(RandForTests(), Vec3{-2, 2, 0}, Vec3{1, -1, 0}), 1); len(path) != 1 {
+		t.Errorf("RayColorDebug() with depth 1 has %d hits, want 1", len(path))
+	}
+}
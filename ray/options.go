@@ -0,0 +1,51 @@
+package ray
+
+// Option configures a Tracer created by NewTracer.
+type Option func(t *Tracer)
+
+// NewTracer creates a Tracer like New, applies the options in order and then sets the
+// defaults of the fields left unset (as Render would), so they can be inspected right away.
+// Setting the fields directly afterwards keeps working.
+func NewTracer(width, height int, opts ...Option) *Tracer {
+	t := New(width, height)
+	for _, opt := range opts {
+		opt(t)
+	}
+	t.applyDefaults()
+	return t
+}
+
+// WithWorkers sets NumWorkers, the number of parallel workers (<= 0 means GOMAXPROCS).
+func WithWorkers(n int) Option {
+	return func(t *Tracer) {
+		t.NumWorkers = n
+	}
+}
+
+// WithRays sets NumRaysPerPixel (<= 0 means 1).
+func WithRays(n int) Option {
+	return func(t *Tracer) {
+		t.NumRaysPerPixel = n
+	}
+}
+
+// WithDepth sets MaxDepth, the maximum number of bounces (<= 0 means 10).
+func WithDepth(n int) Option {
+	return func(t *Tracer) {
+		t.MaxDepth = n
+	}
+}
+
+// WithCamera sets the Camera. Note that rendering a nil scene still uses DefaultSceneCamera.
+func WithCamera(c Camera) Option {
+	return func(t *Tracer) {
+		t.Camera = c
+	}
+}
+
+// WithSeed sets the Seed of the random number generators (0 means randomized each time).
+func WithSeed(s uint64) Option {
+	return func(t *Tracer) {
+		t.Seed = s
+	}
+}
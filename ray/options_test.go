@@ -0,0 +1,53 @@
+package ray
+
+import (
+	"runtime"
+	"slices"
+	"testing"
+)
+
+func TestNewTracer_Options(t *testing.T) {
+	cam := RichSceneCamera()
+	tracer := NewTracer(8, 6, WithWorkers(3), WithRays(4), WithDepth(7), WithCamera(*cam), WithSeed(42))
+	if tracer.width != 8 || tracer.height != 6 {
+		t.Errorf("size = %dx%d, want 8x6", tracer.width, tracer.height)
+	}
+	if tracer.NumWorkers != 3 || tracer.NumRaysPerPixel != 4 || tracer.MaxDepth != 7 || tracer.Seed != 42 {
+		t.Errorf("options not applied: workers %d, rays %d, depth %d, seed %d",
+			tracer.NumWorkers, tracer.NumRaysPerPixel, tracer.MaxDepth, tracer.Seed)
+	}
+	if tracer.Camera.Position != cam.Position || tracer.Camera.LookAt != cam.LookAt {
+		t.Errorf("camera = %v -> %v, want %v -> %v",
+			tracer.Camera.Position, tracer.Camera.LookAt, cam.Position, cam.LookAt)
+	}
+}
+
+func TestNewTracer_Defaults(t *testing.T) {
+	tracer := NewTracer(4, 4)
+	if tracer.NumWorkers != runtime.GOMAXPROCS(0) {
+		t.Errorf("NumWorkers = %d, want GOMAXPROCS %d", tracer.NumWorkers, runtime.GOMAXPROCS(0))
+	}
+	if tracer.NumRaysPerPixel != 1 || tracer.MaxDepth != 10 || tracer.TileSize != 16 ||
+		tracer.RayRadius != 0.5 || tracer.ShadowEpsilon != DefaultShadowEpsilon {
+		t.Errorf("defaults not applied: rays %d, depth %d, tile %d, radius %g, epsilon %g",
+			tracer.NumRaysPerPixel, tracer.MaxDepth, tracer.TileSize, tracer.RayRadius, tracer.ShadowEpsilon)
+	}
+	// Non positive option values also mean the defaults.
+	tracer = NewTracer(4, 4, WithWorkers(0), WithRays(-1))
+	if tracer.NumWorkers <= 0 || tracer.NumRaysPerPixel != 1 {
+		t.Errorf("NumWorkers = %d, NumRaysPerPixel = %d, want defaults", tracer.NumWorkers, tracer.NumRaysPerPixel)
+	}
+}
+
+func TestNewTracer_SameAsFields(t *testing.T) {
+	withOptions := NewTracer(16, 12, WithRays(2), WithDepth(4), WithSeed(7), WithCamera(*DefaultSceneCamera()))
+	expected := withOptions.Render(DefaultScene())
+	withFields := New(16, 12)
+	withFields.NumRaysPerPixel = 2
+	withFields.MaxDepth = 4
+	withFields.Seed = 7
+	withFields.Camera = *DefaultSceneCamera()
+	if img := withFields.Render(DefaultScene()); !slices.Equal(img.Pix, expected.Pix) {
+		t.Error("NewTracer with options renders differently than New with the same fields set")
+	}
+}
@@ -0,0 +1,169 @@
+package ray
+
+import (
+	"math"
+
+	"fortio.org/rand"
+)
+
+// PDF is a probability density over directions (per unit solid angle), used to importance
+// sample where scattered rays go.
+type PDF interface {
+	// Value returns the density of the given (not necessarily unit) direction.
+	Value(direction Vec3) float64
+	// Generate returns a random direction distributed according to the density.
+	Generate(rng rand.Rand) Vec3
+}
+
+// CosinePDF is the cosine weighted hemisphere around a normal, which matches the
+// scattering of Lambertian surfaces.
+type CosinePDF struct {
+	u, v, w Vec3 // orthonormal basis, w is the normal
+}
+
+// NewCosinePDF returns the cosine density around the unit vector normal.
+func NewCosinePDF(normal Vec3) CosinePDF {
+	u, v := orthonormalBasis(normal)
+	return CosinePDF{u: u, v: v, w: normal}
+}
+
+func (p CosinePDF) Value(direction Vec3) float64 {
+	return math.Max(0, Dot(Unit(direction), p.w)) / math.Pi
+}
+
+func (p CosinePDF) Generate(rng rand.Rand) Vec3 {
+	return cosineDirection(rng, p.u, p.v, p.w)
+}
+
+// cosineDirection returns a random unit vector with a cosine weighted distribution
+// around w, for the orthonormal basis (u, v, w): uniform in the unit disc, projected up.
+func cosineDirection(rng rand.Rand, u, v, w Vec3) Vec3 {
+	r1, r2 := rng.Float64(), rng.Float64()
+	phi := 2 * math.Pi * r1
+	sinPhi, cosPhi := math.Sincos(phi)
+	r := math.Sqrt(r2)
+	return AddMultiple(SMul(u, r*cosPhi), SMul(v, r*sinPhi), SMul(w, math.Sqrt(1-r2)))
+}
+
+// Emitter is an object (typically with a DiffuseLight material) that diffuse surfaces
+// can sample directly, see Scene.Emitters.
+type Emitter interface {
+	Hittable
+	// PDFValue returns the density of the directions from origin generated by Random,
+	// for the given direction: 0 when it misses the object.
+	PDFValue(origin, direction Vec3) float64
+	// Random returns a random direction from origin toward the object.
+	Random(rng rand.Rand, origin Vec3) Vec3
+}
+
+// EmittersPDF samples the directions toward a set of emitters from Origin, each emitter
+// being equally likely to be picked.
+type EmittersPDF struct {
+	Emitters []Emitter
+	Origin   Vec3
+}
+
+func (p EmittersPDF) Value(direction Vec3) float64 {
+	sum := 0.0
+	for _, e := range p.Emitters {
+		sum += e.PDFValue(p.Origin, direction)
+	}
+	return sum / float64(len(p.Emitters))
+}
+
+func (p EmittersPDF) Generate(rng rand.Rand) Vec3 {
+	return p.Emitters[rng.IntN(len(p.Emitters))].Random(rng, p.Origin)
+}
+
+// MixturePDF is the equal mix of A and B: half of the directions are generated from each
+// and the density is the average of both (one sample multiple importance sampling with
+// the balance heuristic).
+type MixturePDF struct {
+	A, B PDF
+}
+
+func (p MixturePDF) Value(direction Vec3) float64 {
+	return 0.5*p.A.Value(direction) + 0.5*p.B.Value(direction)
+}
+
+func (p MixturePDF) Generate(rng rand.Rand) Vec3 {
+	if rng.Float64() < 0.5 {
+		return p.A.Generate(rng)
+	}
+	return p.B.Generate(rng)
+}
+
+// PDFValue is the density of Random: the uniform density over the quad's area converted
+// to solid angle as seen from origin (distance^2 / (cosine * area)).
+func (q *Quad) PDFValue(origin, direction Vec3) float64 {
+	var hr HitRecord
+	if !q.Hit(&Ray{Origin: origin, Direction: direction}, FrontEpsilon, &hr) {
+		return 0
+	}
+	distanceSquared := hr.T * hr.T * LengthSquared(direction)
+	cosine := math.Abs(Dot(direction, q.normal)) / Length(direction)
+	return distanceSquared / (cosine * q.area)
+}
+
+// Random returns the direction from origin to a uniformly random point of the quad.
+func (q *Quad) Random(rng rand.Rand, origin Vec3) Vec3 {
+	p := AddMultiple(q.Q, SMul(q.U, rng.Float64()), SMul(q.V, rng.Float64()))
+	return Sub(p, origin)
+}
+
+// PDFValue is the density of Random: uniform over the cone of directions from origin
+// to the sphere (or over all directions when origin is inside the sphere).
+func (s *Sphere) PDFValue(origin, direction Vec3) float64 {
+	var hr HitRecord
+	if !s.Hit(&Ray{Origin: origin, Direction: direction}, FrontEpsilon, &hr) {
+		return 0
+	}
+	distanceSquared := LengthSquared(Sub(s.Center, origin))
+	if distanceSquared <= s.Radius*s.Radius {
+		return 1 / (4 * math.Pi)
+	}
+	cosThetaMax := math.Sqrt(1 - s.Radius*s.Radius/distanceSquared)
+	return 1 / (2 * math.Pi * (1 - cosThetaMax))
+}
+
+// Random returns a unit direction from origin uniformly distributed in the cone
+// subtended by the sphere.
+func (s *Sphere) Random(rng rand.Rand, origin Vec3) Vec3 {
+	toCenter := Sub(s.Center, origin)
+	distanceSquared := LengthSquared(toCenter)
+	if distanceSquared <= s.Radius*s.Radius {
+		return RandomUnitVector(rng)
+	}
+	w := SDiv(toCenter, math.Sqrt(distanceSquared))
+	u, v := orthonormalBasis(w)
+	cosThetaMax := math.Sqrt(1 - s.Radius*s.Radius/distanceSquared)
+	z := 1 + rng.Float64()*(cosThetaMax-1)
+	phi := 2 * math.Pi * rng.Float64()
+	sinPhi, cosPhi := math.Sincos(phi)
+	r := math.Sqrt(1 - z*z)
+	return AddMultiple(SMul(u, r*cosPhi), SMul(v, r*sinPhi), SMul(w, z))
+}
+
+// scatterTowardEmitters scatters the ray off the diffuse surface hit in hr (with the given
+// albedo) in a direction sampled from the mixture of the cosine distribution and the
+// Emitters (or only the Emitters for SamplingLight), weighted by the density it was
+// sampled from. Directions below the surface are absorbed.
+func (s *Scene) scatterTowardEmitters(r *Ray, hr *HitRecord, albedo ColorF, scratch *pathScratch) (bool, ColorF, Ray) {
+	scratch.emittersPDF = EmittersPDF{Emitters: s.Emitters, Origin: hr.Point}
+	var pdf PDF = &scratch.emittersPDF
+	if scratch.sampling != SamplingLight {
+		scratch.cosinePDF = NewCosinePDF(hr.Normal)
+		pdf = MixturePDF{A: &scratch.cosinePDF, B: &scratch.emittersPDF}
+	}
+	direction := pdf.Generate(r.Rand)
+	cosine := Dot(Unit(direction), hr.Normal)
+	if cosine <= 0 {
+		return false, ColorF{}, Ray{}
+	}
+	density := pdf.Value(direction)
+	if density <= 0 { // grazing the edge of an emitter
+		return false, ColorF{}, Ray{}
+	}
+	// Lambertian scattering density is cosine/pi.
+	return true, SMul(albedo, cosine/math.Pi/density), Ray{Rand: r.Rand, Origin: hr.Point, Direction: direction, Time: r.Time, Channel: r.Channel}
+}
@@ -0,0 +1,143 @@
+package ray
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCosinePDF(t *testing.T) {
+	rnd := RandForTests()
+	normal := Unit(Vec3{1, 2, -1})
+	pdf := NewCosinePDF(normal)
+	const n = 20000
+	sumCos := 0.0
+	for range n {
+		d := pdf.Generate(rnd)
+		cos := Dot(d, normal)
+		if math.Abs(Length(d)-1) > 1e-9 || cos < 0 {
+			t.Fatalf("Generate() = %v (length %v, cosine %v), want a unit vector above the surface", d, Length(d), cos)
+		}
+		if v := pdf.Value(SMul(d, 3)); math.Abs(v-cos/math.Pi) > 1e-9 {
+			t.Fatalf("Value(%v) = %v, want %v", d, v, cos/math.Pi)
+		}
+		sumCos += cos
+	}
+	// E[cos] = 2/3 for the cosine distribution (1/2 for uniform on the hemisphere).
+	if mean := sumCos / n; math.Abs(mean-2.0/3) > 0.01 {
+		t.Errorf("mean cosine = %v, want 2/3", mean)
+	}
+	if v := pdf.Value(Neg(normal)); v != 0 {
+		t.Errorf("Value() below the surface = %v, want 0", v)
+	}
+}
+
+// integrate estimates the integral of the pdf over all directions (which should be 1)
+// by uniformly sampling the sphere of directions.
+func integrate(pdf func(Vec3) float64) float64 {
+	rnd := RandForTests()
+	const n = 200000
+	sum := 0.0
+	for range n {
+		sum += pdf(RandomUnitVector(rnd))
+	}
+	return 4 * math.Pi * sum / n
+}
+
+func TestPDFsIntegrateToOne(t *testing.T) {
+	origin := Vec3{0.2, -0.1, 0.3}
+	quad := NewQuad(Vec3{-1, -1, -1}, Vec3{2, 0, 0}, Vec3{0, 2, 0}, DiffuseLight{})
+	sphere := NewSphere(Vec3{0, 0, 2}, 1, DiffuseLight{})
+	emitters := EmittersPDF{Emitters: []Emitter{quad, sphere}, Origin: origin}
+	cosine := NewCosinePDF(Vec3{0, 0, -1})
+	tests := []struct {
+		name string
+		pdf  func(Vec3) float64
+	}{
+		{"cosine", cosine.Value},
+		{"quad", func(d Vec3) float64 { return quad.PDFValue(origin, d) }},
+		{"sphere", func(d Vec3) float64 { return sphere.PDFValue(origin, d) }},
+		{"inside sphere", func(d Vec3) float64 { return sphere.PDFValue(Vec3{0, 0.5, 2}, d) }},
+		{"emitters", emitters.Value},
+		{"mixture", MixturePDF{A: cosine, B: emitters}.Value},
+	}
+	for _, tt := range tests {
+		if got := integrate(tt.pdf); math.Abs(got-1) > 0.03 {
+			t.Errorf("%s: integral of the pdf = %v, want 1", tt.name, got)
+		}
+	}
+}
+
+func TestEmitterRandom(t *testing.T) {
+	rnd := RandForTests()
+	origin := Vec3{0.2, -0.1, 0.3}
+	quad := NewQuad(Vec3{-1, -1, -1}, Vec3{2, 0, 0}, Vec3{0, 2, 0}, DiffuseLight{})
+	sphere := NewSphere(Vec3{0, 0, 2}, 1, DiffuseLight{})
+	for _, e := range []Emitter{quad, sphere} {
+		solidAngle := 0.0
+		const n = 10000
+		for range n {
+			d := e.Random(rnd, origin)
+			pdf := e.PDFValue(origin, d)
+			if pdf <= 0 {
+				t.Fatalf("%T: Random() = %v misses the object", e, d)
+			}
+			solidAngle += 1 / pdf
+		}
+		// Importance sampling estimate of the solid angle vs the uniform one.
+		expected := integrate(func(d Vec3) float64 {
+			if e.PDFValue(origin, d) > 0 {
+				return 1
+			}
+			return 0
+		})
+		if got := solidAngle / n; math.Abs(got-expected)/expected > 0.03 {
+			t.Errorf("%T: solid angle from Random() = %v, want %v", e, got, expected)
+		}
+	}
+}
+
+// smallLightScene is a floor lit only by a small emissive quad above it.
+func smallLightScene(withEmitters bool) *Scene {
+	light := NewQuad(Vec3{-0.25, 2, -1.25}, Vec3{0.5, 0, 0}, Vec3{0, 0, 0.5}, DiffuseLight{Emit: ColorF{15, 15, 15}})
+	scene := &Scene{
+		Objects: []Hittable{
+			NewQuad(Vec3{-2, 0, 1}, Vec3{4, 0, 0}, Vec3{0, 0, -4}, Lambertian{Albedo: ColorF{0.7, 0.7, 0.7}}),
+			light,
+		},
+		Background: NoBackground,
+	}
+	if withEmitters {
+		scene.Emitters = []Emitter{light}
+	}
+	return scene
+}
+
+func TestRender_Emitters(t *testing.T) {
+	render := func(withEmitters bool, seed uint64, rays int) []ColorF {
+		rt := New(24, 16)
+		rt.Seed = seed
+		rt.NumRaysPerPixel = rays
+		rt.KeepHDR = true
+		rt.Camera = Camera{Position: Vec3{0, 1, 2}, LookAt: Vec3{0, 0, -1}, VerticalFoV: 50}
+		rt.Render(smallLightScene(withEmitters))
+		return rt.HDRBuffer()
+	}
+	// Noise is the average difference between 2 seeds.
+	stats := func(withEmitters bool, rays int) (mean, noise float64) {
+		a, b := render(withEmitters, 1, rays), render(withEmitters, 2, rays)
+		for i := range a {
+			mean += Luminance(a[i]) + Luminance(b[i])
+			noise += math.Abs(Luminance(a[i]) - Luminance(b[i]))
+		}
+		return mean / float64(2*len(a)), noise / float64(len(a))
+	}
+	_, noise := stats(false, 8)
+	meanE, noiseE := stats(true, 8)
+	mean, _ := stats(false, 128) // reference
+	if noiseE > noise/2 {
+		t.Errorf("noise with Emitters = %v, want less than half of %v", noiseE, noise)
+	}
+	if math.Abs(meanE-mean) > 0.05*mean {
+		t.Errorf("mean with Emitters = %v, want about the same as %v", meanE, mean)
+	}
+}
@@ -0,0 +1,138 @@
+package ray
+
+import (
+	"math"
+
+	"fortio.org/rand"
+)
+
+const perlinPointCount = 256
+
+// Perlin is a (gradient) Perlin noise generator.
+// See https://raytracing.github.io/books/RayTracingTheNextWeek.html#perlinnoise
+type Perlin struct {
+	randVec             [perlinPointCount]Vec3
+	permX, permY, permZ [perlinPointCount]int
+}
+
+// NewPerlin creates a Perlin noise generator using rng for its random gradients
+// and permutations, so the same seed always gives the same noise.
+func NewPerlin(rng rand.Rand) *Perlin {
+	p := &Perlin{}
+	for i := range p.randVec {
+		p.randVec[i] = RandomUnitVector(rng)
+	}
+	perlinGeneratePerm(rng, &p.permX)
+	perlinGeneratePerm(rng, &p.permY)
+	perlinGeneratePerm(rng, &p.permZ)
+	return p
+}
+
+// perlinGeneratePerm fills perm with a random permutation of 0..perlinPointCount-1.
+func perlinGeneratePerm(rng rand.Rand, perm *[perlinPointCount]int) {
+	for i := range perm {
+		perm[i] = i
+	}
+	for i := len(perm) - 1; i > 0; i-- {
+		target := rng.IntN(i + 1)
+		perm[i], perm[target] = perm[target], perm[i]
+	}
+}
+
+// Noise returns the noise value at point p, in about [-1,1].
+func (pn *Perlin) Noise(p Vec3) float64 {
+	u := p.x - math.Floor(p.x)
+	v := p.y - math.Floor(p.y)
+	w := p.z - math.Floor(p.z)
+	i := int(math.Floor(p.x))
+	j := int(math.Floor(p.y))
+	k := int(math.Floor(p.z))
+	var c [2][2][2]Vec3
+	for di := range 2 {
+		for dj := range 2 {
+			for dk := range 2 {
+				c[di][dj][dk] = pn.randVec[pn.permX[(i+di)&(perlinPointCount-1)]^
+					pn.permY[(j+dj)&(perlinPointCount-1)]^
+					pn.permZ[(k+dk)&(perlinPointCount-1)]]
+			}
+		}
+	}
+	return perlinInterp(&c, u, v, w)
+}
+
+// perlinInterp does the trilinear interpolation of the gradients, with Hermite smoothing.
+func perlinInterp(c *[2][2][2]Vec3, u, v, w float64) float64 {
+	uu := u * u * (3 - 2*u)
+	vv := v * v * (3 - 2*v)
+	ww := w * w * (3 - 2*w)
+	accum := 0.0
+	for i := range 2 {
+		fi := float64(i)
+		for j := range 2 {
+			fj := float64(j)
+			for k := range 2 {
+				fk := float64(k)
+				weight := Vec3{u - fi, v - fj, w - fk}
+				accum += (fi*uu + (1-fi)*(1-uu)) *
+					(fj*vv + (1-fj)*(1-vv)) *
+					(fk*ww + (1-fk)*(1-ww)) *
+					Dot(c[i][j][k], weight)
+			}
+		}
+	}
+	return accum
+}
+
+// Turbulence returns the (absolute value of the) sum of depth octaves of noise,
+// each at twice the frequency and half the amplitude of the previous one.
+func (pn *Perlin) Turbulence(p Vec3, depth int) float64 {
+	accum := 0.0
+	weight := 1.0
+	for range depth {
+		accum += weight * pn.Noise(p)
+		weight *= 0.5
+		p = SMul(p, 2)
+	}
+	return math.Abs(accum)
+}
+
+// NoiseTexture is a procedural gray Texture based on Perlin turbulence.
+// Use NewNoiseTexture (or NewNoiseTextureSeed) to create one.
+type NoiseTexture struct {
+	Noise *Perlin
+	// Seed Noise was generated from, when known (non zero), so SaveScene can save the texture.
+	Seed uint64
+	// Scale is the frequency of the pattern: higher values give finer details.
+	Scale float64
+	// Depth is the number of turbulence octaves. If zero, defaults to 7.
+	Depth int
+	// Marble uses the turbulence to phase shift a sine along z (marble like veins)
+	// instead of using it directly (cloudy/smoky look).
+	Marble bool
+}
+
+// NewNoiseTexture creates a (cloudy) NoiseTexture with the given scale and turbulence depth,
+// using rng to pick the seed of the noise (see NewNoiseTextureSeed).
+func NewNoiseTexture(rng rand.Rand, scale float64, depth int) *NoiseTexture {
+	return NewNoiseTextureSeed(max(rng.Uint64(), 1), scale, depth)
+}
+
+// NewNoiseTextureSeed creates a (cloudy) NoiseTexture with the given scale and turbulence
+// depth, with the noise generated from seed (which shouldn't be 0, as that picks a random one).
+func NewNoiseTextureSeed(seed uint64, scale float64, depth int) *NoiseTexture {
+	return &NoiseTexture{Noise: NewPerlin(rand.New(seed)), Seed: seed, Scale: scale, Depth: depth}
+}
+
+func (nt *NoiseTexture) Value(_, _ float64, p Vec3) ColorF {
+	depth := nt.Depth
+	if depth <= 0 {
+		depth = 7
+	}
+	var gray float64
+	if nt.Marble {
+		gray = 0.5 * (1 + math.Sin(nt.Scale*p.z+10*nt.Noise.Turbulence(p, depth)))
+	} else {
+		gray = nt.Noise.Turbulence(SMul(p, nt.Scale), depth)
+	}
+	return ColorF{gray, gray, gray}
+}
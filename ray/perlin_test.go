@@ -0,0 +1,89 @@
+package ray
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"fortio.org/rand"
+)
+
+func TestPerlinDeterministic(t *testing.T) {
+	p1 := NewPerlin(rand.New(42))
+	p2 := NewPerlin(rand.New(42))
+	p3 := NewPerlin(rand.New(43))
+	different := false
+	for _, pt := range []Vec3{{0.1, 0.2, 0.3}, {1.5, -2.25, 3.75}, {-10.3, 4.2, 0.01}} {
+		n1, n2 := p1.Noise(pt), p2.Noise(pt)
+		if n1 != n2 {
+			t.Errorf("Noise(%v) differs for the same seed: %v vs %v", pt, n1, n2)
+		}
+		if p3.Noise(pt) != n1 {
+			different = true
+		}
+	}
+	if !different {
+		t.Error("Expected a different seed to give different noise")
+	}
+}
+
+func TestPerlinKnownValues(t *testing.T) {
+	p := NewPerlin(rand.New(42))
+	tests := []struct {
+		pt       Vec3
+		expected string
+	}{
+		{Vec3{0, 0, 0}, "0.000000"},
+		{Vec3{0.5, 0.5, 0.5}, "0.228784"},
+		{Vec3{1.25, -0.75, 2.5}, "0.058151"},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprintf("%.6f", p.Noise(tt.pt)); got != tt.expected {
+			t.Errorf("Noise(%v) = %s, want %s", tt.pt, got, tt.expected)
+		}
+	}
+	const expectedTurb = "0.390833"
+	if got := fmt.Sprintf("%.6f", p.Turbulence(Vec3{0.3, 0.6, 0.9}, 7)); got != expectedTurb {
+		t.Errorf("Turbulence() = %s, want %s", got, expectedTurb)
+	}
+}
+
+func TestPerlinRangeAndContinuity(t *testing.T) {
+	p := NewPerlin(RandForTests())
+	rnd := RandForTests()
+	for range 1000 {
+		pt := RandomInRange(rnd, Interval{Start: -50, End: 50})
+		n := p.Noise(pt)
+		if n < -1.5 || n > 1.5 {
+			t.Fatalf("Noise(%v) = %v out of expected range", pt, n)
+		}
+		// Small moves give small changes.
+		if d := math.Abs(p.Noise(Add(pt, Vec3{1e-6, 1e-6, 1e-6})) - n); d > 1e-4 {
+			t.Fatalf("Noise not continuous at %v: delta %v", pt, d)
+		}
+	}
+}
+
+func TestNoiseTexture(t *testing.T) {
+	tex := NewNoiseTexture(RandForTests(), 4, 0)
+	marble := &NoiseTexture{Noise: tex.Noise, Scale: 4, Marble: true}
+	rnd := RandForTests()
+	for range 100 {
+		pt := RandomInRange(rnd, Interval{Start: -5, End: 5})
+		for _, nt := range []*NoiseTexture{tex, marble} {
+			c := nt.Value(0, 0, pt)
+			if c.x != c.y || c.y != c.z {
+				t.Fatalf("Expected gray, got %v", c)
+			}
+			if c.x < 0 || (nt.Marble && c.x > 1) {
+				t.Fatalf("Value(%v) = %v out of range (marble %v)", pt, c, nt.Marble)
+			}
+		}
+	}
+	// Default depth is 7.
+	pt := Vec3{0.3, 0.7, 1.1}
+	expected := tex.Noise.Turbulence(SMul(pt, 4), 7)
+	if c := tex.Value(0, 0, pt); c.x != expected {
+		t.Errorf("Value(%v) = %v, want %v", pt, c.x, expected)
+	}
+}
@@ -0,0 +1,72 @@
+package ray
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// Progress is a snapshot of a render, see Tracer.Progress.
+type Progress struct {
+	Done    int64         // Pixels rendered so far (pixel passes for RenderProgressive)
+	Total   int64         // Pixels to render; 0 when unknown (RenderWithin)
+	Elapsed time.Duration // Time since the render started
+	ETA     time.Duration // Estimated time left at the average rate so far; 0 when unknown
+}
+
+// Fraction returns the completed fraction of the render, in [0, 1] (0 when unknown).
+func (p Progress) Fraction() float64 {
+	if p.Total <= 0 {
+		return 0
+	}
+	return float64(p.Done) / float64(p.Total)
+}
+
+func (p Progress) String() string {
+	if p.Total <= 0 {
+		return fmt.Sprintf("%d pixels in %v", p.Done, p.Elapsed.Round(time.Millisecond))
+	}
+	return fmt.Sprintf("%d/%d pixels (%.1f%%) in %v, ETA %v", p.Done, p.Total, 100*p.Fraction(),
+		p.Elapsed.Round(time.Millisecond), p.ETA.Round(time.Second))
+}
+
+// progressCounter is where the workers count the pixels they complete. The Tracer
+// points to it so the copy made for supersampling updates the same one.
+type progressCounter struct {
+	done, total atomic.Int64
+	start       atomic.Int64 // Unix nanoseconds
+}
+
+// Progress returns how far along the current (or last) render is. It's safe to call from
+// any goroutine while rendering, e.g. to drive a progress bar or a status page, unlike
+// ProgressFunc which gets the per line increments from all the workers, in whatever order
+// they complete. The ETA uses the average rate since the start, which smooths out the
+// differences between cheap (e.g. sky) and expensive lines.
+func (t *Tracer) Progress() Progress {
+	if t.progress == nil {
+		return Progress{}
+	}
+	p := Progress{Done: t.progress.done.Load(), Total: t.progress.total.Load()}
+	if start := t.progress.start.Load(); start != 0 {
+		p.Elapsed = time.Since(time.Unix(0, start))
+	}
+	if p.Total > 0 && p.Done > 0 && p.Done < p.Total {
+		p.ETA = time.Duration(float64(p.Elapsed) * float64(p.Total-p.Done) / float64(p.Done))
+	}
+	return p
+}
+
+// startProgress resets the progress for a new render of total pixels (0 if unknown).
+func (t *Tracer) startProgress(total int) {
+	if t.progress == nil {
+		t.progress = &progressCounter{}
+	}
+	t.progress.done.Store(0)
+	t.progress.total.Store(int64(total))
+	t.progress.start.Store(time.Now().UnixNano())
+}
+
+// addProgress counts n more rendered pixels.
+func (t *Tracer) addProgress(n int) {
+	t.progress.done.Add(int64(n))
+}
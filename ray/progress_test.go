@@ -0,0 +1,74 @@
+package ray
+
+import (
+	"image"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestProgress(t *testing.T) {
+	const w, h = 30, 20
+	rt := New(w, h)
+	if p := rt.Progress(); p.Done != 0 || p.Total != 0 || p.Fraction() != 0 {
+		t.Errorf("Progress() before rendering = %+v, want zero", p)
+	}
+	rt.NumWorkers = 4
+	rt.TileSize = 8
+	var mu sync.Mutex
+	var snapshots []Progress
+	rt.ProgressFunc = func(_ int) {
+		p := rt.Progress()
+		mu.Lock()
+		snapshots = append(snapshots, p)
+		mu.Unlock()
+	}
+	rt.Render(nil)
+	for _, p := range snapshots {
+		if p.Total != w*h || p.Done <= 0 || p.Done > p.Total || p.ETA < 0 {
+			t.Errorf("Progress() while rendering = %+v, want 0 < Done <= Total %d", p, w*h)
+		}
+	}
+	p := rt.Progress()
+	if p.Done != w*h || p.Total != w*h || p.Fraction() != 1 || p.ETA != 0 || p.Elapsed <= 0 {
+		t.Errorf("Progress() after Render = %+v, want all %d pixels done", p, w*h)
+	}
+
+	rt.ProgressFunc = nil
+	rt.SuperSample = 2
+	rt.Render(nil)
+	if p := rt.Progress(); p.Done != 4*w*h || p.Total != 4*w*h {
+		t.Errorf("Progress() after a supersampled Render = %+v, want %d pixels (RenderSize)", p, 4*w*h)
+	}
+	rt.SuperSample = 0
+	rt.RenderRegion(nil, image.Rect(5, 5, 15, 10))
+	if p := rt.Progress(); p.Done != 50 || p.Total != 50 {
+		t.Errorf("Progress() after RenderRegion = %+v, want 50 pixels", p)
+	}
+	rt.NumRaysPerPixel = 3
+	rt.RenderProgressive(nil, func(_ *image.RGBA, pass int) bool {
+		if p := rt.Progress(); p.Done != int64(pass*w*h) || p.Total != 3*w*h {
+			t.Errorf("Progress() after pass %d = %+v, want %d/%d", pass, p, pass*w*h, 3*w*h)
+		}
+		return true
+	})
+	_, passes := rt.RenderWithin(nil, 10*time.Millisecond)
+	if p := rt.Progress(); p.Done != int64(passes*w*h) || p.Total != 0 || p.ETA != 0 || p.Fraction() != 0 {
+		t.Errorf("Progress() after RenderWithin = %+v, want %d pixel passes of unknown total", p, passes*w*h)
+	}
+}
+
+func TestProgressString(t *testing.T) {
+	tests := []struct {
+		p        Progress
+		expected string
+	}{
+		{Progress{Done: 25, Total: 100, Elapsed: 1500 * time.Millisecond, ETA: 4500 * time.Millisecond}, "25/100 pixels (25.0%) in 1.5s, ETA 5s"},
+		{Progress{Done: 42, Elapsed: time.Second}, "42 pixels in 1s"},
+	}
+	for _, tt := range tests {
+		if s := tt.p.String(); s != tt.expected {
+			t.Errorf("String() = %q, want %q", s, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package ray
+
+import "math"
+
+// Quad is a planar parallelogram with corner Q and edges U and V.
+// Use NewQuad to create one, as it needs precomputed values.
+type Quad struct {
+	Q, U, V Vec3
+	Mat     Material
+	// OneSided quads can only be hit from the front (where U x V points): rays reaching
+	// the back face go through, e.g. for a ceiling light that shouldn't light up the roof.
+	OneSided bool
+	// Computed fields (initialized by NewQuad)
+	normal Vec3
+	d      float64 // plane equation: Dot(normal, p) = d
+	w      Vec3    // used to find the planar (alpha, beta) coordinates of a hit
+	area   float64
+	bbox   AABB
+}
+
+// quadPadding is the minimum thickness of the (otherwise flat for axis aligned quads) bounding boxes.
+const quadPadding = 1e-4
+
+// NewQuad creates a Quad with corner q and edges u and v.
+// The front face is the one where u x v points.
+func NewQuad(q, u, v Vec3, mat Material) *Quad {
+	n := Cross(u, v)
+	normal := Unit(n)
+	return &Quad{
+		Q:      q,
+		U:      u,
+		V:      v,
+		Mat:    mat,
+		normal: normal,
+		d:      Dot(normal, q),
+		w:      SDiv(n, Dot(n, n)),
+		area:   Length(n),
+		bbox:   UnionAABB(NewAABB(q, q.Plus(u, v)), NewAABB(q.Plus(u), q.Plus(v))).Pad(quadPadding),
+	}
+}
+
+func (q *Quad) Hit(r *Ray, i Interval, hr *HitRecord) bool {
+	denom := Dot(q.normal, r.Direction)
+	// No hit if the ray is parallel to the plane.
+	if math.Abs(denom) < 1e-8 || (q.OneSided && denom > 0) {
+		return false
+	}
+	t := (q.d - Dot(q.normal, r.Origin)) / denom
+	if !i.Surrounds(t) {
+		return false
+	}
+	// Check the hit point is within the parallelogram using its planar coordinates.
+	intersection := r.At(t)
+	planarHit := Sub(intersection, q.Q)
+	alpha := Dot(q.w, Cross(planarHit, q.V))
+	beta := Dot(q.w, Cross(q.U, planarHit))
+	if !ZeroOne.Contains(alpha) || !ZeroOne.Contains(beta) {
+		return false
+	}
+	hr.Point = intersection
+	hr.T = t
+	hr.U, hr.V = alpha, beta
+	hr.Tangent = q.U
+	hr.SetFaceNormal(r, q.normal)
+	hr.Mat = q.Mat
+	return true
+}
+
+func (q *Quad) BoundingBox() AABB {
+	return q.bbox
+}
+
+// NewBox returns the axis-aligned box (six quads) with opposite corners a and b.
+func NewBox(a, b Vec3, mat Material) Hittable {
+	minP := Min(a, b)
+	maxP := Max(a, b)
+	dx := Vec3{maxP.x - minP.x, 0, 0}
+	dy := Vec3{0, maxP.y - minP.y, 0}
+	dz := Vec3{0, 0, maxP.z - minP.z}
+	return &Scene{
+		Objects: []Hittable{
+			NewQuad(Vec3{minP.x, minP.y, maxP.z}, dx, dy, mat),      // front
+			NewQuad(Vec3{maxP.x, minP.y, maxP.z}, Neg(dz), dy, mat), // right
+			NewQuad(Vec3{maxP.x, minP.y, minP.z}, Neg(dx), dy, mat), // back
+			NewQuad(Vec3{minP.x, minP.y, minP.z}, dz, dy, mat),      // left
+			NewQuad(Vec3{minP.x, maxP.y, maxP.z}, dx, Neg(dz), mat), // top
+			NewQuad(Vec3{minP.x, minP.y, minP.z}, dx, dz, mat),      // bottom
+		},
+	}
+}
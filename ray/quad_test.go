@@ -0,0 +1,151 @@
+package ray
+
+import (
+	"math"
+	"testing"
+)
+
+func TestQuadHit(t *testing.T) {
+	rnd := RandForTests()
+	mat := Lambertian{Albedo: ColorF{1, 0, 0}}
+	// Unit square in the z=-2 plane, facing the camera (+Z).
+	quad := NewQuad(Vec3{-0.5, -0.5, -2}, Vec3{1, 0, 0}, Vec3{0, 1, 0}, mat)
+
+	hit, rec := testHit(quad, NewRay(rnd, Vec3{0, 0, 0}, Vec3{0, 0, -1}), FrontEpsilon)
+	if !hit {
+		t.Fatal("Expected hit")
+	}
+	if math.Abs(rec.T-2) > 1e-10 {
+		t.Errorf("Expected t=2, got %v", rec.T)
+	}
+	if !rec.FrontFace {
+		t.Error("Expected front face hit")
+	}
+	if rec.Normal != (Vec3{0, 0, 1}) {
+		t.Errorf("Expected normal {0, 0, 1}, got %v", rec.Normal)
+	}
+	if rec.Mat != mat {
+		t.Errorf("Expected material %v, got %v", mat, rec.Mat)
+	}
+}
+
+func TestQuadMiss(t *testing.T) {
+	rnd := RandForTests()
+	quad := NewQuad(Vec3{-0.5, -0.5, -2}, Vec3{1, 0, 0}, Vec3{0, 1, 0}, Lambertian{})
+	tests := []struct {
+		name string
+		dir  Vec3
+	}{
+		{"outside the edges", Vec3{1, 0, -1}},
+		{"parallel to the plane", Vec3{1, 0, 0}},
+		{"pointing away", Vec3{0, 0, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if hit, _ := testHit(quad, NewRay(rnd, Vec3{0, 0, 0}, tt.dir), FrontEpsilon); hit {
+				t.Error("Expected no hit")
+			}
+		})
+	}
+}
+
+func TestQuadBoundingBox(t *testing.T) {
+	quad := NewQuad(Vec3{1, 0, 0}, Vec3{-2, 0, 0}, Vec3{0, 3, -1}, Lambertian{})
+	expected := NewAABB(Vec3{-1, 0, -1}, Vec3{1, 3, 0})
+	if box := quad.BoundingBox(); box != expected {
+		t.Errorf("BoundingBox() = %v, want %v", box, expected)
+	}
+}
+
+func TestQuadBoundingBoxPadded(t *testing.T) {
+	rnd := RandForTests()
+	// Axis aligned quad in the z=-1 plane: its box is padded to be hit by rays.
+	quad := NewQuad(Vec3{-1, -1, -1}, Vec3{2, 0, 0}, Vec3{0, 2, 0}, Lambertian{})
+	box := quad.BoundingBox()
+	if box.Z.Length() < quadPadding || !box.Contains(Vec3{0, 0, -1}) {
+		t.Errorf("BoundingBox() = %v, should be padded around z=-1", box)
+	}
+	if !box.Hit(NewRay(rnd, Vec3{0, 0, 0}, Vec3{0.1, 0.2, -1}), FrontEpsilon) {
+		t.Errorf("BoundingBox().Hit() should hit the flat quad's box")
+	}
+}
+
+func TestNewBox(t *testing.T) {
+	rnd := RandForTests()
+	// Corners given in "wrong" order on purpose.
+	box := NewBox(Vec3{1, 2, -3}, Vec3{-1, 0, -5}, Lambertian{Albedo: ColorF{1, 1, 1}})
+	// Each face's box is padded by quadPadding in its flat dimension.
+	p := Vec3{quadPadding / 2, quadPadding / 2, quadPadding / 2}
+	expected := NewAABB(Sub(Vec3{-1, 0, -5}, p), Add(Vec3{1, 2, -3}, p))
+	if bb := box.BoundingBox(); !Equal(bb.Centroid(), expected.Centroid(), 1e-12) ||
+		math.Abs(bb.X.Length()-expected.X.Length()) > 1e-12 || math.Abs(bb.Y.Length()-expected.Y.Length()) > 1e-12 ||
+		math.Abs(bb.Z.Length()-expected.Z.Length()) > 1e-12 {
+		t.Errorf("BoundingBox() = %v, want %v", bb, expected)
+	}
+	scene, ok := box.(*Scene)
+	if !ok {
+		t.Fatalf("NewBox() returned %T, want *Scene", box)
+	}
+	if len(scene.Objects) != 6 {
+		t.Errorf("NewBox() has %d sides, want 6", len(scene.Objects))
+	}
+	tests := []struct {
+		name     string
+		origin   Vec3
+		dir      Vec3
+		expected Vec3 // hit point
+		normal   Vec3
+	}{
+		{"front", Vec3{0, 1, 0}, Vec3{0, 0, -1}, Vec3{0, 1, -3}, Vec3{0, 0, 1}},
+		{"back", Vec3{0, 1, -10}, Vec3{0, 0, 1}, Vec3{0, 1, -5}, Vec3{0, 0, -1}},
+		{"left", Vec3{-5, 1, -4}, Vec3{1, 0, 0}, Vec3{-1, 1, -4}, Vec3{-1, 0, 0}},
+		{"right", Vec3{5, 1, -4}, Vec3{-1, 0, 0}, Vec3{1, 1, -4}, Vec3{1, 0, 0}},
+		{"top", Vec3{0, 5, -4}, Vec3{0, -1, 0}, Vec3{0, 2, -4}, Vec3{0, 1, 0}},
+		{"bottom", Vec3{0, -5, -4}, Vec3{0, 1, 0}, Vec3{0, 0, -4}, Vec3{0, -1, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hit, rec := testHit(box, NewRay(rnd, tt.origin, tt.dir), FrontEpsilon)
+			if !hit {
+				t.Fatal("Expected hit")
+			}
+			if !Equal(rec.Point, tt.expected, 1e-10) {
+				t.Errorf("Expected hit point %v, got %v", tt.expected, rec.Point)
+			}
+			if !Equal(rec.Normal, tt.normal, 1e-10) {
+				t.Errorf("Expected normal %v, got %v", tt.normal, rec.Normal)
+			}
+			if !rec.FrontFace {
+				t.Error("Expected front face hit from outside the box")
+			}
+		})
+	}
+	// Missing the box entirely.
+	if hit, _ := testHit(box, NewRay(rnd, Vec3{0, 5, 0}, Vec3{0, 0, -1}), FrontEpsilon); hit {
+		t.Error("Expected no hit above the box")
+	}
+}
+
+func TestQuadOneSided(t *testing.T) {
+	rnd := RandForTests()
+	// Ceiling light at y=1, facing down.
+	light := NewQuad(Vec3{-1, 1, -1}, Vec3{2, 0, 0}, Vec3{0, 0, 2}, DiffuseLight{Emit: ColorF{4, 4, 4}})
+	for _, oneSided := range []bool{false, true} {
+		light.OneSided = oneSided
+		scene := &Scene{Objects: []Hittable{light}, Background: NoBackground}
+		if hit, rec := testHit(light, NewRay(rnd, Vec3{0, 0, 0}, Vec3{0, 1, 0}), FrontEpsilon); !hit || !rec.FrontFace {
+			t.Errorf("OneSided %v: Hit() from below = %v, want front face hit", oneSided, hit)
+		}
+		hit, _ := testHit(light, NewRay(rnd, Vec3{0, 2, 0}, Vec3{0, -1, 0}), FrontEpsilon)
+		if hit == oneSided {
+			t.Errorf("OneSided %v: Hit() from above = %v, want %v", oneSided, hit, !oneSided)
+		}
+		want := ColorF{4, 4, 4}
+		if oneSided {
+			want = ColorF{} // no light leaking upward
+		}
+		if got := scene.RayColor(NewRay(rnd, Vec3{0, 2, 0}, Vec3{0, -1, 0}), 1); got != want {
+			t.Errorf("OneSided %v: RayColor() from above = %v, want %v", oneSided, got, want)
+		}
+	}
+}
@@ -40,6 +40,54 @@ func RandomOnHemisphere(r rand.Rand, normal Vec3) Vec3 {
 	return Neg(onUnitSphere)
 }
 
+// RandomCosineDirection returns a random unit vector on the hemisphere oriented by the
+// given unit normal, with a density proportional to the cosine to the normal (the
+// distribution of light scattered by ideal diffuse surfaces). Unlike normal plus a random
+// unit vector it's always a unit vector, never (close to) zero.
+func RandomCosineDirection(r rand.Rand, normal Vec3) Vec3 {
+	u, v := orthonormalBasis(normal)
+	return cosineDirection(r, u, v, normal)
+}
+
+// Rand wraps a rand.Rand with methods returning Vec3, so scene and material code can read
+// rng.UnitVector() instead of RandomUnitVector(rng). The embedded rand.Rand methods remain
+// available, except UnitVector which is shadowed (use r.Rand.UnitVector() for the 3 floats).
+// Like rand.Rand, it's a cheap value not to be shared across goroutines.
+type Rand struct {
+	rand.Rand
+}
+
+// NewRand returns a Rand seeded with seed (0 for a random seed), see rand.New.
+func NewRand(seed uint64) Rand {
+	return Rand{rand.New(seed)}
+}
+
+// Vec3Random returns a random vector with each component in [0,1), like Random.
+func (r Rand) Vec3Random() Vec3 {
+	return Random(r.Rand)
+}
+
+// Vec3InRange returns a random vector with each component in [iv.Start, iv.End), like RandomInRange.
+func (r Rand) Vec3InRange(iv Interval) Vec3 {
+	return RandomInRange(r.Rand, iv)
+}
+
+// UnitVector returns a random unit vector, uniformly distributed on the sphere, like RandomUnitVector.
+func (r Rand) UnitVector() Vec3 {
+	return RandomUnitVector(r.Rand)
+}
+
+// OnHemisphere returns a random unit vector on the hemisphere oriented by normal, like RandomOnHemisphere.
+func (r Rand) OnHemisphere(normal Vec3) Vec3 {
+	return RandomOnHemisphere(r.Rand, normal)
+}
+
+// CosineDirection returns a cosine distributed random unit vector around the unit normal,
+// like RandomCosineDirection.
+func (r Rand) CosineDirection(normal Vec3) Vec3 {
+	return RandomCosineDirection(r.Rand, normal)
+}
+
 // The following functions are kept for backward compatibility with existing tests
 // that compare different random unit vector generation methods.
 
@@ -8,6 +8,11 @@ type Ray struct {
 	rand.Rand
 	Origin    Vec3
 	Direction Vec3
+	// Time at which the ray exists (for motion blur), in the camera's shutter interval.
+	Time float64
+	// Channel is the color channel the path follows since it went through a dispersive
+	// Dielectric (1 for red, 2 for green, 3 for blue), 0 while it still carries all 3.
+	Channel int
 }
 
 // NewRay creates a new Ray with the given origin and direction, transferring
@@ -21,5 +26,5 @@ func NewRay(r rand.Rand, origin, direction Vec3) *Ray {
 }
 
 func (r *Ray) At(t float64) Vec3 {
-	return Add(r.Origin, SMul(r.Direction, t))
+	return AddScaled(r.Origin, r.Direction, t)
 }
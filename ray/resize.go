@@ -0,0 +1,94 @@
+package ray
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"strings"
+
+	"fortio.org/terminal/ansipixels/tcolor"
+)
+
+// ResizeFilter selects how Resize computes each destination pixel from the source ones.
+type ResizeFilter int
+
+const (
+	// ResizeBox averages the source pixels covered by the destination pixel, weighted by
+	// their coverage (default). Best for downsampling supersampled images.
+	ResizeBox ResizeFilter = iota
+	// ResizeBilinear interpolates the 4 source pixels nearest to the destination pixel's
+	// center. Skips pixels when shrinking by more than 2x.
+	ResizeBilinear
+	// ResizeNearest picks the source pixel under the destination pixel's center.
+	ResizeNearest
+)
+
+var resizeFilterNames = []string{"box", "bilinear", "nearest"}
+
+func (f ResizeFilter) String() string {
+	if f < 0 || int(f) >= len(resizeFilterNames) {
+		return fmt.Sprintf("ResizeFilter(%d)", int(f))
+	}
+	return resizeFilterNames[f]
+}
+
+// ParseResizeFilter returns the ResizeFilter for the given name (case insensitive):
+// one of "box", "bilinear" or "nearest".
+func ParseResizeFilter(name string) (ResizeFilter, error) {
+	for i, n := range resizeFilterNames {
+		if strings.EqualFold(name, n) {
+			return ResizeFilter(i), nil
+		}
+	}
+	return ResizeBox, fmt.Errorf("unknown resize filter %q, should be one of %v", name, resizeFilterNames)
+}
+
+// Resize returns src (sRGB encoded, e.g. from Render with the default OutputEncoding)
+// scaled to w x h using filter. Unlike scaling the 8 bit values directly, the pixels are
+// converted to linear light before being combined and back to sRGB after, so averaging
+// e.g. black and white gives a mid gray (188) and edges don't get darkened.
+func Resize(src *image.RGBA, w, h int, filter ResizeFilter) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	b := src.Bounds()
+	sw, sh := b.Dx(), b.Dy()
+	if w <= 0 || h <= 0 || sw == 0 || sh == 0 {
+		return dst
+	}
+	linear := make([]ColorF, sw*sh)
+	for y := range sh {
+		for x := range sw {
+			c := src.RGBAAt(b.Min.X+x, b.Min.Y+y)
+			linear[y*sw+x] = ColorF{tcolor.SrgbToLinear(c.R, 1), tcolor.SrgbToLinear(c.G, 1), tcolor.SrgbToLinear(c.B, 1)}
+		}
+	}
+	fx, fy := float64(sw)/float64(w), float64(sh)/float64(h)
+	for y := range h {
+		for x := range w {
+			var c ColorF
+			switch filter {
+			case ResizeBilinear:
+				c = bilinear(linear, sw, (float64(x)+0.5)*fx-0.5, (float64(y)+0.5)*fy-0.5)
+			case ResizeNearest:
+				c = linear[min(int((float64(y)+0.5)*fy), sh-1)*sw+min(int((float64(x)+0.5)*fx), sw-1)]
+			default:
+				c = boxAverage(linear, sw, float64(x)*fx, float64(y)*fy, fx, fy)
+			}
+			dst.SetRGBA(x, y, c.ToSRGBA())
+		}
+	}
+	return dst
+}
+
+// bilinear interpolates the w wide buf at (x, y), in pixel index coordinates (clamped
+// to the edges).
+func bilinear(buf []ColorF, w int, x, y float64) ColorF {
+	h := len(buf) / w
+	x = math.Max(0, math.Min(x, float64(w-1)))
+	y = math.Max(0, math.Min(y, float64(h-1)))
+	x0, y0 := int(x), int(y)
+	x1, y1 := min(x0+1, w-1), min(y0+1, h-1)
+	tx, ty := x-float64(x0), y-float64(y0)
+	top := Add(SMul(buf[y0*w+x0], 1-tx), SMul(buf[y0*w+x1], tx))
+	bottom := Add(SMul(buf[y1*w+x0], 1-tx), SMul(buf[y1*w+x1], tx))
+	return Add(SMul(top, 1-ty), SMul(bottom, ty))
+}
@@ -0,0 +1,87 @@
+package ray
+
+import (
+	"image"
+	"image/color"
+	"slices"
+	"testing"
+
+	"fortio.org/terminal/ansipixels/tcolor"
+)
+
+func TestParseResizeFilter(t *testing.T) {
+	for _, f := range []ResizeFilter{ResizeBox, ResizeBilinear, ResizeNearest} {
+		parsed, err := ParseResizeFilter(f.String())
+		if err != nil || parsed != f {
+			t.Errorf("ParseResizeFilter(%q) = %v, %v; want %v", f.String(), parsed, err, f)
+		}
+	}
+	if f, err := ParseResizeFilter("BiLinear"); err != nil || f != ResizeBilinear {
+		t.Errorf("ParseResizeFilter(\"BiLinear\") = %v, %v; want bilinear", f, err)
+	}
+	if _, err := ParseResizeFilter("lanczos"); err == nil {
+		t.Error("Expected error for unknown resize filter")
+	}
+	if s := ResizeFilter(7).String(); s != "ResizeFilter(7)" {
+		t.Errorf("String() = %q, want \"ResizeFilter(7)\"", s)
+	}
+}
+
+func checkerboard(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := range h {
+		for x := range w {
+			if (x+y)%2 == 0 {
+				img.SetRGBA(x, y, color.RGBA{255, 255, 255, 255})
+			} else {
+				img.SetRGBA(x, y, color.RGBA{0, 0, 0, 255})
+			}
+		}
+	}
+	return img
+}
+
+func TestResizeCheckerboard(t *testing.T) {
+	src := checkerboard(8, 6)
+	// Half black, half white in linear light is 0.5, which is 188 in sRGB (not the 128
+	// of averaging the 8 bit values).
+	gray := tcolor.LinearToSrgb(0.5)
+	if gray != 188 {
+		t.Fatalf("LinearToSrgb(0.5) = %d, want 188", gray)
+	}
+	for _, filter := range []ResizeFilter{ResizeBox, ResizeBilinear} {
+		dst := Resize(src, 4, 3, filter)
+		if b := dst.Bounds(); b.Dx() != 4 || b.Dy() != 3 {
+			t.Fatalf("%v: Resize() size = %v, want 4x3", filter, b)
+		}
+		for y := range 3 {
+			for x := range 4 {
+				if c := dst.RGBAAt(x, y); c != (color.RGBA{gray, gray, gray, 255}) {
+					t.Errorf("%v: pixel %d,%d = %v, want gray %d", filter, x, y, c, gray)
+				}
+			}
+		}
+	}
+	// Nearest keeps the pixels at the even coordinates: all white.
+	dst := Resize(src, 4, 3, ResizeNearest)
+	for i, v := range dst.Pix {
+		if v != 255 {
+			t.Fatalf("nearest: Pix[%d] = %d, want 255", i, v)
+		}
+	}
+}
+
+func TestResizeSameSize(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 5, 4))
+	for i := range src.Pix {
+		src.Pix[i] = uint8(i * 13)
+		if i%4 == 3 {
+			src.Pix[i] = 255
+		}
+	}
+	for _, filter := range []ResizeFilter{ResizeBox, ResizeBilinear, ResizeNearest} {
+		if dst := Resize(src, 5, 4, filter); !slices.Equal(dst.Pix, src.Pix) {
+			t.Errorf("%v: Resize() to the same size changed the image", filter)
+		}
+	}
+}
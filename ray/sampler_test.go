@@ -0,0 +1,105 @@
+package ray
+
+import "testing"
+
+func TestParseSampler(t *testing.T) {
+	for _, s := range []Sampler{SamplerRandom, SamplerStratified} {
+		parsed, err := ParseSampler(s.String())
+		if err != nil || parsed != s {
+			t.Errorf("ParseSampler(%q) = %v, %v; want %v", s.String(), parsed, err, s)
+		}
+	}
+	if s, err := ParseSampler("Stratified"); err != nil || s != SamplerStratified {
+		t.Errorf("ParseSampler(\"Stratified\") = %v, %v; want stratified", s, err)
+	}
+	if _, err := ParseSampler("sobol"); err == nil {
+		t.Error("Expected error for unknown sampler")
+	}
+	if s := Sampler(7).String(); s != "Sampler(7)" {
+		t.Errorf("String() = %q, want \"Sampler(7)\"", s)
+	}
+}
+
+func TestSamplerStrata(t *testing.T) {
+	tests := []struct {
+		sampler  Sampler
+		numRays  int
+		expected int
+	}{
+		{SamplerRandom, 16, 0},
+		{SamplerStratified, 1, 0},
+		{SamplerStratified, 4, 2},
+		{SamplerStratified, 64, 8},
+		{SamplerStratified, 10, 0}, // not a perfect square: random
+	}
+	for _, tt := range tests {
+		if n := tt.sampler.strata(tt.numRays); n != tt.expected {
+			t.Errorf("%v.strata(%d) = %d, want %d", tt.sampler, tt.numRays, n, tt.expected)
+		}
+	}
+}
+
+func TestStratifiedOffset(t *testing.T) {
+	rng := RandForTests()
+	const n, radius = 4, 0.5
+	cell := 2 * radius / n
+	for j := range n {
+		for i := range n {
+			for range 10 {
+				x, y := stratifiedOffset(rng, i, j, n, radius)
+				minX, minY := -radius+float64(i)*cell, -radius+float64(j)*cell
+				if x < minX || x >= minX+cell || y < minY || y >= minY+cell {
+					t.Fatalf("stratifiedOffset(%d, %d) = (%v, %v), want within cell [%v, %v) x [%v, %v)",
+						i, j, x, y, minX, minX+cell, minY, minY+cell)
+				}
+			}
+		}
+	}
+}
+
+// edgeScene is a flat white emitter whose bottom edge crosses the view diagonally,
+// so the pixels along it are partially covered and only antialiasing noise remains.
+func edgeScene() *Scene {
+	light := DiffuseLight{Emit: ColorF{1, 1, 1}}
+	quad := NewQuad(Vec3{-5, -1.5, -1}, Vec3{10, 3, 0}, Vec3{0, 10, 0}, light)
+	return &Scene{Objects: []Hittable{quad}, Background: NoBackground}
+}
+
+func renderEdge(sampler Sampler, numRays int, seed uint64) []uint8 {
+	tracer := New(12, 12)
+	tracer.Seed = seed
+	tracer.NumRaysPerPixel = numRays
+	tracer.Sampler = sampler
+	return tracer.Render(edgeScene()).Pix
+}
+
+// variance returns the sum over all the pixels of their variance across renders.
+func variance(renders [][]uint8) float64 {
+	total := 0.0
+	n := float64(len(renders))
+	for i := range renders[0] {
+		sum, sum2 := 0.0, 0.0
+		for _, pix := range renders {
+			v := float64(pix[i])
+			sum += v
+			sum2 += v * v
+		}
+		mean := sum / n
+		total += sum2/n - mean*mean
+	}
+	return total
+}
+
+func TestSamplerStratifiedLessNoise(t *testing.T) {
+	noise := func(sampler Sampler) float64 {
+		var renders [][]uint8
+		for seed := range uint64(30) {
+			renders = append(renders, renderEdge(sampler, 16, seed+1))
+		}
+		return variance(renders)
+	}
+	random, stratified := noise(SamplerRandom), noise(SamplerStratified)
+	if stratified > 0.75*random {
+		t.Errorf("stratified variance %.0f, want clearly less than random's %.0f", stratified, random)
+	}
+}
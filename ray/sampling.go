@@ -0,0 +1,46 @@
+package ray
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Sampling selects how diffuse surfaces pick the direction of their scattered
+// rays when the Scene has Emitters, to compare the noise of each (with no Emitters, or
+// off other materials, the material's own sampling is always used).
+type Sampling int
+
+const (
+	// SamplingMIS samples half of the directions from the material (cosine) and half
+	// toward the Emitters, weighted by the density of the mix (one sample multiple
+	// importance sampling with the balance heuristic). Robust to both small bright and
+	// large emitters (default).
+	SamplingMIS Sampling = iota
+	// SamplingBRDF only uses the material's sampling: the emitters are only found by
+	// chance, very noisy for small ones.
+	SamplingBRDF
+	// SamplingLight only samples directions toward the Emitters: the least noise from
+	// small emitters, but noisy near large ones and the light bouncing off other
+	// (non emitter) objects is lost, so it's biased (darker) beyond direct lighting.
+	SamplingLight
+)
+
+var samplingNames = []string{"mis", "brdf", "light"}
+
+func (s Sampling) String() string {
+	if s < 0 || int(s) >= len(samplingNames) {
+		return fmt.Sprintf("Sampling(%d)", int(s))
+	}
+	return samplingNames[s]
+}
+
+// ParseSampling returns the Sampling for the given name (case insensitive):
+// one of "mis", "brdf" or "light".
+func ParseSampling(name string) (Sampling, error) {
+	for i, n := range samplingNames {
+		if strings.EqualFold(name, n) {
+			return Sampling(i), nil
+		}
+	}
+	return SamplingMIS, fmt.Errorf("unknown sampling strategy %q, should be one of %v", name, samplingNames)
+}
@@ -0,0 +1,65 @@
+package ray
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseSampling(t *testing.T) {
+	for _, s := range []Sampling{SamplingMIS, SamplingBRDF, SamplingLight} {
+		parsed, err := ParseSampling(s.String())
+		if err != nil || parsed != s {
+			t.Errorf("ParseSampling(%q) = %v, %v; want %v", s.String(), parsed, err, s)
+		}
+	}
+	if s, err := ParseSampling("BRDF"); err != nil || s != SamplingBRDF {
+		t.Errorf("ParseSampling(\"BRDF\") = %v, %v; want brdf", s, err)
+	}
+	if _, err := ParseSampling("bidir"); err == nil {
+		t.Error("Expected error for unknown sampling strategy")
+	}
+	if s := Sampling(7).String(); s != "Sampling(7)" {
+		t.Errorf("String() = %q, want \"Sampling(7)\"", s)
+	}
+}
+
+// TestSamplingMIS checks that, on EmittersScene's floor, the MIS mix has less noise than
+// either strategy alone: the light sampling struggles in front of the big wall and the
+// diffuse sampling rarely finds the small bulb.
+func TestSamplingMIS(t *testing.T) {
+	scene, camera := EmittersScene()
+	const w, h = 32, 18
+	render := func(s Sampling, rays int, seed uint64) []ColorF {
+		rt := New(w, h)
+		rt.Seed = seed
+		rt.Camera = *camera
+		rt.MaxDepth = 2     // direct lighting only, which all the strategies get (see SamplingLight)
+		rt.RayRadius = 1e-9 // same point for all the rays of a pixel: no antialiasing noise
+		rt.NumRaysPerPixel = rays
+		rt.SamplingStrategy = s
+		rt.KeepHDR = true
+		rt.Render(scene)
+		return rt.HDRBuffer()
+	}
+	ref := render(SamplingMIS, 1024, 1)
+	mse := map[Sampling]float64{}
+	mean := map[Sampling]float64{}
+	for _, s := range []Sampling{SamplingMIS, SamplingBRDF, SamplingLight} {
+		for seed := uint64(42); seed < 46; seed++ {
+			for i, c := range render(s, 16, seed) {
+				d := Luminance(c) - Luminance(ref[i])
+				mse[s] += d * d
+				mean[s] += Luminance(c)
+			}
+		}
+	}
+	t.Logf("squared errors: %v", mse)
+	if mse[SamplingMIS] >= mse[SamplingLight] || mse[SamplingMIS] >= mse[SamplingBRDF] {
+		t.Errorf("MIS error %v should be less than the light only %v and brdf only %v ones",
+			mse[SamplingMIS], mse[SamplingLight], mse[SamplingBRDF])
+	}
+	// Same expected image (the brdf one is too noisy to check that way).
+	if d := math.Abs(mean[SamplingLight]/mean[SamplingMIS] - 1); d > 0.02 {
+		t.Errorf("light only average %v differs from MIS %v by %.1f%%", mean[SamplingLight], mean[SamplingMIS], 100*d)
+	}
+}
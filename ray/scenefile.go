@@ -0,0 +1,842 @@
+package ray
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+)
+
+// JSON scene file format: objects, materials, textures and backgrounds are
+// encoded as objects with a "type" discriminator field, e.g.
+//
+//	{"type": "sphere", "center": [0, 1, 0], "radius": 1,
+//	  "material": {"type": "dielectric", "ref_idx": 1.5}}
+//
+// Vectors and colors are encoded as [x, y, z] / [r, g, b] arrays; objects with x, y, z
+// (or r, g, b) keys are also accepted when loading (see Vec3.UnmarshalJSON). The scene's
+// emitters are the indices of those objects in "objects".
+
+type typeJSON struct {
+	Type string `json:"type"`
+}
+
+type sceneJSON struct {
+	Camera     *cameraJSON       `json:"camera,omitempty"`
+	Background json.RawMessage   `json:"background,omitempty"`
+	Lights     []json.RawMessage `json:"lights,omitempty"`
+	Objects    []json.RawMessage `json:"objects"`
+	Emitters   []int             `json:"emitters,omitempty"` // Indices in Objects of the Scene.Emitters
+}
+
+type cameraJSON struct {
+	Position      Vec3    `json:"position"`
+	LookAt        Vec3    `json:"look_at"`
+	Up            Vec3    `json:"up"`
+	VerticalFoV   float64 `json:"vertical_fov,omitempty"`
+	FocalLength   float64 `json:"focal_length,omitempty"`
+	FocusDistance float64 `json:"focus_distance,omitempty"`
+	AutoFocus     *Vec3   `json:"auto_focus,omitempty"`
+	Aperture      float64 `json:"aperture,omitempty"`
+	Blades        int     `json:"aperture_blades,omitempty"`
+	Orthographic  bool    `json:"orthographic,omitempty"`
+	OrthoHeight   float64 `json:"ortho_height,omitempty"`
+	Time0         float64 `json:"time0,omitempty"`
+	Time1         float64 `json:"time1,omitempty"`
+	PixelAspect   float64 `json:"pixel_aspect,omitempty"`
+}
+
+type sphereJSON struct {
+	Type     string          `json:"type"`
+	Center   Vec3            `json:"center"`
+	Radius   float64         `json:"radius"`
+	Material json.RawMessage `json:"material"`
+}
+
+type ellipsoidJSON struct {
+	Type     string          `json:"type"`
+	Center   Vec3            `json:"center"`
+	Radii    Vec3            `json:"radii"`
+	Material json.RawMessage `json:"material"`
+}
+
+type movingSphereJSON struct {
+	Type     string          `json:"type"`
+	Center   Vec3            `json:"center"`
+	Center1  Vec3            `json:"center1"`
+	Radius   float64         `json:"radius"`
+	Material json.RawMessage `json:"material"`
+}
+
+type quadJSON struct {
+	Type     string          `json:"type"`
+	Q        Vec3            `json:"q"`
+	U        Vec3            `json:"u"`
+	V        Vec3            `json:"v"`
+	Material json.RawMessage `json:"material"`
+	OneSided bool            `json:"one_sided,omitempty"`
+}
+
+type diskJSON struct {
+	Type     string          `json:"type"`
+	Center   Vec3            `json:"center"`
+	Normal   Vec3            `json:"normal"`
+	Radius   float64         `json:"radius"`
+	Material json.RawMessage `json:"material"`
+	OneSided bool            `json:"one_sided,omitempty"`
+}
+
+type planeJSON struct {
+	Type     string          `json:"type"`
+	Point    Vec3            `json:"point"`
+	Normal   Vec3            `json:"normal"`
+	Material json.RawMessage `json:"material"`
+}
+
+type translateJSON struct {
+	Type   string          `json:"type"`
+	Offset Vec3            `json:"offset"`
+	Object json.RawMessage `json:"object"`
+}
+
+type rotateYJSON struct {
+	Type   string          `json:"type"`
+	Angle  float64         `json:"angle"`
+	Object json.RawMessage `json:"object"`
+}
+
+type groupJSON struct {
+	Type    string            `json:"type"`
+	Objects []json.RawMessage `json:"objects"`
+}
+
+type lambertianJSON struct {
+	Type        string          `json:"type"`
+	Albedo      ColorF          `json:"albedo"`
+	Texture     json.RawMessage `json:"texture,omitempty"`
+	BookScatter bool            `json:"book_scatter,omitempty"`
+	NormalMap   json.RawMessage `json:"normal_map,omitempty"`
+}
+
+type metalJSON struct {
+	Type      string          `json:"type"`
+	Albedo    ColorF          `json:"albedo"`
+	Texture   json.RawMessage `json:"texture,omitempty"`
+	Fuzz      float64         `json:"fuzz,omitempty"`
+	Roughness float64         `json:"roughness,omitempty"`
+	NormalMap json.RawMessage `json:"normal_map,omitempty"`
+}
+
+type dielectricJSON struct {
+	Type       string  `json:"type"`
+	RefIdx     float64 `json:"ref_idx"`
+	Absorption ColorF  `json:"absorption,omitzero"`
+	Roughness  float64 `json:"roughness,omitempty"`
+	Tint       ColorF  `json:"tint,omitzero"`
+	Dispersion float64 `json:"dispersion,omitempty"`
+}
+
+type diffuseLightJSON struct {
+	Type string `json:"type"`
+	Emit ColorF `json:"emit"`
+}
+
+type mixJSON struct {
+	Type  string          `json:"type"`
+	A     json.RawMessage `json:"a"`
+	B     json.RawMessage `json:"b"`
+	Ratio float64         `json:"ratio"`
+}
+
+type solidColorJSON struct {
+	Type   string `json:"type"`
+	Albedo ColorF `json:"albedo"`
+}
+
+type checkerJSON struct {
+	Type  string  `json:"type"`
+	Scale float64 `json:"scale,omitempty"`
+	Even  ColorF  `json:"even"`
+	Odd   ColorF  `json:"odd"`
+}
+
+type imageTextureJSON struct {
+	Type string `json:"type"`
+	Path string `json:"path"`
+	Wrap bool   `json:"wrap,omitempty"`
+}
+
+type noiseTextureJSON struct {
+	Type   string  `json:"type"`
+	Scale  float64 `json:"scale,omitempty"`
+	Depth  int     `json:"depth,omitempty"`
+	Marble bool    `json:"marble,omitempty"`
+	Seed   uint64  `json:"seed"`
+}
+
+type ambientLightJSON struct {
+	Type         string  `json:"type"`
+	ColorA       ColorF  `json:"color_a"`
+	ColorB       ColorF  `json:"color_b"`
+	SunDirection Vec3    `json:"sun_direction,omitzero"`
+	SunRadius    float64 `json:"sun_radius,omitempty"`
+	SunColor     ColorF  `json:"sun_color,omitzero"`
+}
+
+type directionalLightJSON struct {
+	Type      string `json:"type"`
+	Direction Vec3   `json:"direction"`
+	Color     ColorF `json:"color"`
+}
+
+type pointLightJSON struct {
+	Type      string  `json:"type"`
+	Position  Vec3    `json:"position"`
+	Color     ColorF  `json:"color"`
+	Intensity float64 `json:"intensity,omitempty"`
+}
+
+type spotLightJSON struct {
+	Type      string  `json:"type"`
+	Position  Vec3    `json:"position"`
+	Direction Vec3    `json:"direction"`
+	Color     ColorF  `json:"color"`
+	Intensity float64 `json:"intensity,omitempty"`
+	ConeAngle float64 `json:"cone_angle"`
+	Falloff   float64 `json:"falloff,omitempty"`
+}
+
+type environmentMapJSON struct {
+	Type      string  `json:"type"`
+	Path      string  `json:"path"`
+	Intensity float64 `json:"intensity,omitempty"`
+}
+
+// SaveScene writes the scene and the (optional, can be nil) camera as JSON.
+func SaveScene(w io.Writer, s *Scene, c *Camera) error {
+	sj := sceneJSON{Objects: make([]json.RawMessage, 0, len(s.Objects))}
+	if c != nil {
+		sj.Camera = &cameraJSON{
+			Position:      c.Position,
+			LookAt:        c.LookAt,
+			Up:            c.Up,
+			VerticalFoV:   c.VerticalFoV,
+			FocalLength:   c.FocalLength,
+			FocusDistance: c.FocusDistance,
+			AutoFocus:     c.AutoFocus,
+			Aperture:      c.Aperture,
+			Blades:        c.ApertureBlades,
+			Orthographic:  c.Orthographic,
+			OrthoHeight:   c.OrthoHeight,
+			Time0:         c.Time0,
+			Time1:         c.Time1,
+			PixelAspect:   c.PixelAspect,
+		}
+	}
+	if s.Background != nil {
+		bg, err := encodeBackground(s.Background)
+		if err != nil {
+			return err
+		}
+		sj.Background = bg
+	}
+	for _, l := range s.Lights {
+		lj, err := encodeLight(l)
+		if err != nil {
+			return err
+		}
+		sj.Lights = append(sj.Lights, lj)
+	}
+	for _, o := range s.Objects {
+		oj, err := encodeHittable(o)
+		if err != nil {
+			return err
+		}
+		sj.Objects = append(sj.Objects, oj)
+	}
+	for _, e := range s.Emitters {
+		idx := slices.IndexFunc(s.Objects, func(o Hittable) bool { return o == Hittable(e) })
+		if idx < 0 {
+			return fmt.Errorf("can't save emitter %T that isn't one of the scene's objects", e)
+		}
+		sj.Emitters = append(sj.Emitters, idx)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sj)
+}
+
+// LoadScene reads a JSON scene as written by SaveScene. The returned camera
+// is nil when the file doesn't have one.
+func LoadScene(r io.Reader) (*Scene, *Camera, error) {
+	var sj sceneJSON
+	if err := json.NewDecoder(r).Decode(&sj); err != nil {
+		return nil, nil, fmt.Errorf("invalid scene json: %w", err)
+	}
+	s := &Scene{}
+	if len(sj.Background) > 0 {
+		bg, err := decodeBackground(sj.Background)
+		if err != nil {
+			return nil, nil, err
+		}
+		s.Background = bg
+	}
+	for _, lj := range sj.Lights {
+		l, err := decodeLight(lj)
+		if err != nil {
+			return nil, nil, err
+		}
+		s.Lights = append(s.Lights, l)
+	}
+	objects, err := decodeHittables(sj.Objects)
+	if err != nil {
+		return nil, nil, err
+	}
+	s.Objects = objects
+	for _, idx := range sj.Emitters {
+		if idx < 0 || idx >= len(objects) {
+			return nil, nil, fmt.Errorf("emitter index %d out of the %d objects", idx, len(objects))
+		}
+		e, ok := objects[idx].(Emitter)
+		if !ok {
+			return nil, nil, fmt.Errorf("object %d (%T) can't be an emitter", idx, objects[idx])
+		}
+		s.Emitters = append(s.Emitters, e)
+	}
+	var c *Camera
+	if sj.Camera != nil {
+		if !ZeroOne.Contains(sj.Camera.Time0) || !ZeroOne.Contains(sj.Camera.Time1) {
+			return nil, nil, fmt.Errorf("camera shutter times %v, %v should be within [0, 1]", sj.Camera.Time0, sj.Camera.Time1)
+		}
+		c = &Camera{
+			Position:       sj.Camera.Position,
+			LookAt:         sj.Camera.LookAt,
+			Up:             sj.Camera.Up,
+			VerticalFoV:    sj.Camera.VerticalFoV,
+			FocalLength:    sj.Camera.FocalLength,
+			FocusDistance:  sj.Camera.FocusDistance,
+			AutoFocus:      sj.Camera.AutoFocus,
+			Aperture:       sj.Camera.Aperture,
+			ApertureBlades: sj.Camera.Blades,
+			Orthographic:   sj.Camera.Orthographic,
+			OrthoHeight:    sj.Camera.OrthoHeight,
+			Time0:          sj.Camera.Time0,
+			Time1:          sj.Camera.Time1,
+			PixelAspect:    sj.Camera.PixelAspect,
+		}
+	}
+	return s, c, nil
+}
+
+// LoadSceneFile is LoadScene from the named file.
+func LoadSceneFile(path string) (*Scene, *Camera, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not open scene %q: %w", path, err)
+	}
+	defer f.Close()
+	s, c, err := LoadScene(f)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not load scene %q: %w", path, err)
+	}
+	return s, c, nil
+}
+
+func decodeType(data json.RawMessage) (string, error) {
+	var t typeJSON
+	if err := json.Unmarshal(data, &t); err != nil {
+		return "", err
+	}
+	return t.Type, nil
+}
+
+func encodeHittable(h Hittable) (json.RawMessage, error) {
+	var v any
+	switch o := h.(type) {
+	case *Sphere:
+		mat, err := encodeMaterial(o.Mat)
+		if err != nil {
+			return nil, err
+		}
+		v = sphereJSON{Type: "sphere", Center: o.Center, Radius: o.Radius, Material: mat}
+	case *Ellipsoid:
+		mat, err := encodeMaterial(o.Mat)
+		if err != nil {
+			return nil, err
+		}
+		v = ellipsoidJSON{Type: "ellipsoid", Center: o.Center, Radii: o.Radii, Material: mat}
+	case *MovingSphere:
+		mat, err := encodeMaterial(o.Mat)
+		if err != nil {
+			return nil, err
+		}
+		v = movingSphereJSON{
+			Type: "moving_sphere", Center: o.Center, Center1: o.Center1,
+			Radius: o.Radius, Material: mat,
+		}
+	case *Quad:
+		mat, err := encodeMaterial(o.Mat)
+		if err != nil {
+			return nil, err
+		}
+		v = quadJSON{Type: "quad", Q: o.Q, U: o.U, V: o.V, Material: mat, OneSided: o.OneSided}
+	case *Disk:
+		mat, err := encodeMaterial(o.Mat)
+		if err != nil {
+			return nil, err
+		}
+		v = diskJSON{Type: "disk", Center: o.Center, Normal: o.Normal, Radius: o.Radius, Material: mat, OneSided: o.OneSided}
+	case *Plane:
+		mat, err := encodeMaterial(o.Mat)
+		if err != nil {
+			return nil, err
+		}
+		v = planeJSON{Type: "plane", Point: o.Point, Normal: o.Normal, Material: mat}
+	case *Translate:
+		oj, err := encodeHittable(o.Object)
+		if err != nil {
+			return nil, err
+		}
+		v = translateJSON{Type: "translate", Offset: o.Offset, Object: oj}
+	case *RotateY:
+		oj, err := encodeHittable(o.Object)
+		if err != nil {
+			return nil, err
+		}
+		v = rotateYJSON{Type: "rotate_y", Angle: o.Angle, Object: oj}
+	case *Scene:
+		g := groupJSON{Type: "group", Objects: make([]json.RawMessage, 0, len(o.Objects))}
+		for _, child := range o.Objects {
+			cj, err := encodeHittable(child)
+			if err != nil {
+				return nil, err
+			}
+			g.Objects = append(g.Objects, cj)
+		}
+		v = g
+	default:
+		return nil, fmt.Errorf("can't encode object of type %T", h)
+	}
+	return json.Marshal(v)
+}
+
+func decodeHittables(list []json.RawMessage) ([]Hittable, error) {
+	res := make([]Hittable, 0, len(list))
+	for _, oj := range list {
+		o, err := decodeHittable(oj)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, o)
+	}
+	return res, nil
+}
+
+func decodeHittable(data json.RawMessage) (Hittable, error) {
+	typ, err := decodeType(data)
+	if err != nil {
+		return nil, err
+	}
+	switch typ {
+	case "sphere":
+		var sj sphereJSON
+		if err = json.Unmarshal(data, &sj); err != nil {
+			return nil, err
+		}
+		mat, err := decodeMaterial(sj.Material)
+		if err != nil {
+			return nil, err
+		}
+		return NewSphere(sj.Center, sj.Radius, mat), nil
+	case "ellipsoid":
+		var ej ellipsoidJSON
+		if err = json.Unmarshal(data, &ej); err != nil {
+			return nil, err
+		}
+		mat, err := decodeMaterial(ej.Material)
+		if err != nil {
+			return nil, err
+		}
+		return NewEllipsoid(ej.Center, ej.Radii, mat), nil
+	case "moving_sphere":
+		var mj movingSphereJSON
+		if err = json.Unmarshal(data, &mj); err != nil {
+			return nil, err
+		}
+		mat, err := decodeMaterial(mj.Material)
+		if err != nil {
+			return nil, err
+		}
+		return &MovingSphere{Center: mj.Center, Center1: mj.Center1, Radius: mj.Radius, Mat: mat}, nil
+	case "quad":
+		var qj quadJSON
+		if err = json.Unmarshal(data, &qj); err != nil {
+			return nil, err
+		}
+		mat, err := decodeMaterial(qj.Material)
+		if err != nil {
+			return nil, err
+		}
+		q := NewQuad(qj.Q, qj.U, qj.V, mat)
+		q.OneSided = qj.OneSided
+		return q, nil
+	case "disk":
+		var dj diskJSON
+		if err = json.Unmarshal(data, &dj); err != nil {
+			return nil, err
+		}
+		mat, err := decodeMaterial(dj.Material)
+		if err != nil {
+			return nil, err
+		}
+		d := NewDisk(dj.Center, dj.Normal, dj.Radius, mat)
+		d.OneSided = dj.OneSided
+		return d, nil
+	case "plane":
+		var pj planeJSON
+		if err = json.Unmarshal(data, &pj); err != nil {
+			return nil, err
+		}
+		mat, err := decodeMaterial(pj.Material)
+		if err != nil {
+			return nil, err
+		}
+		return NewPlane(pj.Point, pj.Normal, mat), nil
+	case "translate":
+		var tj translateJSON
+		if err = json.Unmarshal(data, &tj); err != nil {
+			return nil, err
+		}
+		o, err := decodeHittable(tj.Object)
+		if err != nil {
+			return nil, err
+		}
+		return &Translate{Object: o, Offset: tj.Offset}, nil
+	case "rotate_y":
+		var rj rotateYJSON
+		if err = json.Unmarshal(data, &rj); err != nil {
+			return nil, err
+		}
+		o, err := decodeHittable(rj.Object)
+		if err != nil {
+			return nil, err
+		}
+		return NewRotateY(o, rj.Angle), nil
+	case "group":
+		var gj groupJSON
+		if err = json.Unmarshal(data, &gj); err != nil {
+			return nil, err
+		}
+		objects, err := decodeHittables(gj.Objects)
+		if err != nil {
+			return nil, err
+		}
+		return &Scene{Objects: objects}, nil
+	default:
+		return nil, fmt.Errorf("unknown object type %q", typ)
+	}
+}
+
+func encodeMaterial(m Material) (json.RawMessage, error) {
+	var v any
+	switch mat := m.(type) {
+	case Lambertian:
+		lj := lambertianJSON{Type: "lambertian", Albedo: mat.Albedo, BookScatter: mat.BookScatter}
+		if mat.Tex != nil {
+			tex, err := encodeTexture(mat.Tex)
+			if err != nil {
+				return nil, err
+			}
+			lj.Texture = tex
+		}
+		var err error
+		if lj.NormalMap, err = encodeOptionalTexture(mat.NormalMap); err != nil {
+			return nil, err
+		}
+		v = lj
+	case Metal:
+		mj := metalJSON{Type: "metal", Albedo: mat.Albedo, Fuzz: mat.Fuzz, Roughness: mat.Roughness}
+		var err error
+		if mj.Texture, err = encodeOptionalTexture(mat.Tex); err != nil {
+			return nil, err
+		}
+		if mj.NormalMap, err = encodeOptionalTexture(mat.NormalMap); err != nil {
+			return nil, err
+		}
+		v = mj
+	case Dielectric:
+		v = dielectricJSON{
+			Type: "dielectric", RefIdx: mat.RefIdx, Absorption: mat.Absorption,
+			Roughness: mat.Roughness, Tint: mat.Tint, Dispersion: mat.Dispersion,
+		}
+	case DiffuseLight:
+		v = diffuseLightJSON{Type: "diffuse_light", Emit: mat.Emit}
+	case MixMaterial:
+		mj := mixJSON{Type: "mix", Ratio: mat.Ratio}
+		var err error
+		if mj.A, err = encodeMaterial(mat.A); err != nil {
+			return nil, err
+		}
+		if mj.B, err = encodeMaterial(mat.B); err != nil {
+			return nil, err
+		}
+		v = mj
+	default:
+		return nil, fmt.Errorf("can't encode material of type %T", m)
+	}
+	return json.Marshal(v)
+}
+
+func decodeMaterial(data json.RawMessage) (Material, error) {
+	typ, err := decodeType(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid material: %w", err)
+	}
+	switch typ {
+	case "lambertian":
+		var lj lambertianJSON
+		if err = json.Unmarshal(data, &lj); err != nil {
+			return nil, err
+		}
+		l := Lambertian{Albedo: lj.Albedo, BookScatter: lj.BookScatter}
+		if len(lj.Texture) > 0 {
+			if l.Tex, err = decodeTexture(lj.Texture); err != nil {
+				return nil, err
+			}
+		}
+		if l.NormalMap, err = decodeOptionalTexture(lj.NormalMap); err != nil {
+			return nil, err
+		}
+		return l, nil
+	case "metal":
+		var mj metalJSON
+		if err = json.Unmarshal(data, &mj); err != nil {
+			return nil, err
+		}
+		m := Metal{Albedo: mj.Albedo, Fuzz: mj.Fuzz, Roughness: mj.Roughness}
+		if m.Tex, err = decodeOptionalTexture(mj.Texture); err != nil {
+			return nil, err
+		}
+		if m.NormalMap, err = decodeOptionalTexture(mj.NormalMap); err != nil {
+			return nil, err
+		}
+		return m, nil
+	case "dielectric":
+		var dj dielectricJSON
+		if err = json.Unmarshal(data, &dj); err != nil {
+			return nil, err
+		}
+		return Dielectric{
+			RefIdx: dj.RefIdx, Absorption: dj.Absorption,
+			Roughness: dj.Roughness, Tint: dj.Tint, Dispersion: dj.Dispersion,
+		}, nil
+	case "diffuse_light":
+		var dj diffuseLightJSON
+		if err = json.Unmarshal(data, &dj); err != nil {
+			return nil, err
+		}
+		return DiffuseLight{Emit: dj.Emit}, nil
+	case "mix":
+		var mj mixJSON
+		if err = json.Unmarshal(data, &mj); err != nil {
+			return nil, err
+		}
+		mm := MixMaterial{Ratio: mj.Ratio}
+		if mm.A, err = decodeMaterial(mj.A); err != nil {
+			return nil, err
+		}
+		if mm.B, err = decodeMaterial(mj.B); err != nil {
+			return nil, err
+		}
+		return mm, nil
+	default:
+		return nil, fmt.Errorf("unknown material type %q", typ)
+	}
+}
+
+func encodeTexture(t Texture) (json.RawMessage, error) {
+	var v any
+	switch tex := t.(type) {
+	case SolidColor:
+		v = solidColorJSON{Type: "solid", Albedo: tex.Albedo}
+	case CheckerTexture:
+		v = checkerJSON{Type: "checker", Scale: tex.Scale, Even: tex.Even, Odd: tex.Odd}
+	case *ImageTexture:
+		if tex.Path == "" {
+			return nil, fmt.Errorf("can't encode image texture without image path")
+		}
+		v = imageTextureJSON{Type: "image", Path: tex.Path, Wrap: tex.Wrap}
+	case *NoiseTexture:
+		if tex.Seed == 0 {
+			return nil, fmt.Errorf("can't encode noise texture without seed")
+		}
+		v = noiseTextureJSON{Type: "noise", Scale: tex.Scale, Depth: tex.Depth, Marble: tex.Marble, Seed: tex.Seed}
+	default:
+		return nil, fmt.Errorf("can't encode texture of type %T", t)
+	}
+	return json.Marshal(v)
+}
+
+// encodeOptionalTexture is encodeTexture for optional fields: nil for a nil Texture.
+func encodeOptionalTexture(t Texture) (json.RawMessage, error) {
+	if t == nil {
+		return nil, nil
+	}
+	return encodeTexture(t)
+}
+
+// decodeOptionalTexture is decodeTexture for optional fields: nil when absent.
+func decodeOptionalTexture(data json.RawMessage) (Texture, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	return decodeTexture(data)
+}
+
+func decodeTexture(data json.RawMessage) (Texture, error) {
+	typ, err := decodeType(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid texture: %w", err)
+	}
+	switch typ {
+	case "solid":
+		var sj solidColorJSON
+		if err = json.Unmarshal(data, &sj); err != nil {
+			return nil, err
+		}
+		return SolidColor{Albedo: sj.Albedo}, nil
+	case "checker":
+		var cj checkerJSON
+		if err = json.Unmarshal(data, &cj); err != nil {
+			return nil, err
+		}
+		return CheckerTexture{Scale: cj.Scale, Even: cj.Even, Odd: cj.Odd}, nil
+	case "image":
+		var ij imageTextureJSON
+		if err = json.Unmarshal(data, &ij); err != nil {
+			return nil, err
+		}
+		it, err := NewImageTexture(ij.Path)
+		if err != nil {
+			return nil, err
+		}
+		it.Wrap = ij.Wrap
+		return it, nil
+	case "noise":
+		var nj noiseTextureJSON
+		if err = json.Unmarshal(data, &nj); err != nil {
+			return nil, err
+		}
+		if nj.Seed == 0 {
+			return nil, fmt.Errorf("noise texture needs a non zero seed")
+		}
+		nt := NewNoiseTextureSeed(nj.Seed, nj.Scale, nj.Depth)
+		nt.Marble = nj.Marble
+		return nt, nil
+	default:
+		return nil, fmt.Errorf("unknown texture type %q", typ)
+	}
+}
+
+func encodeBackground(b Background) (json.RawMessage, error) {
+	switch bg := b.(type) {
+	case AmbientLight:
+		return json.Marshal(ambientLightJSON{
+			Type: "ambient", ColorA: bg.ColorA, ColorB: bg.ColorB,
+			SunDirection: bg.SunDirection, SunRadius: bg.SunRadius, SunColor: bg.SunColor,
+		})
+	case *EnvironmentMap:
+		if bg.Path == "" {
+			return nil, fmt.Errorf("can't encode environment map without image path")
+		}
+		return json.Marshal(environmentMapJSON{Type: "environment", Path: bg.Path, Intensity: bg.Intensity})
+	default:
+		return nil, fmt.Errorf("can't encode background of type %T", b)
+	}
+}
+
+func decodeBackground(data json.RawMessage) (Background, error) {
+	typ, err := decodeType(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid background: %w", err)
+	}
+	switch typ {
+	case "ambient":
+		var aj ambientLightJSON
+		if err = json.Unmarshal(data, &aj); err != nil {
+			return nil, err
+		}
+		return AmbientLight{
+			ColorA: aj.ColorA, ColorB: aj.ColorB,
+			SunDirection: aj.SunDirection, SunRadius: aj.SunRadius, SunColor: aj.SunColor,
+		}, nil
+	case "environment":
+		var ej environmentMapJSON
+		if err = json.Unmarshal(data, &ej); err != nil {
+			return nil, err
+		}
+		em, err := NewEnvironmentMap(ej.Path)
+		if err != nil {
+			return nil, err
+		}
+		em.Intensity = ej.Intensity
+		return em, nil
+	default:
+		return nil, fmt.Errorf("unknown background type %q", typ)
+	}
+}
+
+func encodeLight(l Light) (json.RawMessage, error) {
+	switch light := l.(type) {
+	case DirectionalLight:
+		return json.Marshal(directionalLightJSON{
+			Type: "directional", Direction: light.Direction, Color: light.Color,
+		})
+	case PointLight:
+		return json.Marshal(pointLightJSON{
+			Type: "point", Position: light.Position, Color: light.Color,
+			Intensity: light.Intensity,
+		})
+	case SpotLight:
+		return json.Marshal(spotLightJSON{
+			Type: "spot", Position: light.Position, Direction: light.Direction,
+			Color: light.Color, Intensity: light.Intensity, ConeAngle: light.ConeAngle,
+			Falloff: light.Falloff,
+		})
+	default:
+		return nil, fmt.Errorf("can't encode light of type %T", l)
+	}
+}
+
+func decodeLight(data json.RawMessage) (Light, error) {
+	typ, err := decodeType(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid light: %w", err)
+	}
+	switch typ {
+	case "directional":
+		var dj directionalLightJSON
+		if err = json.Unmarshal(data, &dj); err != nil {
+			return nil, err
+		}
+		return DirectionalLight{Direction: dj.Direction, Color: dj.Color}, nil
+	case "point":
+		var pj pointLightJSON
+		if err = json.Unmarshal(data, &pj); err != nil {
+			return nil, err
+		}
+		return PointLight{Position: pj.Position, Color: pj.Color, Intensity: pj.Intensity}, nil
+	case "spot":
+		var sj spotLightJSON
+		if err = json.Unmarshal(data, &sj); err != nil {
+			return nil, err
+		}
+		return SpotLight{
+			Position: sj.Position, Direction: sj.Direction, Color: sj.Color,
+			Intensity: sj.Intensity, ConeAngle: sj.ConeAngle, Falloff: sj.Falloff,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown light type %q", typ)
+	}
+}
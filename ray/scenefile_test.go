@@ -0,0 +1,259 @@
+package ray
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func testFileCamera() *Camera {
+	return &Camera{
+		Position:       Vec3{-2, 2, 1},
+		LookAt:         Vec3{0, 0, -1},
+		Up:             Vec3{0, 1, 0},
+		VerticalFoV:    20,
+		Aperture:       0.1,
+		ApertureBlades: 6,
+		FocusDistance:  3.4,
+	}
+}
+
+func TestSaveLoadSceneRoundTrip(t *testing.T) {
+	scene := DefaultScene()
+	ceilingLight := NewQuad(Vec3{-1, 2, -3}, Vec3{0, 0, 2}, Vec3{2, 0, 0}, DiffuseLight{Emit: ColorF{4, 4, 4}})
+	ceilingLight.OneSided = true
+	scene.Objects = append(scene.Objects,
+		NewQuad(Vec3{-1, -1, -3}, Vec3{2, 0, 0}, Vec3{0, 2, 0}, DiffuseLight{Emit: ColorF{4, 4, 4}}),
+		ceilingLight,
+		NewBox(Vec3{0, 0, -2}, Vec3{0.3, 0.3, -2.3}, Metal{Albedo: ColorF{0.5, 0.6, 0.7}, Fuzz: 0.1, Roughness: 0.3}),
+		NewSphere(Vec3{0, 1, -1}, 0.1, Lambertian{Tex: CheckerTexture{
+			Scale: 0.3, Even: ColorF{1, 1, 1}, Odd: ColorF{0.1, 0.2, 0.3},
+		}}),
+		NewSphere(Vec3{0, 2, -1}, 0.1, Lambertian{Tex: SolidColor{Albedo: ColorF{0.25, 0.5, 0.75}}}),
+		NewSphere(Vec3{0, 3, -1}, 0.1, Lambertian{Albedo: ColorF{0.5, 0.5, 0.5}, BookScatter: true}),
+		NewDisk(Vec3{2, 1, -3}, Vec3{0, 0, 1}, 0.5, Lambertian{Albedo: ColorF{0.2, 0.4, 0.6}}),
+		NewPlane(Vec3{0, -0.6, 0}, Vec3{0, 1, 0}, Metal{Albedo: ColorF{0.5, 0.5, 0.5}}),
+		NewSphere(Vec3{0, 6, -1}, 0.1, Dielectric{RefIdx: 1.5, Roughness: 0.2, Tint: ColorF{0.9, 1, 0.9}, Dispersion: 0.01}),
+		NewSphere(Vec3{0, 5, -1}, 0.1, MixMaterial{A: Lambertian{Albedo: ColorF{0.8, 0.1, 0.1}}, B: Metal{Albedo: ColorF{0.9, 0.9, 0.9}}, Ratio: 0.2}),
+		NewSphere(Vec3{0, 4, -1}, 0.1, Metal{Albedo: ColorF{0.9, 0.9, 0.9}, NormalMap: SolidColor{Albedo: ColorF{0.6, 0.5, 0.9}}}),
+		NewSphere(Vec3{0, 7, -1}, 0.1, Metal{Tex: CheckerTexture{Scale: 0.2, Even: ColorF{0.8, 0.4, 0.2}, Odd: ColorF{0.9, 0.9, 0.9}}, Roughness: 0.1}),
+		&Translate{Object: NewRotateY(NewBox(Vec3{0, 0, 0}, Vec3{1, 1, 1}, Lambertian{}), 15), Offset: Vec3{1, 0, -4}},
+		&MovingSphere{Center: Vec3{1, 2, -1}, Center1: Vec3{1, 2.5, -1}, Radius: 0.2, Mat: Dielectric{RefIdx: 1.3, Absorption: ColorF{0, 0.2, 0.4}}},
+		NewEllipsoid(Vec3{-2, 1, -2}, Vec3{0.5, 0.2, 0.3}, Lambertian{Albedo: ColorF{0.7, 0.3, 0.1}}),
+	)
+	// Textures loaded from an image file and generated from a seed.
+	imageFile := filepath.Join(t.TempDir(), "texture.png")
+	if err := SaveImage(testImage(), imageFile, 0); err != nil {
+		t.Fatal(err)
+	}
+	imageTex, err := NewImageTexture(imageFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	imageTex.Wrap = true
+	marble := NewNoiseTextureSeed(42, 4, 5)
+	marble.Marble = true
+	scene.Objects = append(scene.Objects,
+		NewSphere(Vec3{-1, 1, -1}, 0.2, Lambertian{Tex: imageTex}),
+		NewSphere(Vec3{-1, 2, -1}, 0.2, Lambertian{Tex: NewNoiseTexture(RandForTests(), 2, 0)}),
+		NewSphere(Vec3{-1, 3, -1}, 0.2, Metal{Tex: marble, Albedo: ColorF{1, 1, 1}}),
+	)
+	scene.Lights = []Light{
+		DirectionalLight{Direction: Vec3{1, -2, 0.5}, Color: ColorF{0.9, 0.8, 0.7}},
+		PointLight{Position: Vec3{0, 3, 0}, Color: ColorF{1, 1, 0.9}, Intensity: 5},
+		SpotLight{
+			Position: Vec3{1, 3, 1}, Direction: Vec3{0, -1, 0}, Color: ColorF{1, 1, 1},
+			Intensity: 10, ConeAngle: 30, Falloff: 5,
+		},
+	}
+	scene.Emitters = []Emitter{ceilingLight}
+	camera := testFileCamera()
+	camera.Time1 = 1
+	var buf bytes.Buffer
+	if err := SaveScene(&buf, scene, camera); err != nil {
+		t.Fatalf("SaveScene() error: %v", err)
+	}
+	loaded, loadedCamera, err := LoadScene(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("LoadScene() error: %v\n%s", err, buf.String())
+	}
+	if !reflect.DeepEqual(loaded, scene) {
+		t.Errorf("Loaded scene differs:\n%+v\nvs\n%+v", loaded, scene)
+	}
+	if *loadedCamera != *camera {
+		t.Errorf("Loaded camera %+v, want %+v", loadedCamera, camera)
+	}
+	idx := slices.Index(scene.Objects, Hittable(ceilingLight))
+	if len(loaded.Emitters) != 1 || loaded.Emitters[0] != loaded.Objects[idx] {
+		t.Errorf("Loaded emitters %v should be the ceiling light of the loaded objects", loaded.Emitters)
+	}
+	// Saving again gives the same json.
+	var buf2 bytes.Buffer
+	if err := SaveScene(&buf2, loaded, loadedCamera); err != nil {
+		t.Fatalf("SaveScene() error: %v", err)
+	}
+	if buf.String() != buf2.String() {
+		t.Errorf("Second save differs:\n%s\nvs\n%s", buf.String(), buf2.String())
+	}
+}
+
+func TestSaveLoadSceneIdenticalRender(t *testing.T) {
+	render := func(scene *Scene, camera *Camera) []byte {
+		tracer := New(16, 9)
+		tracer.Seed = 42
+		tracer.NumWorkers = 1
+		tracer.NumRaysPerPixel = 4
+		tracer.Camera = *camera
+		return tracer.Render(scene).Pix
+	}
+	scene := DefaultScene()
+	var buf bytes.Buffer
+	if err := SaveScene(&buf, scene, testFileCamera()); err != nil {
+		t.Fatalf("SaveScene() error: %v", err)
+	}
+	loaded, camera, err := LoadScene(&buf)
+	if err != nil {
+		t.Fatalf("LoadScene() error: %v", err)
+	}
+	if !bytes.Equal(render(scene, testFileCamera()), render(loaded, camera)) {
+		t.Error("Loaded scene renders differently than the original")
+	}
+	// With Emitters (importance sampled lights).
+	scene, sceneCamera := EmittersScene()
+	buf.Reset()
+	if err = SaveScene(&buf, scene, sceneCamera); err != nil {
+		t.Fatalf("SaveScene() error: %v", err)
+	}
+	if loaded, camera, err = LoadScene(&buf); err != nil {
+		t.Fatalf("LoadScene() error: %v", err)
+	}
+	if len(loaded.Emitters) != len(scene.Emitters) {
+		t.Errorf("Loaded %d emitters, want %d", len(loaded.Emitters), len(scene.Emitters))
+	}
+	if !bytes.Equal(render(scene, sceneCamera), render(loaded, camera)) {
+		t.Error("Loaded emitters scene renders differently than the original")
+	}
+}
+
+func TestSaveLoadSceneBackgrounds(t *testing.T) {
+	sunny := DefaultBackground()
+	sunny.SunDirection, sunny.SunRadius, sunny.SunColor = Vec3{1, 2, 0}, 0.5, ColorF{30, 28, 25}
+	for _, bg := range []Background{nil, NoBackground, DefaultBackground(), sunny} {
+		var buf bytes.Buffer
+		if err := SaveScene(&buf, &Scene{Background: bg}, nil); err != nil {
+			t.Fatalf("SaveScene() error: %v", err)
+		}
+		loaded, camera, err := LoadScene(&buf)
+		if err != nil {
+			t.Fatalf("LoadScene() error: %v", err)
+		}
+		if loaded.Background != bg {
+			t.Errorf("Background = %v, want %v", loaded.Background, bg)
+		}
+		if camera != nil {
+			t.Errorf("Expected nil camera, got %v", camera)
+		}
+	}
+}
+
+func TestLoadSceneVectorObjects(t *testing.T) {
+	json := `{"objects": [{"type": "sphere", "center": {"x": 1, "y": 2, "z": -3}, "radius": 0.5,
+		"material": {"type": "lambertian", "albedo": {"r": 0.1, "g": 0.2, "b": 0.3}}}]}`
+	scene, _, err := LoadScene(strings.NewReader(json))
+	if err != nil {
+		t.Fatalf("LoadScene() error: %v", err)
+	}
+	want := NewSphere(Vec3{1, 2, -3}, 0.5, Lambertian{Albedo: ColorF{0.1, 0.2, 0.3}})
+	if len(scene.Objects) != 1 || !reflect.DeepEqual(scene.Objects[0], want) {
+		t.Errorf("Loaded objects %+v, want %+v", scene.Objects, want)
+	}
+}
+
+func TestLoadSceneErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+	}{
+		{"invalid json", `{"objects": [`},
+		{"unknown object", `{"objects": [{"type": "teapot"}]}`},
+		{"unknown material", `{"objects": [{"type": "sphere", "radius": 1, "material": {"type": "cheese"}}]}`},
+		{"missing material", `{"objects": [{"type": "sphere", "radius": 1}]}`},
+		{"unknown texture", `{"objects": [{"type": "sphere", "material": {"type": "lambertian", "texture": {"type": "x"}}}]}`},
+		{"unknown background", `{"background": {"type": "stars"}, "objects": []}`},
+		{"unknown light", `{"lights": [{"type": "candle"}], "objects": []}`},
+		{"bad translate", `{"objects": [{"type": "translate", "object": {"type": "nope"}}]}`},
+		{"bad rotate", `{"objects": [{"type": "rotate_y", "angle": 10}]}`},
+		{"bad group", `{"objects": [{"type": "group", "objects": [{"type": "nope"}]}]}`},
+		{"shutter", `{"camera": {"position": [0, 0, 0], "look_at": [0, 0, -1], "up": [0, 1, 0], "time1": 2}, "objects": []}`},
+		{"bad vector", `{"objects": [{"type": "sphere", "center": [1, 2], "radius": 1, "material": {"type": "lambertian"}}]}`},
+		{"missing image", `{"objects": [{"type": "sphere", "material": {"type": "lambertian", "texture": {"type": "image", "path": "nope.png"}}}]}`},
+		{"noise seed", `{"objects": [{"type": "sphere", "material": {"type": "lambertian", "texture": {"type": "noise", "scale": 1}}}]}`},
+		{"emitter index", `{"objects": [], "emitters": [0]}`},
+		{"not an emitter", `{"objects": [{"type": "group", "objects": []}], "emitters": [0]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := LoadScene(strings.NewReader(tt.json)); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+}
+
+func TestSaveSceneUnsupported(t *testing.T) {
+	tests := []struct {
+		name  string
+		scene *Scene
+	}{
+		{"texture", &Scene{Objects: []Hittable{
+			&Sphere{Radius: 1, Mat: Lambertian{Tex: &ImageTexture{}}},
+		}}},
+		{"noise texture", &Scene{Objects: []Hittable{
+			&Sphere{Radius: 1, Mat: Lambertian{Tex: &NoiseTexture{Noise: NewPerlin(RandForTests())}}},
+		}}},
+		{"material", &Scene{Objects: []Hittable{&Sphere{Radius: 1, Mat: &Lambertian{}}}}},
+		{"translated", &Scene{Objects: []Hittable{&Translate{Object: &Sphere{Radius: 1}}}}},
+		{"rotated", &Scene{Objects: []Hittable{NewRotateY(&Sphere{Radius: 1}, 30)}}},
+		{"group member", &Scene{Objects: []Hittable{&Scene{Objects: []Hittable{&Sphere{Radius: 1}}}}}},
+		{"light", &Scene{Lights: []Light{&DirectionalLight{}}}},
+		{"emitter not an object", &Scene{Emitters: []Emitter{&Sphere{Radius: 1}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := SaveScene(&bytes.Buffer{}, tt.scene, nil); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+}
+
+func TestLoadSceneFile(t *testing.T) {
+	fname := filepath.Join(t.TempDir(), "scene.json")
+	var buf bytes.Buffer
+	if err := SaveScene(&buf, DefaultScene(), nil); err != nil {
+		t.Fatalf("SaveScene() error: %v", err)
+	}
+	if err := os.WriteFile(fname, buf.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	scene, _, err := LoadSceneFile(fname)
+	if err != nil {
+		t.Fatalf("LoadSceneFile() error: %v", err)
+	}
+	if len(scene.Objects) != len(DefaultScene().Objects) {
+		t.Errorf("Loaded %d objects, want %d", len(scene.Objects), len(DefaultScene().Objects))
+	}
+	if _, _, err := LoadSceneFile(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("Expected error for missing file")
+	}
+	if err := os.WriteFile(fname, []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := LoadSceneFile(fname); err == nil {
+		t.Error("Expected error for invalid file")
+	}
+}
@@ -0,0 +1,134 @@
+package ray
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"fortio.org/log"
+	"fortio.org/rand"
+)
+
+// SceneFunc builds a scene and its camera, using rng for any random placement.
+type SceneFunc func(rng rand.Rand) (*Scene, *Camera)
+
+// Scenes is the registry of built-in scenes by name, e.g. for a command line flag
+// (see BuiltinScene). Programs can register their own.
+var Scenes = map[string]SceneFunc{
+	"default": func(_ rand.Rand) (*Scene, *Camera) {
+		return DefaultScene(), DefaultSceneCamera()
+	},
+	"rich": func(rng rand.Rand) (*Scene, *Camera) {
+		return RichScene(rng), RichSceneCamera()
+	},
+	"checker":  CheckerScene,
+	"cornell":  func(_ rand.Rand) (*Scene, *Camera) { return CornellBox() },
+	"emitters": func(_ rand.Rand) (*Scene, *Camera) { return EmittersScene() },
+}
+
+// SceneNames returns the sorted names of the registered Scenes.
+func SceneNames() []string {
+	return slices.Sorted(maps.Keys(Scenes))
+}
+
+// BuiltinScene returns the registered scene with the given name and its camera.
+func BuiltinScene(name string, rng rand.Rand) (*Scene, *Camera, error) {
+	f, ok := Scenes[name]
+	if !ok {
+		return nil, nil, fmt.Errorf("unknown scene %q, should be one of %v", name, SceneNames())
+	}
+	scene, camera := f(rng)
+	return scene, camera, nil
+}
+
+// LoadNamedScene returns the scene and camera registered as name in Scenes (generated
+// using rng), or else loaded from the name JSON file (see LoadSceneFile), framed
+// automatically (see Camera.Frame) for a width x height image when it has no camera.
+// The camera is never nil.
+func LoadNamedScene(name string, rng rand.Rand, width, height int) (*Scene, *Camera, error) {
+	if _, builtin := Scenes[name]; builtin || !strings.HasSuffix(strings.ToLower(name), ".json") {
+		scene, camera, err := BuiltinScene(name, rng)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w (or a .json scene file)", err)
+		}
+		return scene, camera, nil
+	}
+	scene, camera, err := LoadSceneFile(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	log.Infof("Loaded scene from %q: %d objects", name, len(scene.Objects))
+	if camera == nil {
+		// No camera in the file: frame the whole scene.
+		camera = &Camera{VerticalFoV: 40}
+		camera.Frame(scene.FiniteBoundingBox(), 0.1, width, height)
+	}
+	return scene, camera, nil
+}
+
+// CheckerScene is two big checkered spheres, one above the other, seen from RichSceneCamera's
+// position (without depth of field), to check texture mapping.
+func CheckerScene(_ rand.Rand) (*Scene, *Camera) {
+	checker := Lambertian{Tex: CheckerTexture{Scale: 0.32, Even: ColorF{0.2, 0.3, 0.1}, Odd: ColorF{0.9, 0.9, 0.9}}}
+	scene := &Scene{
+		Objects: []Hittable{
+			NewSphere(Vec3{0, -10, 0}, 10, checker),
+			NewSphere(Vec3{0, 10, 0}, 10, checker),
+		},
+		Background: DefaultBackground(),
+	}
+	camera := NewCamera(Vec3{13, 2, 3}, Vec3{0, 0, 0}, Vec3{0, 1, 0}, 20)
+	return scene, camera
+}
+
+// CornellBox is the classic 555 units wide box with a red left wall, a green right wall,
+// white floor, ceiling and back wall, a square light in the ceiling and two rotated boxes.
+// It's only lit by the ceiling light, which is also an Emitter for light sampling.
+func CornellBox() (*Scene, *Camera) {
+	red := Lambertian{Albedo: ColorF{.65, .05, .05}}
+	white := Lambertian{Albedo: ColorF{.73, .73, .73}}
+	green := Lambertian{Albedo: ColorF{.12, .45, .15}}
+	light := NewQuad(Vec3{343, 554, 332}, Vec3{-130, 0, 0}, Vec3{0, 0, -105}, DiffuseLight{Emit: ColorF{15, 15, 15}})
+	light.OneSided = true // facing down
+	tall := &Translate{Object: NewRotateY(NewBox(Vec3{0, 0, 0}, Vec3{165, 330, 165}, white), 15), Offset: Vec3{265, 0, 295}}
+	short := &Translate{Object: NewRotateY(NewBox(Vec3{0, 0, 0}, Vec3{165, 165, 165}, white), -18), Offset: Vec3{130, 0, 65}}
+	scene := &Scene{
+		Objects: []Hittable{
+			// The camera looks toward +z so +x is on the left.
+			NewQuad(Vec3{555, 0, 0}, Vec3{0, 555, 0}, Vec3{0, 0, 555}, red),
+			NewQuad(Vec3{0, 0, 0}, Vec3{0, 555, 0}, Vec3{0, 0, 555}, green),
+			NewQuad(Vec3{0, 0, 0}, Vec3{555, 0, 0}, Vec3{0, 0, 555}, white),
+			NewQuad(Vec3{555, 555, 555}, Vec3{-555, 0, 0}, Vec3{0, 0, -555}, white),
+			NewQuad(Vec3{0, 0, 555}, Vec3{555, 0, 0}, Vec3{0, 555, 0}, white),
+			light,
+			tall,
+			short,
+		},
+		Background: NoBackground,
+		Emitters:   []Emitter{light},
+	}
+	camera := NewCamera(Vec3{278, 278, -800}, Vec3{278, 278, 0}, Vec3{0, 1, 0}, 40)
+	return scene, camera
+}
+
+// EmittersScene is a white floor lit only by emitters of very different sizes: a small and
+// bright sphere and a large and dim back wall, to compare the Tracer.SamplingStrategy
+// options: sampling the emitters is best for the small one, the diffuse (cosine) sampling
+// for the floor right in front of the wall, and their mix (SamplingMIS) good for both.
+func EmittersScene() (*Scene, *Camera) {
+	white := Lambertian{Albedo: ColorF{0.8, 0.8, 0.8}}
+	bulb := NewSphere(Vec3{-1.5, 1.2, -2}, 0.05, DiffuseLight{Emit: ColorF{400, 300, 200}})
+	wall := NewQuad(Vec3{-4, 0, -4}, Vec3{8, 0, 0}, Vec3{0, 3, 0}, DiffuseLight{Emit: ColorF{1, 1.4, 2}})
+	scene := &Scene{
+		Objects: []Hittable{
+			NewQuad(Vec3{-4, 0, 2}, Vec3{8, 0, 0}, Vec3{0, 0, -6}, white),
+			bulb,
+			wall,
+		},
+		Background: NoBackground,
+		Emitters:   []Emitter{bulb, wall},
+	}
+	camera := NewCamera(Vec3{0, 2, 3}, Vec3{0, 0.3, -2}, Vec3{0, 1, 0}, 50)
+	return scene, camera
+}
@@ -0,0 +1,105 @@
+package ray
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestScenesRender(t *testing.T) {
+	names := SceneNames()
+	if !slices.IsSorted(names) || !slices.Contains(names, "rich") {
+		t.Fatalf("SceneNames() = %v, want sorted and including rich", names)
+	}
+	for _, name := range names {
+		t.Run(name, func(t *testing.T) {
+			scene, camera, err := BuiltinScene(name, RandForTests())
+			if err != nil {
+				t.Fatalf("BuiltinScene(%q) error: %v", name, err)
+			}
+			if len(scene.Objects) == 0 || camera == nil {
+				t.Fatalf("BuiltinScene(%q) = %d objects, camera %v", name, len(scene.Objects), camera)
+			}
+			rt := New(8, 6)
+			rt.NumRaysPerPixel = 1
+			rt.MaxDepth = 4
+			rt.Camera = *camera
+			img := rt.Render(scene)
+			black := true
+			for i := 0; i < len(img.Pix); i += 4 {
+				if img.Pix[i]|img.Pix[i+1]|img.Pix[i+2] != 0 {
+					black = false
+					break
+				}
+			}
+			if black {
+				t.Errorf("scene %q rendered all black", name)
+			}
+		})
+	}
+}
+
+func TestBuiltinSceneUnknown(t *testing.T) {
+	if _, _, err := BuiltinScene("nope", RandForTests()); err == nil {
+		t.Errorf("BuiltinScene(nope) error = nil, want unknown scene error")
+	}
+}
+
+func TestLoadNamedScene(t *testing.T) {
+	scene, camera, err := LoadNamedScene("cornell", RandForTests(), 40, 30)
+	if err != nil || len(scene.Objects) == 0 || camera == nil {
+		t.Fatalf("LoadNamedScene(cornell) = %v objects, camera %v, error %v", scene, camera, err)
+	}
+	if _, _, err = LoadNamedScene("nope", RandForTests(), 40, 30); err == nil {
+		t.Error("LoadNamedScene(nope) should fail")
+	}
+	if _, _, err = LoadNamedScene(filepath.Join(t.TempDir(), "missing.json"), RandForTests(), 40, 30); err == nil {
+		t.Error("LoadNamedScene(missing.json) should fail")
+	}
+	// A file without a camera gets one framing the scene.
+	fname := filepath.Join(t.TempDir(), "scene.JSON")
+	f, err := os.Create(fname)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = SaveScene(f, &Scene{Objects: []Hittable{NewSphere(Vec3{1, 2, 3}, 1, Lambertian{})}}, nil); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	scene, camera, err = LoadNamedScene(fname, RandForTests(), 40, 30)
+	if err != nil {
+		t.Fatalf("LoadNamedScene(%q) error: %v", fname, err)
+	}
+	if len(scene.Objects) != 1 || camera == nil || camera.LookAt != (Vec3{1, 2, 3}) {
+		t.Errorf("LoadNamedScene(%q) = %d objects, camera %+v, want 1 and framed on the sphere", fname, len(scene.Objects), camera)
+	}
+}
+
+func TestCornellBoxWalls(t *testing.T) {
+	scene, camera := CornellBox()
+	const w, h = 24, 24
+	rt := New(w, h)
+	rt.NumRaysPerPixel = 16
+	rt.KeepHDR = true
+	rt.Camera = *camera
+	rt.Render(scene)
+	hdr := rt.HDRBuffer()
+	// Average of the two outer columns, at mid height, on each side.
+	side := func(x0 int) ColorF {
+		var sum ColorF
+		for y := h / 3; y < 2*h/3; y++ {
+			for x := x0; x < x0+2; x++ {
+				sum = Add(sum, hdr[y*w+x])
+			}
+		}
+		return sum
+	}
+	left, right := side(0), side(w-2)
+	if left.X() <= 2*left.Y() || left.X() <= 2*left.Z() {
+		t.Errorf("left wall = %v, want red", left)
+	}
+	if right.Y() <= 2*right.X() || right.Y() <= 2*right.Z() {
+		t.Errorf("right wall = %v, want green", right)
+	}
+}
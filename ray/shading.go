@@ -0,0 +1,118 @@
+package ray
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Shading selects how the Tracer colors the camera rays: full path tracing or one of the
+// quick preview modes, which only look at the first hit (no bounces nor lights) and so
+// render nearly instantly, e.g. to position the camera before a full render.
+type Shading int
+
+const (
+	// ShadingFull path traces the scene (default).
+	ShadingFull Shading = iota
+	// ShadingNormals colors the first hit by its normal (facing the ray), mapped from
+	// [-1,1] to [0,1] per component: x to red, y to green, z to blue. Black for misses.
+	ShadingNormals
+	// ShadingFlat is a single bounce Lambert shading of the first hit with a headlight (a
+	// light at the camera), using the diffuse albedo or emitted color of the material
+	// (light gray for the others). Black for misses.
+	ShadingFlat
+	// ShadingDepth is a grayscale depth map: 1/(1+d/f) for a hit at distance d along the
+	// ray, with f the distance from the camera to its LookAt point (so things there are
+	// mid gray, closer ones lighter). Black for misses.
+	ShadingDepth
+	// ShadingAO is ambient occlusion: the first hit is gray by the fraction of Tracer.AOSamples
+	// cosine distributed rays, from it over its hemisphere, that escape farther than
+	// Tracer.AORadius. Open surfaces are white, creases and contacts darker. White for misses.
+	ShadingAO
+)
+
+// Defaults for the ShadingAO Tracer settings.
+const (
+	DefaultAORadius  = 1.0
+	DefaultAOSamples = 16
+)
+
+var shadingNames = []string{"full", "normals", "flat", "depth", "ao"}
+
+func (s Shading) String() string {
+	if s < 0 || int(s) >= len(shadingNames) {
+		return fmt.Sprintf("Shading(%d)", int(s))
+	}
+	return shadingNames[s]
+}
+
+// ParseShading returns the Shading for the given name (case insensitive):
+// one of "full", "normals", "flat", "depth" or "ao".
+func ParseShading(name string) (Shading, error) {
+	for i, n := range shadingNames {
+		if strings.EqualFold(name, n) {
+			return Shading(i), nil
+		}
+	}
+	return ShadingFull, fmt.Errorf("unknown shading %q, should be one of %v", name, shadingNames)
+}
+
+// previewColor returns the color of the camera ray r for the preview Shading modes.
+func (t *Tracer) previewColor(r *Ray, scene *Scene, scratch *pathScratch) ColorF {
+	scratch.paths++
+	scratch.rays++
+	hr := &scratch.hr
+	if !scene.Hit(r, Interval{Start: t.ShadowEpsilon, End: math.Inf(1)}, hr) {
+		if t.Shading == ShadingAO {
+			return ColorF{1, 1, 1}
+		}
+		return ColorF{}
+	}
+	scratch.hits++
+	switch t.Shading {
+	case ShadingAO:
+		g := t.ambientOcclusion(r, scene, scratch)
+		return ColorF{g, g, g}
+	case ShadingNormals:
+		n := hr.Normal
+		return ColorF{0.5 * (n.x + 1), 0.5 * (n.y + 1), 0.5 * (n.z + 1)}
+	case ShadingDepth:
+		f := Length(Sub(t.LookAt, t.Position))
+		if f == 0 {
+			f = 1
+		}
+		g := 1 / (1 + hr.T*Length(r.Direction)/f)
+		return ColorF{g, g, g}
+	default: // ShadingFlat
+		albedo := ColorF{0.8, 0.8, 0.8}
+		if dm, ok := hr.Mat.(DiffuseMaterial); ok {
+			albedo = dm.DiffuseAlbedo(hr)
+		} else if e := hr.Mat.Emitted(); e != (ColorF{}) {
+			return e
+		}
+		return SMul(albedo, math.Max(0, -Dot(hr.Normal, Unit(r.Direction))))
+	}
+}
+
+// ambientOcclusion returns the fraction of the AO rays from the hit in scratch.hr that
+// don't hit anything within AORadius. With cosine distributed directions, that's the
+// cosine weighted unoccluded fraction of the hemisphere.
+func (t *Tracer) ambientOcclusion(r *Ray, scene *Scene, scratch *pathScratch) float64 {
+	radius, n := t.AORadius, t.AOSamples
+	if radius <= 0 {
+		radius = DefaultAORadius
+	}
+	if n <= 0 {
+		n = DefaultAOSamples
+	}
+	hr, aoHr, aoRay := &scratch.hr, &scratch.shadowHr, &scratch.shadowRay
+	escaped := 0
+	for range n {
+		*aoRay = Ray{Rand: r.Rand, Origin: hr.Point, Direction: RandomCosineDirection(r.Rand, hr.Normal), Time: r.Time, Channel: r.Channel}
+		scratch.shadowRays++
+		if !scene.Hit(aoRay, Interval{Start: t.ShadowEpsilon, End: radius}, aoHr) {
+			escaped++
+		}
+	}
+	return float64(escaped) / float64(n)
+}
@@ -0,0 +1,129 @@
+package ray
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseShading(t *testing.T) {
+	for _, s := range []Shading{ShadingFull, ShadingNormals, ShadingFlat, ShadingDepth, ShadingAO} {
+		parsed, err := ParseShading(s.String())
+		if err != nil || parsed != s {
+			t.Errorf("ParseShading(%q) = %v, %v; want %v", s.String(), parsed, err, s)
+		}
+	}
+	if s, err := ParseShading("Normals"); err != nil || s != ShadingNormals {
+		t.Errorf("ParseShading(\"Normals\") = %v, %v; want normals", s, err)
+	}
+	if _, err := ParseShading("wireframe"); err == nil {
+		t.Error("Expected error for unknown shading")
+	}
+	if s := Shading(7).String(); s != "Shading(7)" {
+		t.Errorf("String() = %q, want \"Shading(7)\"", s)
+	}
+}
+
+// shadingRender renders scene with the given shading mode and returns the linear colors.
+func shadingRender(scene *Scene, shading Shading, w, h int) []ColorF {
+	rt := New(w, h)
+	rt.Shading = shading
+	rt.KeepHDR = true
+	rt.Camera = Camera{LookAt: Vec3{0, 0, -1}}
+	rt.Render(scene)
+	return rt.HDRBuffer()
+}
+
+func TestShadingNormals(t *testing.T) {
+	const size = 21
+	scene := &Scene{Objects: []Hittable{NewSphere(Vec3{0, 0, -1}, 0.5, Lambertian{Albedo: ColorF{1, 0, 0}})}}
+	img := shadingRender(scene, ShadingNormals, size, size)
+	// The center of the sphere faces the camera (+z).
+	if c := img[size/2*size+size/2]; !Equal(c, ColorF{0.5, 0.5, 1}, 1e-12) {
+		t.Errorf("center pixel = %v, want %v", c, ColorF{0.5, 0.5, 1})
+	}
+	hits := 0
+	for y := range size {
+		for x := range size {
+			c := img[y*size+x]
+			if c == (ColorF{}) {
+				continue // missed the sphere
+			}
+			hits++
+			n := Vec3{2*c.x - 1, 2*c.y - 1, 2*c.z - 1}
+			if math.Abs(Length(n)-1) > 1e-9 || n.z < 0 {
+				t.Errorf("pixel %d,%d = %v isn't a normal of the visible hemisphere: %v", x, y, c, n)
+			}
+			// Right of the image is +x, top is +y.
+			dx, dy := x-size/2, size/2-y
+			if dx*int(math.Copysign(1, n.x)) < 0 || dy*int(math.Copysign(1, n.y)) < 0 {
+				t.Errorf("pixel %d,%d normal %v points the wrong way", x, y, n)
+			}
+		}
+	}
+	// The sphere covers 30° of the 90° field of view: a disk of radius ~size/2*tan(30°).
+	r := size / 2 * math.Tan(math.Pi/6)
+	if want := math.Pi * r * r; math.Abs(float64(hits)-want) > 0.15*want {
+		t.Errorf("%d pixels hit the sphere, want about %.0f", hits, want)
+	}
+}
+
+func TestShadingFlatAndDepth(t *testing.T) {
+	// A wall facing the camera, 2 away, so twice the LookAt distance.
+	wall := NewQuad(Vec3{-10, -10, -2}, Vec3{20, 0, 0}, Vec3{0, 20, 0}, Lambertian{Albedo: ColorF{0.2, 0.4, 0.6}})
+	scene := &Scene{Objects: []Hittable{wall, NewSphere(Vec3{0, 0, -1.5}, 0.1, DiffuseLight{Emit: ColorF{4, 4, 4}})}}
+	const size = 9
+	center := size/2*size + size/2
+	flat := shadingRender(scene, ShadingFlat, size, size)
+	if c := flat[center]; c != (ColorF{4, 4, 4}) {
+		t.Errorf("flat light = %v, want its emitted color", c)
+	}
+	corner := flat[0]
+	d := 1 - 1.0/size // center of the corner pixel on the viewport at distance 1
+	if want := SMul(ColorF{0.2, 0.4, 0.6}, 1/math.Sqrt(1+2*d*d)); !Equal(corner, want, 1e-9) {
+		t.Errorf("flat wall corner = %v, want albedo times cosine %v", corner, want)
+	}
+	depth := shadingRender(&Scene{Objects: []Hittable{wall}}, ShadingDepth, size, size)
+	if c := depth[center]; !Equal(c, ColorF{1.0 / 3, 1.0 / 3, 1.0 / 3}, 1e-9) {
+		t.Errorf("depth at twice the LookAt distance = %v, want 1/3 gray", c)
+	}
+	if depth[0].x >= depth[center].x {
+		t.Errorf("depth corner %v should be darker (farther) than the center %v", depth[0], depth[center])
+	}
+	if c := shadingRender(&Scene{}, ShadingDepth, 1, 1)[0]; c != (ColorF{}) {
+		t.Errorf("depth of a miss = %v, want black", c)
+	}
+}
+
+func TestShadingAOContact(t *testing.T) {
+	// A unit sphere sitting on the ground plane, at the origin.
+	scene := &Scene{Objects: []Hittable{
+		NewPlane(Vec3{}, Vec3{0, 1, 0}, Lambertian{}),
+		NewSphere(Vec3{0, 1, 0}, 1, Lambertian{}),
+	}}
+	rt := New(1, 1)
+	rt.Shading = ShadingAO
+	rt.AOSamples = 1024
+	rt.ShadowEpsilon = DefaultShadowEpsilon
+	rng := RandForTests()
+	// ao returns the occlusion of the ground seen from straight above at x.
+	ao := func(x float64) float64 {
+		return rt.trace(NewRay(rng, Vec3{x, 5, 0}, Vec3{0, -1, 0}), scene, &pathScratch{}, 0).x
+	}
+	if g := ao(10); g != 1 {
+		t.Errorf("AO of the open ground = %v, want 1 (white)", g)
+	}
+	if g := ao(0.5); g != 1 {
+		t.Errorf("AO of the top of the sphere = %v, want 1 (white)", g)
+	}
+	near, mid := ao(1.05), ao(1.5)
+	if near > 0.75 || near >= mid || mid >= 1 {
+		t.Errorf("AO near the contact = %v, farther = %v: want darker closer to the sphere", near, mid)
+	}
+	if g := rt.trace(NewRay(rng, Vec3{0, 5, 0}, Vec3{0, 1, 0}), scene, &pathScratch{}, 0); g != (ColorF{1, 1, 1}) {
+		t.Errorf("AO of a miss = %v, want white", g)
+	}
+	rt.AORadius = 0.01
+	if g := ao(1.5); g != 1 {
+		t.Errorf("AO with a radius smaller than the gap = %v, want 1", g)
+	}
+}
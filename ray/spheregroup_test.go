@@ -0,0 +1,116 @@
+package ray
+
+import (
+	"testing"
+
+	"fortio.org/rand"
+)
+
+func randomSpheres(rng rand.Rand, n int) []*Sphere {
+	spheres := make([]*Sphere, 0, n)
+	for range n {
+		spheres = append(spheres, NewSphere(RandomInRange(rng, Interval{-5, 5}), 0.1+0.4*rng.Float64(), Lambertian{Albedo: Random(rng)}))
+	}
+	return spheres
+}
+
+func TestSphereGroupSameAsList(t *testing.T) {
+	rng := RandForTests()
+	spheres := randomSpheres(rng, 100)
+	spheres[0] = &Sphere{Center: Vec3{1, 2, 3}, Radius: 0.5, Mat: Lambertian{}} // literal, without precomputed constants
+	objects := make([]Hittable, 0, len(spheres))
+	for _, s := range spheres {
+		objects = append(objects, s)
+	}
+	list := &Scene{Objects: objects}
+	group := NewSphereGroup(spheres)
+	if group.Len() != len(spheres) {
+		t.Errorf("Len() = %d, want %d", group.Len(), len(spheres))
+	}
+	if group.BoundingBox() != list.BoundingBox() {
+		t.Errorf("BoundingBox() = %v, want %v", group.BoundingBox(), list.BoundingBox())
+	}
+	var want, got HitRecord
+	hits := 0
+	for k := range 2000 {
+		r := NewRay(rng, RandomInRange(rng, Interval{-8, 8}), RandomUnitVector(rng))
+		interval := FrontEpsilon
+		if k%4 == 0 {
+			interval = Interval{-2, 2} // also check the intervals starting behind the origin
+		}
+		hit := list.Hit(r, interval, &want)
+		if groupHit := group.Hit(r, interval, &got); groupHit != hit {
+			t.Fatalf("SphereGroup Hit() = %v, want %v", groupHit, hit)
+		}
+		if !hit {
+			continue
+		}
+		hits++
+		if got.T != want.T || !Equal(got.Point, want.Point, 1e-12) || !Equal(got.Normal, want.Normal, 1e-12) ||
+			got.FrontFace != want.FrontFace || got.Mat != want.Mat || got.U != want.U || got.V != want.V {
+			t.Fatalf("SphereGroup Hit() = %+v, want %+v", got, want)
+		}
+	}
+	if hits < 50 {
+		t.Errorf("only %d hits, the test isn't testing much", hits)
+	}
+}
+
+func TestGroupSpheres(t *testing.T) {
+	spheres := randomSpheres(RandForTests(), 3)
+	plane := NewPlane(Vec3{}, Vec3{0, 1, 0}, Lambertian{})
+	objects := []Hittable{plane, spheres[0], spheres[1], &MovingSphere{Radius: 1}, spheres[2]}
+	grouped := GroupSpheres(objects)
+	if len(grouped) != 3 {
+		t.Fatalf("GroupSpheres() returned %d objects, want 3", len(grouped))
+	}
+	if grouped[0] != plane {
+		t.Errorf("GroupSpheres()[0] = %v, want the plane", grouped[0])
+	}
+	if g, ok := grouped[1].(*SphereGroup); !ok || g.Len() != 3 {
+		t.Errorf("GroupSpheres()[1] = %v, want a SphereGroup of 3 spheres", grouped[1])
+	}
+	if _, ok := grouped[2].(*MovingSphere); !ok {
+		t.Errorf("GroupSpheres()[2] = %v, want the moving sphere", grouped[2])
+	}
+	if got := GroupSpheres([]Hittable{plane}); len(got) != 1 || got[0] != plane {
+		t.Errorf("GroupSpheres() without spheres = %v, want unchanged", got)
+	}
+}
+
+// BenchmarkSphereGroup compares the flat array group to a Scene list and a BVH of the
+// same spheres: the RichScene ones, hit by rays from the RichSceneCamera.
+func BenchmarkSphereGroup(b *testing.B) {
+	rng := RandForTests()
+	var spheres []*Sphere
+	var objects []Hittable
+	for _, o := range RichScene(rng).Objects {
+		if s, ok := o.(*Sphere); ok {
+			spheres = append(spheres, s)
+			objects = append(objects, s)
+		}
+	}
+	camera := RichSceneCamera()
+	camera.Initialize(200, 100)
+	rays := make([]*Ray, 1024)
+	for k := range rays {
+		rays[k] = camera.GetRay(rng, float64(rng.IntN(200)), float64(rng.IntN(100)), rng.Float64(), rng.Float64())
+	}
+	for _, bench := range []struct {
+		name string
+		h    Hittable
+	}{
+		{"list", &Scene{Objects: objects}},
+		{"bvh", NewBVH(append([]Hittable(nil), objects...))},
+		{"group", NewSphereGroup(spheres)},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			var hr HitRecord
+			k := 0
+			for b.Loop() {
+				bench.h.Hit(rays[k%len(rays)], FrontEpsilon, &hr)
+				k++
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package ray
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// RenderStats are the counters of the last render, filled in when Tracer.EnableStats is set.
+type RenderStats struct {
+	PrimaryRays int64         // Camera rays (one per path)
+	BounceRays  int64         // Scattered rays following the primary ones
+	ShadowRays  int64         // Rays toward Scene.Lights, checking for occlusion
+	Hits        int64         // Primary and bounce rays that hit an object
+	Misses      int64         // Primary and bounce rays that escaped to the background
+	Workers     int64         // Goroutines started to render the tiles (for each pass of progressive renders)
+	Elapsed     time.Duration // Wall time of the render
+}
+
+// Rays returns the total number of rays traced.
+func (s RenderStats) Rays() int64 {
+	return s.PrimaryRays + s.BounceRays + s.ShadowRays
+}
+
+// RaysPerSecond returns the average number of rays traced per second (0 before a render).
+func (s RenderStats) RaysPerSecond() float64 {
+	if s.Elapsed <= 0 {
+		return 0
+	}
+	return float64(s.Rays()) / s.Elapsed.Seconds()
+}
+
+func (s RenderStats) String() string {
+	return fmt.Sprintf("%d primary + %d bounce + %d shadow rays (%d hits, %d misses) in %v: %.3f Mrays/s",
+		s.PrimaryRays, s.BounceRays, s.ShadowRays, s.Hits, s.Misses, s.Elapsed.Round(time.Millisecond),
+		s.RaysPerSecond()/1e6)
+}
+
+// startStats resets the Stats for a new render, when enabled.
+func (t *Tracer) startStats() {
+	if t.EnableStats {
+		t.Stats = RenderStats{}
+		t.statsStart = time.Now()
+	}
+}
+
+// addStats adds the counts of a tile, kept in scratch without synchronization, to
+// t.Stats (atomically, as the workers share it) and resets them. It's a no-op unless
+// EnableStats is set.
+func (t *Tracer) addStats(scratch *pathScratch) {
+	if !t.EnableStats {
+		return
+	}
+	atomic.AddInt64(&t.Stats.PrimaryRays, scratch.paths)
+	atomic.AddInt64(&t.Stats.BounceRays, scratch.rays-scratch.paths)
+	atomic.AddInt64(&t.Stats.ShadowRays, scratch.shadowRays)
+	atomic.AddInt64(&t.Stats.Hits, scratch.hits)
+	atomic.AddInt64(&t.Stats.Misses, scratch.rays-scratch.hits)
+	scratch.paths, scratch.rays, scratch.shadowRays, scratch.hits = 0, 0, 0, 0
+}
+
+// finishStats records the elapsed time of the render, when enabled.
+func (t *Tracer) finishStats() {
+	if t.EnableStats {
+		t.Stats.Elapsed = time.Since(t.statsStart)
+	}
+}
@@ -0,0 +1,57 @@
+package ray
+
+import "testing"
+
+func TestStats(t *testing.T) {
+	render := func(enable bool, workers int) RenderStats {
+		rt := New(20, 10)
+		rt.Seed = 42
+		rt.NumRaysPerPixel = 3
+		rt.NumWorkers = workers
+		rt.TileSize = 4
+		rt.EnableStats = enable
+		scene := DefaultScene()
+		scene.Lights = []Light{DirectionalLight{Direction: Vec3{0, -1, 0}, Color: ColorF{1, 1, 1}}}
+		rt.Camera = *DefaultSceneCamera()
+		rt.Render(scene)
+		return rt.Stats
+	}
+	if s := render(false, 1); s != (RenderStats{}) {
+		t.Errorf("Stats without EnableStats = %v, want zero", s)
+	}
+	s := render(true, 1)
+	if s.PrimaryRays != 20*10*3 {
+		t.Errorf("PrimaryRays = %d, want %d", s.PrimaryRays, 20*10*3)
+	}
+	if s.BounceRays == 0 || s.ShadowRays == 0 || s.Hits == 0 || s.Misses == 0 {
+		t.Errorf("Stats = %+v, want some bounce and shadow rays, hits and misses", s)
+	}
+	if s.Hits+s.Misses != s.PrimaryRays+s.BounceRays {
+		t.Errorf("Hits + Misses = %d, want primary + bounce rays %d", s.Hits+s.Misses, s.PrimaryRays+s.BounceRays)
+	}
+	if s.Elapsed <= 0 || s.RaysPerSecond() <= 0 {
+		t.Errorf("Elapsed = %v, RaysPerSecond() = %v, want > 0", s.Elapsed, s.RaysPerSecond())
+	}
+	if s.Workers != 1 {
+		t.Errorf("Workers = %d, want 1", s.Workers)
+	}
+	// Pixels are seeded individually, so the counts don't depend on the number of workers.
+	p := render(true, 4)
+	if p.Workers != 4 {
+		t.Errorf("Workers = %d, want 4", p.Workers)
+	}
+	p.Elapsed, p.Workers = s.Elapsed, s.Workers
+	if p != s {
+		t.Errorf("Stats with 4 workers = %+v, want %+v", p, s)
+	}
+}
+
+func TestStatsSuperSample(t *testing.T) {
+	rt := New(8, 4)
+	rt.SuperSample = 2
+	rt.EnableStats = true
+	rt.Render(nil)
+	if rt.Stats.PrimaryRays != 16*8 || rt.Stats.Elapsed <= 0 {
+		t.Errorf("Stats = %v, want %d primary rays and some elapsed time", rt.Stats, 16*8)
+	}
+}
@@ -0,0 +1,48 @@
+package ray
+
+import (
+	"image"
+	"image/color"
+)
+
+// RenderStereo renders the scene twice, for a left and a right eye eyeSeparation apart
+// along the camera's Right vector (the camera's Position is between them). Both eyes keep
+// the camera's view direction (parallel axes, so no vertical disparity): far objects line
+// up while near ones shift horizontally, to the right in the left image. See Anaglyph to
+// combine them. The images are new ones (not reused by the next render) and the Camera is
+// left unchanged, even with a nil scene (rendered with DefaultSceneCamera).
+func (t *Tracer) RenderStereo(scene *Scene, eyeSeparation float64) (left, right *image.RGBA) {
+	saved := t.Camera
+	defer func() { t.Camera = saved }()
+	if scene == nil {
+		scene = DefaultScene() // and its matching camera, as Render would
+		t.Camera = *DefaultSceneCamera()
+	}
+	camera := t.Camera
+	t.Camera.Initialize(t.width, t.height)
+	offset := SMul(t.Camera.Right(), eyeSeparation/2)
+	eye := func(sign float64) *image.RGBA {
+		t.Camera = camera
+		t.Camera.Position = AddScaled(camera.Position, offset, sign)
+		t.Camera.LookAt = AddScaled(camera.LookAt, offset, sign)
+		img := t.Render(scene)
+		return &image.RGBA{Pix: append([]uint8(nil), img.Pix...), Stride: img.Stride, Rect: img.Rect}
+	}
+	return eye(-1), eye(1)
+}
+
+// Anaglyph combines a stereo pair (e.g. from Tracer.RenderStereo) into a red/cyan anaglyph,
+// for glasses with the red filter on the left eye: the red channel comes from the left
+// image and the green and blue ones from the right. The result covers the intersection
+// of both bounds.
+func Anaglyph(left, right *image.RGBA) *image.RGBA {
+	bounds := left.Bounds().Intersect(right.Bounds())
+	img := image.NewRGBA(bounds)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			l, r := left.RGBAAt(x, y), right.RGBAAt(x, y)
+			img.SetRGBA(x, y, color.RGBA{l.R, r.G, r.B, 255})
+		}
+	}
+	return img
+}
@@ -0,0 +1,94 @@
+package ray
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+// litCentroid returns the average coordinates of the non black pixels of img.
+func litCentroid(img *image.RGBA) (x, y float64, n int) {
+	b := img.Bounds()
+	for py := b.Min.Y; py < b.Max.Y; py++ {
+		for px := b.Min.X; px < b.Max.X; px++ {
+			if c := img.RGBAAt(px, py); c.R != 0 || c.G != 0 || c.B != 0 {
+				x += float64(px)
+				y += float64(py)
+				n++
+			}
+		}
+	}
+	if n == 0 {
+		return 0, 0, 0
+	}
+	return x / float64(n), y / float64(n), n
+}
+
+func TestRenderStereo(t *testing.T) {
+	scene := &Scene{
+		Objects:    []Hittable{NewSphere(Vec3{0, 0, -2}, 0.4, DiffuseLight{Emit: ColorF{1, 1, 1}})},
+		Background: NoBackground,
+	}
+	rt := New(61, 31)
+	rt.Seed = 42
+	rt.Camera = Camera{LookAt: Vec3{0, 0, -1}}
+	camera := rt.Camera
+	left, right := rt.RenderStereo(scene, 1)
+	if rt.Camera.Position != camera.Position || rt.Camera.LookAt != camera.LookAt {
+		t.Errorf("Camera changed to %v -> %v", rt.Camera.Position, rt.Camera.LookAt)
+	}
+	if bytes.Equal(left.Pix, right.Pix) {
+		t.Fatal("left and right images are the same")
+	}
+	lx, ly, ln := litCentroid(left)
+	rx, ry, rn := litCentroid(right)
+	if ln == 0 || rn == 0 {
+		t.Fatalf("sphere not visible: %d and %d pixels", ln, rn)
+	}
+	// 0.5 to each side at distance 2 is 0.25 on the viewport at distance 1, 2 high for 31 pixels.
+	wantShift := 2 * 0.25 * 31 / 2
+	if shift := lx - rx; math.Abs(shift-wantShift) > 1 {
+		t.Errorf("sphere at x %.2f (left) and %.2f (right), shift %.2f, want about %.2f", lx, rx, shift, wantShift)
+	}
+	if ly != ry {
+		t.Errorf("sphere at y %.2f (left) and %.2f (right), want no vertical shift", ly, ry)
+	}
+	// The centered mono render is in between.
+	cx, _, _ := litCentroid(rt.Render(scene))
+	if math.Abs(cx-(lx+rx)/2) > 0.5 || math.Abs(cx-30) > 0.5 {
+		t.Errorf("mono render centroid x = %.2f, want about %.2f (between the eyes) and 30 (centered)", cx, (lx+rx)/2)
+	}
+}
+
+func TestRenderStereo_NilScene(t *testing.T) {
+	rt := New(8, 6)
+	rt.Camera = Camera{Position: Vec3{1, 2, 3}, LookAt: Vec3{0, 0, -1}}
+	camera := rt.Camera
+	left, right := rt.RenderStereo(nil, 0.1)
+	if rt.Camera.Position != camera.Position || rt.Camera.LookAt != camera.LookAt {
+		t.Errorf("Camera changed to %v -> %v, want %v -> %v", rt.Camera.Position, rt.Camera.LookAt, camera.Position, camera.LookAt)
+	}
+	if bytes.Equal(left.Pix, right.Pix) {
+		t.Error("left and right images of the default scene are the same")
+	}
+}
+
+func TestAnaglyph(t *testing.T) {
+	left := image.NewRGBA(image.Rect(0, 0, 3, 2))
+	right := image.NewRGBA(image.Rect(0, 0, 2, 3))
+	for i := range left.Pix {
+		left.Pix[i] = 10
+	}
+	for i := range right.Pix {
+		right.Pix[i] = 20
+	}
+	img := Anaglyph(left, right)
+	if b := img.Bounds(); b != image.Rect(0, 0, 2, 2) {
+		t.Fatalf("Anaglyph() bounds = %v, want the intersection", b)
+	}
+	if c := img.RGBAAt(1, 1); c != (color.RGBA{10, 20, 20, 255}) {
+		t.Errorf("Anaglyph() pixel = %v, want red from the left, green and blue from the right", c)
+	}
+}
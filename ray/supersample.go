@@ -0,0 +1,63 @@
+package ray
+
+import (
+	"context"
+	"image"
+	"math"
+)
+
+// RenderSize returns the size of the image actually ray traced by Render: the image
+// size, or ceil(width*SuperSample) x ceil(height*SuperSample) when supersampling.
+// ProgressFunc reports pixels of that size.
+func (t *Tracer) RenderSize() (int, int) {
+	if t.SuperSample <= 1 {
+		return t.width, t.height
+	}
+	return int(math.Ceil(float64(t.width) * t.SuperSample)), int(math.Ceil(float64(t.height) * t.SuperSample))
+}
+
+// renderSuperSampled renders the scene at RenderSize into a separate (kept for the next
+// renders) linear buffer, then box filters it down to the image, averaging in linear space
+// before tone mapping and encoding.
+func (t *Tracer) renderSuperSampled(ctx context.Context, scene *Scene) (*image.RGBA, error) {
+	scene = t.setup(scene)
+	sw, sh := t.RenderSize()
+	inner := *t
+	inner.SuperSample = 0
+	inner.width, inner.height = sw, sh
+	inner.KeepHDR = true
+	inner.hdr = t.superHDR
+	inner.accum = nil
+	if t.superImage == nil || t.superImage.Bounds().Dx() != sw || t.superImage.Bounds().Dy() != sh {
+		t.superImage = image.NewRGBA(image.Rect(0, 0, sw, sh))
+	}
+	inner.imageData = t.superImage
+	_, err := inner.RenderContext(ctx, scene)
+	t.superHDR = inner.hdr
+	t.Stats = inner.Stats
+	fx, fy := float64(sw)/float64(t.width), float64(sh)/float64(t.height)
+	for y := range t.height {
+		for x := range t.width {
+			t.setPixel(x, y, boxAverage(t.superHDR, sw, float64(x)*fx, float64(y)*fy, fx, fy))
+		}
+	}
+	t.finishStats()
+	return t.imageData, err
+}
+
+// boxAverage returns the average of the w wide buf over the [x0, x0+dx) x [y0, y0+dy)
+// area, with the partially covered pixels weighted by their coverage (rounding errors
+// past the last row or column are ignored).
+func boxAverage(buf []ColorF, w int, x0, y0, dx, dy float64) ColorF {
+	var sum ColorF
+	x1, y1 := x0+dx, y0+dy
+	h := len(buf) / w
+	for sy := int(y0); sy < h && float64(sy) < y1; sy++ {
+		wy := math.Min(y1, float64(sy+1)) - math.Max(y0, float64(sy))
+		for sx := int(x0); sx < w && float64(sx) < x1; sx++ {
+			wx := math.Min(x1, float64(sx+1)) - math.Max(x0, float64(sx))
+			sum = AddScaled(sum, buf[sy*w+sx], wx*wy)
+		}
+	}
+	return SDiv(sum, dx*dy)
+}
@@ -0,0 +1,129 @@
+package ray
+
+import (
+	"bytes"
+	"image"
+	"slices"
+	"sync/atomic"
+	"testing"
+)
+
+func TestBoxAverage(t *testing.T) {
+	buf := []ColorF{
+		{1, 0, 0}, {0, 1, 0}, {0, 0, 1},
+		{3, 0, 0}, {0, 3, 0}, {0, 0, 3},
+	}
+	tests := []struct {
+		name           string
+		x0, y0, dx, dy float64
+		expected       ColorF
+	}{
+		{"single pixel", 1, 1, 1, 1, ColorF{0, 3, 0}},
+		{"2x2", 0, 0, 2, 2, ColorF{1, 1, 0}},
+		{"1.5 wide", 0, 0, 1.5, 1, ColorF{1 / 1.5, 0.5 / 1.5, 0}},
+		{"1.5 wide, second half", 1.5, 0, 1.5, 1, ColorF{0, 0.5 / 1.5, 1 / 1.5}},
+		{"whole", 0, 0, 3, 2, ColorF{4.0 / 6, 4.0 / 6, 4.0 / 6}},
+	}
+	for _, tt := range tests {
+		if got := boxAverage(buf, 3, tt.x0, tt.y0, tt.dx, tt.dy); !Equal(got, tt.expected, 1e-12) {
+			t.Errorf("%s: boxAverage() = %v, want %v", tt.name, got, tt.expected)
+		}
+	}
+}
+
+func TestRenderSize(t *testing.T) {
+	rt := New(10, 5)
+	for _, tt := range []struct {
+		superSample float64
+		w, h        int
+	}{
+		{0, 10, 5},
+		{1, 10, 5},
+		{0.5, 10, 5}, // no downsampling from a smaller render
+		{2, 20, 10},
+		{1.5, 15, 8},
+	} {
+		rt.SuperSample = tt.superSample
+		if w, h := rt.RenderSize(); w != tt.w || h != tt.h {
+			t.Errorf("SuperSample %v: RenderSize() = %dx%d, want %dx%d", tt.superSample, w, h, tt.w, tt.h)
+		}
+	}
+}
+
+func TestRender_SuperSample(t *testing.T) {
+	scene := DefaultScene()
+	camera := *RichSceneCamera()
+	// Reference: the same seed at twice the resolution, averaged 2x2 by hand.
+	big := New(32, 16)
+	big.Seed = 42
+	big.Camera = camera
+	big.KeepHDR = true
+	big.Render(scene)
+	rt := New(16, 8)
+	rt.Seed = 42
+	rt.Camera = camera
+	rt.KeepHDR = true
+	rt.SuperSample = 2
+	var pixels atomic.Int64
+	rt.ProgressFunc = func(n int) { pixels.Add(int64(n)) }
+	img := rt.Render(scene)
+	if img.Bounds().Dx() != 16 || img.Bounds().Dy() != 8 {
+		t.Fatalf("SuperSample image is %v, want 16x8", img.Bounds())
+	}
+	if n := pixels.Load(); n != 32*16 {
+		t.Errorf("ProgressFunc reported %d pixels, want %d", n, 32*16)
+	}
+	hdr := rt.HDRBuffer()
+	for y := range 8 {
+		for x := range 16 {
+			b := big.HDRBuffer()
+			expected := SMul(AddMultiple(b[2*y*32+2*x], b[2*y*32+2*x+1], b[(2*y+1)*32+2*x], b[(2*y+1)*32+2*x+1]), 0.25)
+			if got := hdr[y*16+x]; !Equal(got, expected, 1e-12) {
+				t.Fatalf("pixel (%d,%d) = %v, want the 2x2 average %v", x, y, got, expected)
+			}
+			if got, want := img.RGBAAt(x, y), expected.ToSRGBA(); got != want {
+				t.Fatalf("pixel (%d,%d) = %v, want %v", x, y, got, want)
+			}
+		}
+	}
+	// The buffers are reused: rendering again gives the same image.
+	first := bytes.Clone(img.Pix)
+	if again := rt.Render(scene); !bytes.Equal(first, again.Pix) {
+		t.Errorf("second SuperSample render differs")
+	}
+	// Non integer factor still produces the requested size.
+	rt.SuperSample = 1.5
+	if img = rt.Render(scene); img.Bounds().Dx() != 16 || img.Bounds().Dy() != 8 {
+		t.Errorf("SuperSample 1.5 image is %v, want 16x8", img.Bounds())
+	}
+}
+
+func TestSuperSampleIgnored(t *testing.T) {
+	// RenderRegion, RenderStream and RenderProgressive trace at the image's resolution.
+	tracer := func(superSample float64) *Tracer {
+		rt := New(16, 8)
+		rt.Seed = 42
+		rt.NumRaysPerPixel = 2
+		rt.SuperSample = superSample
+		return rt
+	}
+	want := slices.Clone(tracer(0).Render(nil).Pix)
+	region := tracer(2).RenderRegion(nil, image.Rect(0, 0, 16, 8))
+	if !slices.Equal(region.Pix, want) {
+		t.Error("RenderRegion with SuperSample 2 differs from Render without supersampling")
+	}
+	streamed := image.NewRGBA(image.Rect(0, 0, 16, 8))
+	for row := range tracer(2).RenderStream(nil) {
+		copy(streamed.Pix[streamed.PixOffset(0, row.Y):], row.Pix)
+	}
+	if !slices.Equal(streamed.Pix, want) {
+		t.Error("RenderStream with SuperSample 2 differs from Render without supersampling")
+	}
+	progressive := tracer(2).RenderProgressive(nil, nil)
+	if !slices.Equal(progressive.Pix, tracer(0).RenderProgressive(nil, nil).Pix) {
+		t.Error("RenderProgressive with SuperSample 2 differs from without supersampling")
+	}
+	if supersampled := tracer(2).Render(nil); slices.Equal(supersampled.Pix, want) {
+		t.Error("Render with SuperSample 2 should differ from without supersampling")
+	}
+}
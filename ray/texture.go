@@ -0,0 +1,131 @@
+package ray
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/jpeg" // register JPEG decoding for NewImageTexture.
+	_ "image/png"  // register PNG decoding for NewImageTexture.
+	"math"
+	"os"
+
+	"fortio.org/terminal/ansipixels/tcolor"
+)
+
+// Texture provides a color for a surface point, using either its (u,v)
+// surface coordinates or its position p (or both).
+type Texture interface {
+	Value(u, v float64, p Vec3) ColorF
+}
+
+// SolidColor is a Texture of a single uniform color.
+type SolidColor struct {
+	Albedo ColorF
+}
+
+func (sc SolidColor) Value(_, _ float64, _ Vec3) ColorF {
+	return sc.Albedo
+}
+
+// CheckerTexture is a 3D (spatial) checker pattern alternating between
+// the Even and Odd colors in cubes of size Scale.
+type CheckerTexture struct {
+	Scale     float64 // Size of the checker squares. If zero, defaults to 1.
+	Even, Odd ColorF
+}
+
+func (ct CheckerTexture) Value(_, _ float64, p Vec3) ColorF {
+	invScale := 1.0
+	if ct.Scale > 0 {
+		invScale = 1.0 / ct.Scale
+	}
+	x := int(math.Floor(invScale * p.x))
+	y := int(math.Floor(invScale * p.y))
+	z := int(math.Floor(invScale * p.z))
+	if (x+y+z)%2 == 0 {
+		return ct.Even
+	}
+	return ct.Odd
+}
+
+// ImageTexture maps an image onto a surface using the (u,v) coordinates, with
+// bilinear filtering. (0,0) is the bottom left of the image and (1,1) the top right.
+type ImageTexture struct {
+	// Wrap repeats the image for coordinates outside of [0,1] instead of
+	// clamping them to the edge pixels.
+	Wrap bool
+	// Path of the image file, when loaded with NewImageTexture (used to save scenes).
+	Path          string
+	width, height int
+	pixels        []ColorF // linear colors, row major, top row first.
+}
+
+// NewImageTexture loads a PNG or JPEG file as an ImageTexture.
+func NewImageTexture(path string) (*ImageTexture, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not open image %q: %w", path, err)
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode image %q: %w", path, err)
+	}
+	it := NewImageTextureFromImage(img)
+	it.Path = path
+	return it, nil
+}
+
+// NewImageTextureFromImage creates an ImageTexture from an (sRGB) image,
+// converting the pixels to linear colors once.
+func NewImageTextureFromImage(img image.Image) *ImageTexture {
+	b := img.Bounds()
+	it := &ImageTexture{
+		width:  b.Dx(),
+		height: b.Dy(),
+		pixels: make([]ColorF, b.Dx()*b.Dy()),
+	}
+	for y := range it.height {
+		for x := range it.width {
+			c := color.NRGBAModel.Convert(img.At(b.Min.X+x, b.Min.Y+y)).(color.NRGBA)
+			it.pixels[y*it.width+x] = ColorF{
+				tcolor.SrgbToLinear(c.R, 1),
+				tcolor.SrgbToLinear(c.G, 1),
+				tcolor.SrgbToLinear(c.B, 1),
+			}
+		}
+	}
+	return it
+}
+
+// index maps a (possibly out of range) pixel coordinate to a valid one.
+func (it *ImageTexture) index(i, n int) int {
+	if it.Wrap {
+		i %= n
+		if i < 0 {
+			i += n
+		}
+		return i
+	}
+	return min(max(i, 0), n-1)
+}
+
+func (it *ImageTexture) pixel(x, y int) ColorF {
+	return it.pixels[it.index(y, it.height)*it.width+it.index(x, it.width)]
+}
+
+func (it *ImageTexture) Value(u, v float64, _ Vec3) ColorF {
+	if len(it.pixels) == 0 {
+		// Solid cyan as a debugging aid when there is no image data.
+		return ColorF{0, 1, 1}
+	}
+	// Continuous pixel coordinates, relative to pixel centers. Image y goes down.
+	fx := u*float64(it.width) - 0.5
+	fy := (1-v)*float64(it.height) - 0.5
+	x0, y0 := math.Floor(fx), math.Floor(fy)
+	tx, ty := fx-x0, fy-y0
+	ix, iy := int(x0), int(y0)
+	top := Add(SMul(it.pixel(ix, iy), 1-tx), SMul(it.pixel(ix+1, iy), tx))
+	bottom := Add(SMul(it.pixel(ix, iy+1), 1-tx), SMul(it.pixel(ix+1, iy+1), tx))
+	return Add(SMul(top, 1-ty), SMul(bottom, ty))
+}
@@ -0,0 +1,215 @@
+package ray
+
+import (
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSolidColor(t *testing.T) {
+	c := ColorF{0.1, 0.2, 0.3}
+	tex := SolidColor{Albedo: c}
+	for _, p := range []Vec3{{0, 0, 0}, {1, -2, 3}} {
+		if v := tex.Value(0.3, 0.7, p); v != c {
+			t.Errorf("Value(%v) = %v, want %v", p, v, c)
+		}
+	}
+}
+
+func TestCheckerTexture(t *testing.T) {
+	even := ColorF{1, 1, 1}
+	odd := ColorF{0, 0, 0}
+	tests := []struct {
+		name     string
+		scale    float64
+		p        Vec3
+		expected ColorF
+	}{
+		{"origin cube", 1, Vec3{0.5, 0.5, 0.5}, even},
+		{"next in x", 1, Vec3{1.5, 0.5, 0.5}, odd},
+		{"next in x and y", 1, Vec3{1.5, 1.5, 0.5}, even},
+		{"negative side", 1, Vec3{-0.5, 0.5, 0.5}, odd},
+		{"negative diagonal", 1, Vec3{-0.5, -0.5, 0.5}, even},
+		{"bigger scale", 2, Vec3{1.5, 0.5, 0.5}, even},
+		{"zero scale defaults to 1", 0, Vec3{1.5, 0.5, 0.5}, odd},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tex := CheckerTexture{Scale: tt.scale, Even: even, Odd: odd}
+			if v := tex.Value(0, 0, tt.p); v != tt.expected {
+				t.Errorf("Value(%v) = %v, want %v", tt.p, v, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLambertianTexture(t *testing.T) {
+	rec := &HitRecord{
+		Point:  Vec3{1.5, 0.5, 0.5},
+		Normal: Vec3{0, 1, 0},
+	}
+	checker := CheckerTexture{Scale: 1, Even: ColorF{1, 1, 1}, Odd: ColorF{0.2, 0, 0}}
+	lambertian := Lambertian{Albedo: ColorF{0, 1, 0}, Tex: checker}
+	_, attenuation, _ := lambertian.Scatter(NewRay(RandForTests(), Vec3{}, Vec3{0, -1, 0}), rec)
+	if attenuation != checker.Odd {
+		t.Errorf("Expected texture color %v, got %v", checker.Odd, attenuation)
+	}
+	// A SolidColor texture gives the same result as the plain Albedo.
+	flat := Lambertian{Albedo: ColorF{0.3, 0.4, 0.5}}
+	textured := Lambertian{Tex: SolidColor{Albedo: flat.Albedo}}
+	didScatter1, att1, ray1 := flat.Scatter(NewRay(RandForTests(), Vec3{}, Vec3{0, -1, 0}), rec)
+	didScatter2, att2, ray2 := textured.Scatter(NewRay(RandForTests(), Vec3{}, Vec3{0, -1, 0}), rec)
+	if didScatter1 != didScatter2 || att1 != att2 || ray1.Direction != ray2.Direction {
+		t.Errorf("Expected identical scatter, got %v %v %v vs %v %v %v",
+			didScatter1, att1, ray1.Direction, didScatter2, att2, ray2.Direction)
+	}
+}
+
+func TestSphereUV(t *testing.T) {
+	tests := []struct {
+		p    Vec3
+		u, v float64
+	}{
+		{Vec3{1, 0, 0}, 0.5, 0.5},
+		{Vec3{-1, 0, 0}, 0, 0.5},
+		{Vec3{0, 1, 0}, 0.5, 1},
+		{Vec3{0, -1, 0}, 0.5, 0},
+		{Vec3{0, 0, 1}, 0.25, 0.5},
+		{Vec3{0, 0, -1}, 0.75, 0.5},
+	}
+	for _, tt := range tests {
+		u, v := SphereUV(tt.p)
+		if math.Abs(u-tt.u) > 1e-12 || math.Abs(v-tt.v) > 1e-12 {
+			t.Errorf("SphereUV(%v) = (%v, %v), want (%v, %v)", tt.p, u, v, tt.u, tt.v)
+		}
+	}
+}
+
+func TestSphereHitUV(t *testing.T) {
+	sphere := &Sphere{Center: Vec3{0, 0, -5}, Radius: 2, Mat: Lambertian{}}
+	// Hits the sphere at its +Z pole.
+	hit, rec := testHit(sphere, NewRay(RandForTests(), Vec3{0, 0, 0}, Vec3{0, 0, -1}), FrontEpsilon)
+	if !hit {
+		t.Fatal("Expected hit")
+	}
+	if math.Abs(rec.U-0.25) > 1e-12 || math.Abs(rec.V-0.5) > 1e-12 {
+		t.Errorf("Expected (u,v) = (0.25, 0.5), got (%v, %v)", rec.U, rec.V)
+	}
+}
+
+func TestQuadHitUV(t *testing.T) {
+	quad := NewQuad(Vec3{-1, -1, -2}, Vec3{4, 0, 0}, Vec3{0, 2, 0}, Lambertian{})
+	hit, rec := testHit(quad, NewRay(RandForTests(), Vec3{0, 0, 0}, Vec3{0, 0, -1}), FrontEpsilon)
+	if !hit {
+		t.Fatal("Expected hit")
+	}
+	if math.Abs(rec.U-0.25) > 1e-12 || math.Abs(rec.V-0.5) > 1e-12 {
+		t.Errorf("Expected (u,v) = (0.25, 0.5), got (%v, %v)", rec.U, rec.V)
+	}
+}
+
+// testImage returns a 2x2 image: black, white on top and red, green on the bottom.
+func testImage() *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	img.SetRGBA(0, 0, color.RGBA{0, 0, 0, 255})
+	img.SetRGBA(1, 0, color.RGBA{255, 255, 255, 255})
+	img.SetRGBA(0, 1, color.RGBA{255, 0, 0, 255})
+	img.SetRGBA(1, 1, color.RGBA{0, 255, 0, 255})
+	return img
+}
+
+func TestImageTextureValue(t *testing.T) {
+	tex := NewImageTextureFromImage(testImage())
+	tests := []struct {
+		name     string
+		u, v     float64
+		expected ColorF
+	}{
+		{"top left pixel center", 0.25, 0.75, ColorF{0, 0, 0}},
+		{"top right pixel center", 0.75, 0.75, ColorF{1, 1, 1}},
+		{"bottom left pixel center", 0.25, 0.25, ColorF{1, 0, 0}},
+		{"bottom right pixel center", 0.75, 0.25, ColorF{0, 1, 0}},
+		{"between top pixels", 0.5, 0.75, ColorF{0.5, 0.5, 0.5}},
+		{"center", 0.5, 0.5, ColorF{0.5, 0.5, 0.25}},
+		{"clamped corner", 0, 1, ColorF{0, 0, 0}},
+		{"clamped outside", 5, -3, ColorF{0, 1, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := tex.Value(tt.u, tt.v, Vec3{})
+			if !Equal(c, tt.expected, 1e-12) {
+				t.Errorf("Value(%v, %v) = %v, want %v", tt.u, tt.v, c, tt.expected)
+			}
+		})
+	}
+}
+
+func TestImageTextureWrap(t *testing.T) {
+	tex := NewImageTextureFromImage(testImage())
+	tex.Wrap = true
+	// At the left edge we blend with the (wrapped) right column.
+	c := tex.Value(0, 0.75, Vec3{})
+	if expected := (ColorF{0.5, 0.5, 0.5}); !Equal(c, expected, 1e-12) {
+		t.Errorf("Value(0, 0.75) = %v, want %v", c, expected)
+	}
+	// A whole number of repeats away gives the same value.
+	if c1, c2 := tex.Value(0.3, 0.6, Vec3{}), tex.Value(-1.7, 2.6, Vec3{}); !Equal(c1, c2, 1e-12) {
+		t.Errorf("Wrapped values differ: %v vs %v", c1, c2)
+	}
+}
+
+func TestImageTextureEmpty(t *testing.T) {
+	tex := &ImageTexture{}
+	if c := tex.Value(0.5, 0.5, Vec3{}); c != (ColorF{0, 1, 1}) {
+		t.Errorf("Value() on empty texture = %v, want cyan", c)
+	}
+}
+
+func TestNewImageTexture(t *testing.T) {
+	dir := t.TempDir()
+	for _, tt := range []struct {
+		name   string
+		encode func(f *os.File) error
+	}{
+		{"tex.png", func(f *os.File) error { return png.Encode(f, testImage()) }},
+		{"tex.jpg", func(f *os.File) error { return jpeg.Encode(f, testImage(), &jpeg.Options{Quality: 100}) }},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			fname := filepath.Join(dir, tt.name)
+			f, err := os.Create(fname)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err = tt.encode(f); err != nil {
+				t.Fatal(err)
+			}
+			f.Close()
+			tex, err := NewImageTexture(fname)
+			if err != nil {
+				t.Fatalf("NewImageTexture() error: %v", err)
+			}
+			if tex.width != 2 || tex.height != 2 {
+				t.Errorf("texture size = %dx%d, want 2x2", tex.width, tex.height)
+			}
+			// jpeg is lossy, so only check the top right is (much) brighter than the top left.
+			if tex.Value(0.75, 0.75, Vec3{}).x < tex.Value(0.25, 0.75, Vec3{}).x+0.5 {
+				t.Errorf("unexpected texture content %v", tex.pixels)
+			}
+		})
+	}
+	if _, err := NewImageTexture(filepath.Join(dir, "missing.png")); err == nil {
+		t.Error("Expected error for missing file")
+	}
+	bad := filepath.Join(dir, "bad.png")
+	if err := os.WriteFile(bad, []byte("not an image"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewImageTexture(bad); err == nil {
+		t.Error("Expected error for invalid image")
+	}
+}
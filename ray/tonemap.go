@@ -0,0 +1,62 @@
+package ray
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ToneMapper selects how linear (possibly high dynamic range, e.g. with emissive lights)
+// colors are mapped to [0,1] before the sRGB conversion.
+type ToneMapper int
+
+const (
+	// ToneMapNone just clips values above 1 (default, the original behavior).
+	ToneMapNone ToneMapper = iota
+	// ToneMapReinhard is the simple Reinhard operator c/(1+c) applied per channel.
+	ToneMapReinhard
+	// ToneMapACES is the ACES filmic curve (Krzysztof Narkowicz's fit).
+	ToneMapACES
+)
+
+var toneMapperNames = []string{"none", "reinhard", "aces"}
+
+func (tm ToneMapper) String() string {
+	if tm < 0 || int(tm) >= len(toneMapperNames) {
+		return fmt.Sprintf("ToneMapper(%d)", int(tm))
+	}
+	return toneMapperNames[tm]
+}
+
+// ParseToneMapper returns the ToneMapper for the given name (case insensitive):
+// one of "none", "reinhard" or "aces".
+func ParseToneMapper(name string) (ToneMapper, error) {
+	for i, n := range toneMapperNames {
+		if strings.EqualFold(name, n) {
+			return ToneMapper(i), nil
+		}
+	}
+	return ToneMapNone, fmt.Errorf("unknown tone mapper %q, should be one of %v", name, toneMapperNames)
+}
+
+// Apply tone maps the linear color c.
+func (tm ToneMapper) Apply(c ColorF) ColorF {
+	switch tm {
+	case ToneMapReinhard:
+		return ColorF{c.x / (1 + c.x), c.y / (1 + c.y), c.z / (1 + c.z)}
+	case ToneMapACES:
+		return ColorF{acesFilmic(c.x), acesFilmic(c.y), acesFilmic(c.z)}
+	default:
+		return c
+	}
+}
+
+func acesFilmic(x float64) float64 {
+	const (
+		a = 2.51
+		b = 0.03
+		c = 2.43
+		d = 0.59
+		e = 0.14
+	)
+	return ZeroOne.Clamp((x * (a*x + b)) / (x*(c*x+d) + e))
+}
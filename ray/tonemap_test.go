@@ -0,0 +1,79 @@
+package ray
+
+import (
+	"math"
+	"testing"
+)
+
+func TestToneMapperApply(t *testing.T) {
+	tests := []struct {
+		name     string
+		tm       ToneMapper
+		in       ColorF
+		expected ColorF
+	}{
+		{"none is identity", ToneMapNone, ColorF{0.2, 1.5, 7}, ColorF{0.2, 1.5, 7}},
+		{"reinhard", ToneMapReinhard, ColorF{0, 1, 3}, ColorF{0, 0.5, 0.75}},
+		{"aces black", ToneMapACES, ColorF{0, 0, 0}, ColorF{0, 0, 0}},
+		{"aces saturates", ToneMapACES, ColorF{100, 1000, 1e6}, ColorF{1, 1, 1}},
+		{"unknown is identity", ToneMapper(42), ColorF{0.2, 1.5, 7}, ColorF{0.2, 1.5, 7}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if c := tt.tm.Apply(tt.in); !Equal(c, tt.expected, 1e-12) {
+				t.Errorf("Apply(%v) = %v, want %v", tt.in, c, tt.expected)
+			}
+		})
+	}
+}
+
+func TestToneMapperMonotonicAndBounded(t *testing.T) {
+	for _, tm := range []ToneMapper{ToneMapReinhard, ToneMapACES} {
+		prev := -1.0
+		for x := 0.0; x < 50; x += 0.01 {
+			v := tm.Apply(ColorF{x, x, x}).x
+			if v < prev || v < 0 || v > 1 {
+				t.Fatalf("%v: Apply(%v) = %v (previous %v), want increasing in [0,1]", tm, x, v, prev)
+			}
+			prev = v
+		}
+		// Mid gray stays in the mid range.
+		if v := tm.Apply(ColorF{0.18, 0.18, 0.18}).x; math.Abs(v-0.18) > 0.1 {
+			t.Errorf("%v: Apply(0.18) = %v, too far from the input", tm, v)
+		}
+	}
+}
+
+func TestParseToneMapper(t *testing.T) {
+	for _, tm := range []ToneMapper{ToneMapNone, ToneMapReinhard, ToneMapACES} {
+		parsed, err := ParseToneMapper(tm.String())
+		if err != nil || parsed != tm {
+			t.Errorf("ParseToneMapper(%q) = %v, %v; want %v", tm.String(), parsed, err, tm)
+		}
+	}
+	if tm, err := ParseToneMapper("ACES"); err != nil || tm != ToneMapACES {
+		t.Errorf("ParseToneMapper(\"ACES\") = %v, %v; want aces", tm, err)
+	}
+	if _, err := ParseToneMapper("filmic"); err == nil {
+		t.Error("Expected error for unknown tone mapper")
+	}
+	if s := ToneMapper(-1).String(); s != "ToneMapper(-1)" {
+		t.Errorf("String() = %q, want \"ToneMapper(-1)\"", s)
+	}
+}
+
+func TestRender_ToneMapper(t *testing.T) {
+	// Very bright emissive background: clipped to white by default, compressed by tone mapping.
+	scene := &Scene{Objects: []Hittable{}, Background: AmbientLight{ColorA: ColorF{8, 4, 2}, ColorB: ColorF{8, 4, 2}}}
+	tracer := New(2, 2)
+	img := tracer.Render(scene)
+	if c := img.RGBAAt(0, 0); c.R != 255 || c.G != 255 || c.B != 255 {
+		t.Errorf("Default (no tone mapping) pixel = %v, want clipped white", c)
+	}
+	tracer = New(2, 2)
+	tracer.ToneMapper = ToneMapReinhard
+	img = tracer.Render(scene)
+	if c := img.RGBAAt(0, 0); c.R == 255 || !(c.R > c.G && c.G > c.B) {
+		t.Errorf("Reinhard pixel = %v, want unclipped and keeping the hue ordering", c)
+	}
+}
@@ -14,142 +14,505 @@
 package ray
 
 import (
+	"context"
+	"fmt"
 	"image"
+	"math"
 	"runtime"
+	"slices"
 	"sync"
+	"sync/atomic"
+	"time"
 
+	"fortio.org/log"
 	"fortio.org/rand"
 )
 
 // Tracer represents a ray tracing engine.
 type Tracer struct {
 	Camera
-	MaxDepth        int
-	NumRaysPerPixel int
-	RayRadius       float64
-	NumWorkers      int // Number of parallel workers; defaults to GOMAXPROCS if <= 0
-	ProgressFunc    func(delta int)
-	Seed            uint64 // Seed for random number generators; 0 means randomized each time
-	width, height   int
-	imageData       *image.RGBA
+	MaxDepth             int
+	ShadowEpsilon        float64 // Hits closer than this along secondary (and shadow) rays are ignored; defaults to DefaultShadowEpsilon (1e-6) if <= 0
+	NumRaysPerPixel      int
+	RayRadius            float64
+	NumWorkers           int             // Number of parallel workers; defaults to GOMAXPROCS if <= 0, capped at the number of tiles
+	ProgressFunc         func(delta int) // Called concurrently for each line (of a tile) with its number of pixels (of rays traced when adaptive); see also Progress
+	Seed                 uint64          // Seed for random number generators; 0 means randomized each time
+	ToneMapper           ToneMapper      // Applied to the averaged linear color before sRGB conversion; default ToneMapNone (clip)
+	OutputEncoding       OutputEncoding  // Transfer function for the 8 bit image; default EncodingSRGB
+	Gamma                float64         // Exponent for EncodingGamma; defaults to DefaultGamma (2.2) if <= 0
+	RussianRoulette      bool            // Randomly end dim paths (unbiased, faster but noisier per sample); see Scene.RayColorRussianRoulette
+	RussianRouletteDepth int             // Number of bounces before Russian roulette kicks in; defaults to 3 if <= 0
+	Shading              Shading         // Full path tracing (default) or a quick preview: normals, flat, depth or ambient occlusion
+	AORadius             float64         // Distance within which hits occlude, for ShadingAO; defaults to DefaultAORadius (1) if <= 0
+	AOSamples            int             // Number of occlusion rays per camera ray, for ShadingAO; defaults to DefaultAOSamples (16) if <= 0
+	SamplingStrategy     Sampling        // How diffuse surfaces sample the Scene's Emitters: SamplingMIS (default), SamplingBRDF or SamplingLight
+	ClampIndirect        float64         // When > 0, caps the luminance of each sample's light gathered after the first bounce (fireflies); biased
+	Sampler              Sampler         // How sub-pixel offsets are picked with multiple rays per pixel; default SamplerRandom
+	Filter               Filter          // How the rays of a pixel are weighted by their offset (within RayRadius); default FilterBox
+	Antithetic           bool            // Pair the rays of a pixel through mirrored lens points, less depth of field noise (Aperture > 0, even NumRaysPerPixel)
+	AdaptiveThreshold    float64         // When > 0, noisy pixels get more rays until their luminance standard error is below it (e.g. 0.01)
+	MaxRaysPerPixel      int             // Cap on the rays per pixel for adaptive sampling; defaults to 4 x NumRaysPerPixel if <= 0
+	TileSize             int             // Side of the square tiles handed out to the workers; defaults to 16 if <= 0
+	CameraPath           *CameraPath     // Camera animation for RenderFrame; optional
+	KeepHDR              bool            // Also retain the linear (unclamped, not tone mapped) pixel colors, see HDRBuffer
+	SuperSample          float64         // When > 1, Render (and RenderContext, RenderFrame, RenderStereo, RenderAOV) traces SuperSample times more pixels per side and box filters them down (see RenderSize); the region, stream and progressive renders ignore it
+	EnableStats          bool            // Count the rays traced into Stats (per tile, so the atomic updates are cheap)
+	Stats                RenderStats     // Counters of the last render, when EnableStats is set
+	OnTileComplete       TileFunc        // Called after each tile is rendered, e.g. for a heatmap of the time spent; optional
+	width, height        int
+	imageData            *image.RGBA
+	accum                []ColorF    // Running sum of the samples for RenderProgressive
+	passes               int         // Number of passes summed in accum
+	resume               bool        // accum and passes come from LoadCheckpoint: continue from them
+	hdr                  []ColorF    // Linear colors of the pixels when KeepHDR is set
+	superImage           *image.RGBA // Supersampled image (RenderSize)
+	superHDR             []ColorF    // Linear colors of the supersampled image
+	statsStart           time.Time   // Start of the render, for Stats.Elapsed
+	// Pixels done, for Progress (shared with the supersampling copy)
+	progress *progressCounter
 }
 
-// New creates and initializes a new Tracer.
+// TileFunc receives a rendered tile and how long it took. It's called concurrently
+// by the workers.
+type TileFunc func(tile image.Rectangle, dur time.Duration)
+
+// New creates and initializes a new Tracer. Width and height are clamped to at least 1
+// (e.g. when a terminal size query failed and returned 0).
 func New(width, height int) *Tracer {
-	// Implementation of ray tracer initialization.
+	width, height = max(width, 1), max(height, 1)
 	return &Tracer{
 		width:     width,
 		height:    height,
 		imageData: image.NewRGBA(image.Rect(0, 0, width, height)),
+		progress:  &progressCounter{},
+	}
+}
+
+// SetImageBuffer makes the next renders write into img instead of the image allocated by
+// New, e.g. to reuse the same memory for all the frames of an animation. img must have the
+// tracer's size with its bounds starting at (0, 0).
+func (t *Tracer) SetImageBuffer(img *image.RGBA) error {
+	if want := image.Rect(0, 0, t.width, t.height); img == nil || img.Bounds() != want {
+		var got image.Rectangle
+		if img != nil {
+			got = img.Bounds()
+		}
+		return fmt.Errorf("image buffer bounds %v don't match the tracer's %v", got, want)
 	}
+	t.imageData = img
+	return nil
 }
 
 // Render performs the ray tracing and returns the resulting image data.
 func (t *Tracer) Render(scene *Scene) *image.RGBA {
+	img, _ := t.RenderContext(context.Background(), scene) // can't be canceled
+	return img
+}
+
+// RenderContext is like Render but stops early, returning ctx.Err(), when the context
+// is canceled. The image is then partially rendered (pixels already done are kept).
+func (t *Tracer) RenderContext(ctx context.Context, scene *Scene) (*image.RGBA, error) {
+	if t.SuperSample > 1 {
+		return t.renderSuperSampled(ctx, scene)
+	}
+	scene = t.setup(scene)
+	t.parallelTiles(ctx, t.imageData.Bounds(), func(tile image.Rectangle) {
+		t.renderRect(ctx, 0, tile, scene)
+	})
+	t.finishStats()
+	return t.imageData, ctx.Err()
+}
+
+// RenderRegion only ray traces the pixels inside region (clipped to the image), leaving
+// the rest of the image as is (e.g. from a previous Render). Useful for quick previews
+// of a detail or to update just the part of the image that changed. SuperSample is
+// ignored: the region's pixels are traced at the image's resolution.
+func (t *Tracer) RenderRegion(scene *Scene, region image.Rectangle) *image.RGBA {
+	scene = t.setup(scene)
+	region = region.Intersect(t.imageData.Bounds())
+	if region.Empty() {
+		return t.imageData
+	}
+	t.startProgress(region.Dx() * region.Dy())
+	t.parallelTiles(context.Background(), region, func(tile image.Rectangle) {
+		t.renderRect(context.Background(), 0, tile, scene)
+	})
+	t.finishStats()
+	return t.imageData
+}
+
+// RenderedRow is one line of the image, as sent by RenderStream.
+type RenderedRow struct {
+	Y   int     // Row index (0 is the top)
+	Pix []uint8 // Copy of the row's pixels, 4 bytes (R, G, B, A) per pixel like image.RGBA
+}
+
+// RenderStream renders the scene in the background and sends each row as soon as it's
+// done. Rows arrive in whatever order the workers complete them (hence the Y index).
+// The channel is closed once the whole image is rendered. SuperSample is ignored.
+func (t *Tracer) RenderStream(scene *Scene) <-chan RenderedRow {
+	scene = t.setup(scene)
+	// Buffered for the whole image so workers never wait on a slow consumer.
+	rows := make(chan RenderedRow, t.height)
+	// Rows are complete once all the tiles of their band are done.
+	tileCols := (t.width + t.TileSize - 1) / t.TileSize
+	bandTiles := make([]atomic.Int32, (t.height+t.TileSize-1)/t.TileSize)
+	go func() {
+		defer close(rows)
+		t.parallelTiles(context.Background(), t.imageData.Bounds(), func(tile image.Rectangle) {
+			t.renderRect(context.Background(), 0, tile, scene)
+			if int(bandTiles[tile.Min.Y/t.TileSize].Add(1)) < tileCols {
+				return
+			}
+			for y := tile.Min.Y; y < tile.Max.Y; y++ {
+				off := t.imageData.PixOffset(0, y)
+				rows <- RenderedRow{Y: y, Pix: slices.Clone(t.imageData.Pix[off : off+4*t.width])}
+			}
+		})
+		t.finishStats()
+	}()
+	return rows
+}
+
+// RenderProgressive renders the image one sample per pixel pass at a time, for up to
+// NumRaysPerPixel passes, so a noisy image is available right away and then refines.
+// The running average is kept in a float accumulator and onPass is called with the
+// updated image after each pass (1 for the first one); returning false stops early.
+// After LoadCheckpoint it continues with the pass following the saved ones. SuperSample
+// is ignored (also by RenderWithin and RenderSnapshots).
+func (t *Tracer) RenderProgressive(scene *Scene, onPass func(img *image.RGBA, pass int) bool) *image.RGBA {
+	scene = t.setup(scene)
+	t.progressive(scene, t.NumRaysPerPixel, onPass)
+	t.finishStats()
+	return t.imageData
+}
+
+// RenderWithin renders progressively (see RenderProgressive) until the time budget is
+// spent, and returns the image averaged over the completed passes along with their
+// number (the rays per pixel). It always does at least one pass and doesn't start one
+// that would likely end past the budget, judging by the duration of the previous one.
+func (t *Tracer) RenderWithin(scene *Scene, budget time.Duration) (*image.RGBA, int) {
+	start := time.Now()
+	scene = t.setup(scene)
+	last := start
+	passes := t.progressive(scene, math.MaxInt, func(_ *image.RGBA, _ int) bool {
+		now := time.Now()
+		passDuration := now.Sub(last)
+		last = now
+		return now.Sub(start)+passDuration <= budget
+	})
+	t.finishStats()
+	return t.imageData, passes
+}
+
+// progressive does the passes of RenderProgressive, up to maxPasses, and returns how
+// many were done.
+func (t *Tracer) progressive(scene *Scene, maxPasses int, onPass func(img *image.RGBA, pass int) bool) int {
+	pass := 1
+	if t.resume && len(t.accum) == t.width*t.height {
+		pass = t.passes + 1
+		t.showAccum()
+	} else if len(t.accum) != t.width*t.height {
+		t.accum = make([]ColorF, t.width*t.height)
+	} else {
+		clear(t.accum)
+	}
+	t.resume = false
+	t.passes = pass - 1
+	if maxPasses == math.MaxInt {
+		t.startProgress(0) // unknown
+	} else {
+		t.startProgress(max(maxPasses-t.passes, 0) * t.width * t.height)
+	}
+	for ; pass <= maxPasses; pass++ {
+		t.parallelTiles(context.Background(), t.imageData.Bounds(), func(tile image.Rectangle) {
+			// Different random sequences for each pass.
+			t.accumulateRect((pass-1)*t.width*t.height, tile, pass, scene)
+		})
+		t.passes = pass
+		if onPass != nil && !onPass(t.imageData, pass) {
+			return pass
+		}
+	}
+	return t.passes
+}
+
+// HDRBuffer returns the linear colors (before tone mapping and clamping) of the pixels of
+// the last render, row by row, e.g. for SaveHDR. It is nil unless KeepHDR was set.
+func (t *Tracer) HDRBuffer() []ColorF {
+	return t.hdr
+}
+
+// PrimaryRay returns the camera ray through the center of pixel (x, y), ignoring the
+// aperture (depth of field), e.g. to find what's under a pixel with Scene.HitObject.
+func (t *Tracer) PrimaryRay(x, y int) *Ray {
+	t.Camera.Initialize(t.width, t.height)
+	pinhole := t.Camera
+	pinhole.Aperture = 0
+	return pinhole.GetRay(rand.New(t.Seed), float64(x), float64(y), 0, 0)
+}
+
+// setup sets the default values, initializes the camera and returns the scene to render.
+func (t *Tracer) setup(scene *Scene) *Scene {
 	if scene == nil {
 		scene = DefaultScene()
-		// For now/for this scene:
-		// t.Position = Vec3{0, .5, 5}
-		t.Position = Vec3{-2, 2, 1}
-		t.LookAt = Vec3{0, 0, -1}
-		t.VerticalFoV = 20.0
-		// t.LookAt = Vec3{-0.1, 0, -0.75} // look slight left and down and in front of the sphere
-		// t.FocalLength = 5
-		// t.VerticalFoV = 40.0
-		t.Aperture = .1
-		t.FocusDistance = Length(Sub(t.Position, t.LookAt))
-	}
-	// Need some/any light to get rays that aren't all black:
-	if scene.Background.ColorA == (ColorF{}) && scene.Background.ColorB == (ColorF{}) {
+		t.Camera = *DefaultSceneCamera()
+	}
+	// Need some/any light to get rays that aren't all black (unless explicitly set, e.g. to NoBackground):
+	if scene.Background == nil {
 		scene.Background = DefaultBackground()
 	}
-	// Other default values:
+	t.applyDefaults()
+	if t.MaxRaysPerPixel <= 0 {
+		t.MaxRaysPerPixel = 4 * t.NumRaysPerPixel
+	}
+	switch {
+	case !t.KeepHDR:
+		t.hdr = nil
+	case len(t.hdr) != t.width*t.height:
+		t.hdr = make([]ColorF, t.width*t.height)
+	}
+	// And zero value (0,0,0) for Camera is the right default
+	// (when not hardcoded in nil scene case above).
+
+	// Initialize camera viewport parameters (and set camera defaults if needed)
+	t.Camera.Initialize(t.width, t.height)
+	t.startStats()
+	t.startProgress(t.width * t.height)
+	return scene
+}
+
+// applyDefaults sets the default values of the fields that are <= 0 (other than
+// MaxRaysPerPixel, which depends on NumRaysPerPixel).
+func (t *Tracer) applyDefaults() {
 	if t.MaxDepth <= 0 {
 		t.MaxDepth = 10
 	}
 	if t.NumRaysPerPixel <= 0 {
 		t.NumRaysPerPixel = 1
 	}
+	if t.ShadowEpsilon <= 0 {
+		t.ShadowEpsilon = DefaultShadowEpsilon
+	}
 	if t.RayRadius <= 0 {
 		t.RayRadius = 0.5
 	}
 	if t.NumWorkers <= 0 {
 		t.NumWorkers = runtime.GOMAXPROCS(0)
 	}
-	// And zero value (0,0,0) for Camera is the right default
-	// (when not hardcoded in nil scene case above).
-
-	// Initialize camera viewport parameters (and set camera defaults if needed)
-	t.Camera.Initialize(t.width, t.height)
+	if t.RussianRouletteDepth <= 0 {
+		t.RussianRouletteDepth = 3
+	}
+	if t.TileSize <= 0 {
+		t.TileSize = 16
+	}
+}
 
-	// Parallel rendering
-	var wg sync.WaitGroup
-	if t.NumWorkers == 1 {
-		// Special case: single worker renders entire image (preserves exact RNG sequence)
-		t.RenderLines(0, 0, t.height, scene)
-	} else {
-		// Work queue approach for dynamic load balancing across multiple workers
-		// Divide image into chunks (smaller than worker count for better distribution)
-		chunkSize := max(4, t.height/(t.NumWorkers*4))
-		type workChunk struct{ startY, endY int }
-		// numChunks = ceiling of t.height/chunkSize
-		numChunks := (t.height + chunkSize - 1) / chunkSize
-		workQueue := make(chan workChunk, numChunks)
-
-		// Fill work queue with chunks
-		for y := 0; y < t.height; y += chunkSize {
-			workQueue <- workChunk{y, min(y+chunkSize, t.height)}
+// parallelTiles splits region into TileSize x TileSize tiles and calls render for each,
+// from NumWorkers goroutines. Each worker takes the next tile from a shared atomic counter
+// so the ones getting the cheap tiles (e.g. sky) just do more of them and they all finish
+// at about the same time. Remaining tiles are skipped once ctx is canceled. The pixels
+// have their own random generators, so the image doesn't depend on NumWorkers (nor TileSize).
+func (t *Tracer) parallelTiles(ctx context.Context, region image.Rectangle, render func(tile image.Rectangle)) {
+	if onTile := t.OnTileComplete; onTile != nil {
+		timed := render
+		render = func(tile image.Rectangle) {
+			start := time.Now()
+			timed(tile)
+			onTile(tile, time.Since(start))
 		}
-		close(workQueue)
-
-		// Workers pull chunks from queue until empty
-		for range t.NumWorkers {
-			wg.Add(1)
-			go func() {
-				defer wg.Done()
-				for chunk := range workQueue {
-					t.RenderLines(chunk.startY, chunk.startY, chunk.endY, scene)
+	}
+	size := t.TileSize
+	cols := (region.Dx() + size - 1) / size
+	numTiles := cols * ((region.Dy() + size - 1) / size)
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	// No more workers than tiles: with many cores and a small image (or region) the extra
+	// ones would have nothing to do. The tiles stay TileSize big whatever the number of
+	// workers, so each still gets enough work per atomic increment.
+	for range min(t.NumWorkers, numTiles) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if t.EnableStats {
+				atomic.AddInt64(&t.Stats.Workers, 1)
+			}
+			for ctx.Err() == nil {
+				i := int(next.Add(1) - 1)
+				if i >= numTiles {
+					return
 				}
-			}()
-		}
-		wg.Wait()
+				tileMin := region.Min.Add(image.Pt(i%cols*size, i/cols*size))
+				render(image.Rectangle{Min: tileMin, Max: tileMin.Add(image.Pt(size, size))}.Intersect(region))
+			}
+		}()
 	}
-	return t.imageData
+	wg.Wait()
 }
 
+// RenderLines renders lines [yStart, yEnd) of the image. Each pixel (x, y) uses its own
+// random generator, seeded from (idx + y*width + x, Seed), so the result doesn't depend on
+// how the image is split between calls (or workers); use idx 0 to get the same pixels as Render.
 func (t *Tracer) RenderLines(idx, yStart, yEnd int, scene *Scene) {
-	rng := rand.NewIdx(idx, t.Seed)
+	t.renderRect(context.Background(), idx, image.Rect(0, yStart, t.width, yEnd), scene)
+}
+
+// renderRect renders the pixels of rect, checking for cancellation before each line.
+// See RenderLines for idx.
+func (t *Tracer) renderRect(ctx context.Context, idx int, rect image.Rectangle, scene *Scene) {
 	multipleRays := t.NumRaysPerPixel > 1
-	colorSumDiv := 1.0 / float64(t.NumRaysPerPixel)
-	pix := t.imageData.Pix
-	for y := yStart; y < yEnd; y++ {
-		if t.ProgressFunc != nil {
-			t.ProgressFunc(t.width)
+	rrDepth := t.rrDepth()
+	antithetic := t.antithetic()
+	strata := t.Sampler.strata(t.NumRaysPerPixel)
+	if antithetic {
+		strata = t.Sampler.strata(t.NumRaysPerPixel / 2)
+	}
+	adaptive := t.AdaptiveThreshold > 0
+	scratch := &pathScratch{sampling: t.SamplingStrategy}
+	defer t.addStats(scratch)
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		if ctx.Err() != nil {
+			return
 		}
-		for x := range t.width {
+		rays := 0
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			// Seeded per pixel for reproducible images independently of the number of workers.
+			// A new generator per pixel (PCG, 2 small allocations) is ~40ns, noise next to
+			// the microseconds of each ray (see BenchmarkRandPerPixel): not worth reseeding
+			// a per worker one, which fortio.org/rand doesn't allow anyway.
+			rng := rand.NewIdx(idx+y*t.width+x, t.Seed)
+			if adaptive {
+				c, n := t.adaptivePixel(rng, scene, scratch, x, y, rrDepth)
+				t.setPixel(x, y, c)
+				rays += n
+				continue
+			}
 			// Compute ray for pixel (x, y)
 			// Multiple rays per pixel for antialiasing (alternative from scaling the image up/down).
 			colorSum := ColorF{0, 0, 0}
-			for range t.NumRaysPerPixel {
-				// Sub-pixel offset for antialiasing
-				offsetX, offsetY := 0.0, 0.0 // Default to pixel center (0,0)
-				if multipleRays {
-					// Random offset within pixel for antialiasing
-					offsetX, offsetY = rng.InDisc(t.RayRadius)
+			weights := 0.0
+			var offsetX, offsetY, lensX, lensY float64
+			for s := range t.NumRaysPerPixel {
+				var c ColorF
+				if antithetic && s%2 == 1 {
+					// Same sub-pixel offset as the previous ray, through the opposite lens point.
+					c = t.trace(t.Camera.rayThrough(rng, float64(x), float64(y), offsetX, offsetY, -lensX, -lensY), scene, scratch, rrDepth)
+				} else {
+					cell := s
+					if antithetic {
+						cell = s / 2 // the pairs share the strata
+					}
+					// Sub-pixel offset for antialiasing
+					offsetX, offsetY = 0.0, 0.0 // Default to pixel center (0,0)
+					switch {
+					case strata > 0:
+						offsetX, offsetY = stratifiedOffset(rng, cell%strata, cell/strata, strata, t.RayRadius)
+					case multipleRays:
+						// Random offset within pixel for antialiasing
+						offsetX, offsetY = rng.InDisc(t.RayRadius)
+					}
+					if antithetic {
+						lensX, lensY = t.Camera.lensSample(rng)
+						c = t.trace(t.Camera.rayThrough(rng, float64(x), float64(y), offsetX, offsetY, lensX, lensY), scene, scratch, rrDepth)
+					} else {
+						c = t.sample(rng, scene, scratch, x, y, offsetX, offsetY, rrDepth)
+					}
 				}
-				// Generate ray with depth of field (if Aperture > 0)
-				ray := t.Camera.GetRay(rng, float64(x), float64(y), offsetX, offsetY)
-				color := scene.RayColor(ray, t.MaxDepth)
-				colorSum = Add(colorSum, color)
+				weight := t.Filter.Weight(offsetX, offsetY, t.RayRadius)
+				colorSum = AddScaled(colorSum, c, weight)
+				weights += weight
 			}
-			c := SMul(colorSum, colorSumDiv).ToSRGBA()
-			// inline SetRGBA for performance
-			off := t.imageData.PixOffset(x, y)
-			s := pix[off : off+4 : off+4]
-			s[0] = c.R
-			s[1] = c.G
-			s[2] = c.B
-			s[3] = 255
+			t.setPixel(x, y, SMul(colorSum, 1/weights))
 		}
+		t.addProgress(rect.Dx())
+		if t.ProgressFunc != nil {
+			if !adaptive {
+				rays = rect.Dx() // pixels
+			}
+			t.ProgressFunc(rays)
+		}
+	}
+}
+
+// accumulateRect adds one sample per pixel of rect to the accumulator and updates the
+// image with the average over the pass(es) so far.
+func (t *Tracer) accumulateRect(idx int, rect image.Rectangle, pass int, scene *Scene) {
+	div := 1.0 / float64(pass)
+	rrDepth := t.rrDepth()
+	scratch := &pathScratch{sampling: t.SamplingStrategy}
+	defer t.addStats(scratch)
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			i := y*t.width + x
+			rng := rand.NewIdx(idx+i, t.Seed)
+			// Always jitter within the pixel as the passes are averaged together.
+			offsetX, offsetY := rng.InDisc(t.RayRadius)
+			t.accum[i] = Add(t.accum[i], t.sample(rng, scene, scratch, x, y, offsetX, offsetY, rrDepth))
+			t.setPixel(x, y, SMul(t.accum[i], div))
+		}
+		t.addProgress(rect.Dx())
+		if t.ProgressFunc != nil {
+			t.ProgressFunc(rect.Dx())
+		}
+	}
+}
+
+// antithetic returns true if the rays of the pixels are traced in antithetic pairs: same
+// sub-pixel offset but mirrored lens points, so their depth of field errors mostly cancel
+// out. Needs Antithetic, a lens (perspective camera with Aperture > 0) symmetric about its
+// center and an even number of rays per pixel.
+func (t *Tracer) antithetic() bool {
+	return t.Antithetic && t.Aperture > 0 && !t.Orthographic && t.symmetricLens() && t.NumRaysPerPixel%2 == 0
+}
+
+// rrDepth returns the minimum depth for Russian roulette, 0 when it's disabled.
+func (t *Tracer) rrDepth() int {
+	if t.RussianRoulette {
+		return t.RussianRouletteDepth
+	}
+	return 0
+}
+
+// sample returns the color of one ray through pixel (x, y) at the given sub-pixel offset.
+func (t *Tracer) sample(rng rand.Rand, scene *Scene, scratch *pathScratch, x, y int, offsetX, offsetY float64, rrDepth int) ColorF {
+	// Generate ray with depth of field (if Aperture > 0)
+	ray := t.Camera.GetRay(rng, float64(x), float64(y), offsetX, offsetY)
+	return t.trace(ray, scene, scratch, rrDepth)
+}
+
+// trace returns the color of the given (camera) ray, with NaN and infinite channels
+// zeroed so they don't spread to the averaged pixel (see ColorF.Sanitize).
+func (t *Tracer) trace(ray *Ray, scene *Scene, scratch *pathScratch, rrDepth int) ColorF {
+	var c ColorF
+	if t.Shading != ShadingFull {
+		c = t.previewColor(ray, scene, scratch)
+	} else {
+		c = scene.rayColor(ray, t.MaxDepth, rrDepth, t.ShadowEpsilon, t.ClampIndirect, scratch)
+	}
+	if !c.IsFinite() {
+		nonFiniteWarning.Do(func() {
+			log.Warnf("Ray color %v isn't finite (NaN or infinite, broken material or object?), using 0 instead", c)
+		})
+		c = c.Sanitize()
+	}
+	return c
+}
+
+// nonFiniteWarning makes sure the non finite sample warning is logged only once.
+var nonFiniteWarning sync.Once
+
+// setPixel stores the linear color c, tone mapped and encoded (sRGB by default), at (x, y).
+func (t *Tracer) setPixel(x, y int, c ColorF) {
+	if t.hdr != nil {
+		t.hdr[y*t.width+x] = c
 	}
+	rgba := t.OutputEncoding.Encode(t.ToneMapper.Apply(c), t.Gamma)
+	// inline SetRGBA for performance
+	off := t.imageData.PixOffset(x, y)
+	s := t.imageData.Pix[off : off+4 : off+4]
+	s[0] = rgba.R
+	s[1] = rgba.G
+	s[2] = rgba.B
+	s[3] = 255
 }
@@ -1,9 +1,20 @@
 package ray
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
 	"runtime"
+	"slices"
+	"sync"
 	"sync/atomic"
 	"testing"
+	"time"
+
+	"fortio.org/rand"
 )
 
 func TestNew(t *testing.T) {
@@ -77,6 +88,18 @@ func TestRender_DefaultScene(t *testing.T) {
 	}
 }
 
+func TestRender_DefaultSceneCamera(t *testing.T) {
+	implicit := New(32, 18)
+	implicit.Seed = 42
+	expected := implicit.Render(nil)
+	explicit := New(32, 18)
+	explicit.Seed = 42
+	explicit.Camera = *DefaultSceneCamera()
+	if img := explicit.Render(DefaultScene()); !slices.Equal(img.Pix, expected.Pix) {
+		t.Error("Render(DefaultScene()) with DefaultSceneCamera() differs from Render(nil)")
+	}
+}
+
 func TestRender_CustomScene(t *testing.T) {
 	tracer := New(5, 5)
 	scene := &Scene{
@@ -192,21 +215,48 @@ func TestRender_ParallelRendering(t *testing.T) {
 		numWorkers int
 		width      int
 		height     int
+		tileSize   int
 	}{
-		{"single_worker", 1, 10, 10},
-		{"two_workers", 2, 10, 10},
-		{"more_workers_than_rows", 20, 10, 5},
+		{"single_worker", 1, 10, 10, 0},
+		{"two_workers", 2, 10, 10, 0},
+		{"more_workers_than_rows", 20, 10, 5, 0},
+		{"more_workers_than_tiles", 64, 12, 9, 4},
+		{"many_more_workers_than_tiles", 500, 3, 5, 1},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tracer := New(tt.width, tt.height)
 			tracer.NumWorkers = tt.numWorkers
+			tracer.TileSize = tt.tileSize
+			tracer.EnableStats = true
+			// Each pixel is rendered exactly once, by no more workers than there are tiles.
+			var mu sync.Mutex
+			rendered := make([]int, tt.width*tt.height)
+			tiles := 0
+			tracer.OnTileComplete = func(tile image.Rectangle, _ time.Duration) {
+				mu.Lock()
+				defer mu.Unlock()
+				tiles++
+				for y := tile.Min.Y; y < tile.Max.Y; y++ {
+					for x := tile.Min.X; x < tile.Max.X; x++ {
+						rendered[y*tt.width+x]++
+					}
+				}
+			}
 			img := tracer.Render(DefaultScene())
 
 			if img == nil {
 				t.Fatal("Render() returned nil")
 			}
+			for i, n := range rendered {
+				if n != 1 {
+					t.Errorf("pixel (%d,%d) rendered %d times, want 1", i%tt.width, i/tt.width, n)
+				}
+			}
+			if want := int64(min(tt.numWorkers, tiles)); tracer.Stats.Workers != want {
+				t.Errorf("%d workers started for %d tiles, want %d", tracer.Stats.Workers, tiles, want)
+			}
 
 			// Verify all pixels have been set (non-zero alpha)
 			for y := range tt.height {
@@ -319,3 +369,666 @@ func TestRender_EmptyScene(t *testing.T) {
 		}
 	}
 }
+
+func TestRender_NoBackground(t *testing.T) {
+	tracer := New(5, 5)
+	scene := &Scene{Objects: []Hittable{}, Background: NoBackground}
+	img := tracer.Render(scene)
+
+	if scene.Background != NoBackground {
+		t.Errorf("Background = %v, want it left as NoBackground", scene.Background)
+	}
+	// Nothing to see and no light: all pixels should be black.
+	for y := range 5 {
+		for x := range 5 {
+			r, g, b, a := img.At(x, y).RGBA()
+			if r != 0 || g != 0 || b != 0 || a != 0xffff {
+				t.Errorf("pixel (%d,%d) = %v, want opaque black", x, y, img.At(x, y))
+			}
+		}
+	}
+}
+
+func meanBrightness(t *testing.T, rr bool) float64 {
+	t.Helper()
+	tracer := New(16, 16)
+	tracer.Seed = 42
+	tracer.NumRaysPerPixel = 64
+	tracer.MaxDepth = 20
+	tracer.RussianRoulette = rr
+	tracer.RussianRouletteDepth = 2
+	img := tracer.Render(nil)
+	sum := 0.0
+	for i, v := range img.Pix {
+		if i%4 != 3 { // skip alpha
+			sum += float64(v)
+		}
+	}
+	return sum / float64(3*16*16)
+}
+
+func TestRender_RussianRoulette(t *testing.T) {
+	plain := meanBrightness(t, false)
+	rr := meanBrightness(t, true)
+	if diff := math.Abs(rr-plain) / plain; diff > 0.02 {
+		t.Errorf("mean brightness with Russian roulette = %.2f, without = %.2f (%.1f%% off), want within 2%%",
+			rr, plain, 100*diff)
+	}
+}
+
+func TestRenderContext(t *testing.T) {
+	tracer := New(8, 8)
+	img, err := tracer.RenderContext(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("RenderContext() error = %v, want nil", err)
+	}
+	if img != tracer.imageData {
+		t.Error("RenderContext() should return the tracer's imageData")
+	}
+}
+
+func TestSetImageBuffer(t *testing.T) {
+	const w, h = 20, 12
+	render := func(rt *Tracer, seed uint64) []byte {
+		rt.Seed = seed
+		rt.NumRaysPerPixel = 2
+		return slices.Clone(rt.Render(nil).Pix)
+	}
+	want1, want2 := render(New(w, h), 1), render(New(w, h), 2)
+	buf := image.NewRGBA(image.Rect(0, 0, w, h))
+	rt := New(w, h)
+	if err := rt.SetImageBuffer(buf); err != nil {
+		t.Fatalf("SetImageBuffer() error: %v", err)
+	}
+	rt.Seed = 1
+	rt.NumRaysPerPixel = 2
+	for i, want := range [][]byte{want1, want2} {
+		img := rt.Render(nil)
+		if img != buf {
+			t.Fatalf("frame %d: Render() didn't return the buffer", i+1)
+		}
+		if !slices.Equal(img.Pix, want) {
+			t.Errorf("frame %d: image rendered into the buffer differs from a fresh render", i+1)
+		}
+		rt.Seed = 2
+	}
+	// A sub-image of a larger one, at the origin (so with a larger stride), works too.
+	big := image.NewRGBA(image.Rect(0, 0, 2*w, h))
+	sub := big.SubImage(image.Rect(0, 0, w, h)).(*image.RGBA)
+	rt = New(w, h)
+	if err := rt.SetImageBuffer(sub); err != nil {
+		t.Fatalf("SetImageBuffer(sub image) error: %v", err)
+	}
+	rt.Seed = 1
+	rt.NumRaysPerPixel = 2
+	img := rt.Render(nil)
+	for y := range h {
+		if !slices.Equal(img.Pix[y*img.Stride:y*img.Stride+4*w], want1[4*w*y:4*w*(y+1)]) {
+			t.Errorf("sub image line %d differs from a fresh render", y)
+		}
+	}
+	for i, bad := range []*image.RGBA{nil, image.NewRGBA(image.Rect(0, 0, w, h+1)), image.NewRGBA(image.Rect(1, 0, w+1, h))} {
+		if err := New(w, h).SetImageBuffer(bad); err == nil {
+			t.Errorf("SetImageBuffer() of bad image %d should fail", i)
+		}
+	}
+}
+
+func TestRenderContext_Canceled(t *testing.T) {
+	for _, workers := range []int{1, 4} {
+		t.Run(fmt.Sprintf("workers=%d", workers), func(t *testing.T) {
+			tracer := New(10, 40)
+			tracer.NumWorkers = workers
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			var lines atomic.Int32
+			tracer.ProgressFunc = func(_ int) {
+				if lines.Add(1) == 1 {
+					cancel() // stop right after the first line starts rendering
+				}
+			}
+			img, err := tracer.RenderContext(ctx, nil)
+			if !errors.Is(err, context.Canceled) {
+				t.Fatalf("RenderContext() error = %v, want %v", err, context.Canceled)
+			}
+			// The partial image is returned: first line rendered, last one untouched.
+			if _, _, _, a := img.At(0, 0).RGBA(); a == 0 {
+				t.Error("first line should have been rendered")
+			}
+			if _, _, _, a := img.At(0, 39).RGBA(); a != 0 {
+				t.Error("last line should not have been rendered")
+			}
+			if n := lines.Load(); n >= 40 {
+				t.Errorf("rendered %d lines, want fewer than 40 after cancel", n)
+			}
+		})
+	}
+}
+
+func TestRenderProgressive(t *testing.T) {
+	tracer := New(8, 8)
+	tracer.NumRaysPerPixel = 5
+	var passes []int
+	img := tracer.RenderProgressive(nil, func(img *image.RGBA, pass int) bool {
+		if img != tracer.imageData {
+			t.Error("onPass should get the tracer's imageData")
+		}
+		passes = append(passes, pass)
+		return true
+	})
+	if img != tracer.imageData {
+		t.Error("RenderProgressive() should return the tracer's imageData")
+	}
+	if want := []int{1, 2, 3, 4, 5}; !slices.Equal(passes, want) {
+		t.Errorf("passes = %v, want %v", passes, want)
+	}
+	for y := range 8 {
+		for x := range 8 {
+			if _, _, _, a := img.At(x, y).RGBA(); a != 0xffff {
+				t.Errorf("pixel (%d,%d) not rendered", x, y)
+			}
+		}
+	}
+}
+
+func TestRenderProgressive_Stop(t *testing.T) {
+	tracer := New(8, 8)
+	tracer.NumRaysPerPixel = 10
+	var lines atomic.Int32
+	tracer.ProgressFunc = func(_ int) { lines.Add(1) }
+	last := 0
+	tracer.RenderProgressive(nil, func(_ *image.RGBA, pass int) bool {
+		last = pass
+		return pass < 3
+	})
+	if last != 3 {
+		t.Errorf("last pass = %d, want 3", last)
+	}
+	if n := lines.Load(); n != 3*8 {
+		t.Errorf("rendered %d lines, want %d", n, 3*8)
+	}
+}
+
+func TestRenderProgressive_Converges(t *testing.T) {
+	// The progressive average should match a regular render with as many rays per pixel.
+	render := func(progressive bool) float64 {
+		tracer := New(16, 16)
+		tracer.Seed = 42
+		tracer.NumRaysPerPixel = 32
+		var img *image.RGBA
+		if progressive {
+			img = tracer.RenderProgressive(nil, nil)
+		} else {
+			img = tracer.Render(nil)
+		}
+		sum := 0.0
+		for i, v := range img.Pix {
+			if i%4 != 3 {
+				sum += float64(v)
+			}
+		}
+		return sum / float64(3*16*16)
+	}
+	regular, progressive := render(false), render(true)
+	if diff := math.Abs(progressive-regular) / regular; diff > 0.02 {
+		t.Errorf("progressive mean brightness = %.2f, regular = %.2f, want within 2%%", progressive, regular)
+	}
+}
+
+func TestRenderWithin(t *testing.T) {
+	newTracer := func() *Tracer {
+		tracer := New(16, 16)
+		tracer.Seed = 42
+		return tracer
+	}
+	if _, passes := newTracer().RenderWithin(nil, 0); passes != 1 {
+		t.Errorf("RenderWithin(0) did %d passes, want 1", passes)
+	}
+	const budget = 100 * time.Millisecond
+	start := time.Now()
+	img, passes := newTracer().RenderWithin(nil, budget)
+	if elapsed := time.Since(start); passes < 2 || elapsed > 2*budget {
+		t.Errorf("RenderWithin(%v) did %d passes in %v, want several within the budget", budget, passes, elapsed)
+	}
+	// Same image as a progressive render of that many passes.
+	expected := newTracer()
+	expected.NumRaysPerPixel = passes
+	if !slices.Equal(img.Pix, expected.RenderProgressive(nil, nil).Pix) {
+		t.Errorf("RenderWithin() image differs from RenderProgressive() with %d rays per pixel", passes)
+	}
+}
+
+func TestNew_ZeroSize(t *testing.T) {
+	rt := New(0, 0)
+	img := rt.Render(nil)
+	if b := img.Bounds(); b.Dx() != 1 || b.Dy() != 1 {
+		t.Fatalf("New(0, 0) image bounds = %v, want 1x1", b)
+	}
+	if hasNaN(rt.u, rt.v, rt.w, rt.pixel00, rt.pixelXVector, rt.pixelYVector) {
+		t.Errorf("New(0, 0) camera has NaN/Inf vectors: %+v", rt.Camera)
+	}
+}
+
+// rampTexture varies smoothly with the position, so blurring it by depth of field is
+// noisy unlike a solid color.
+type rampTexture struct{}
+
+func (rampTexture) Value(_, _ float64, p Vec3) ColorF {
+	f := 0.5 + 0.02*p.x + 0.01*p.y + 0.05*math.Sin(p.x)
+	return ColorF{f, f, f}
+}
+
+func TestRender_Antithetic(t *testing.T) {
+	// A far away plane, very out of focus.
+	scene := &Scene{
+		Objects:    []Hittable{NewQuad(Vec3{-20, -20, -10}, Vec3{40, 0, 0}, Vec3{0, 40, 0}, Lambertian{Tex: rampTexture{}})},
+		Background: NoBackground,
+		Lights:     []Light{DirectionalLight{Direction: Vec3{0, 0, -1}, Color: ColorF{1, 1, 1}}},
+	}
+	render := func(rays int, antithetic bool, seed uint64) []ColorF {
+		rt := New(24, 24)
+		rt.Seed = seed
+		rt.MaxDepth = 1 // direct light only: the lens is the only source of noise
+		rt.NumRaysPerPixel = rays
+		rt.Antithetic = antithetic
+		rt.KeepHDR = true
+		rt.Camera = Camera{Aperture: 1, FocusDistance: 1}
+		rt.Render(scene)
+		return rt.HDRBuffer()
+	}
+	reference := render(1024, false, 1)
+	mse := func(img []ColorF) float64 {
+		sum := 0.0
+		for i, c := range img {
+			d := c.x - reference[i].x
+			sum += d * d
+		}
+		return sum / float64(len(img))
+	}
+	independent, antithetic := 0.0, 0.0
+	for seed := range uint64(4) {
+		independent += mse(render(4, false, seed+2))
+		antithetic += mse(render(4, true, seed+2))
+	}
+	t.Logf("mean squared error: independent %g, antithetic %g", independent/4, antithetic/4)
+	if antithetic > independent/2 {
+		t.Errorf("antithetic sampling error %g isn't much lower than independent sampling's %g", antithetic/4, independent/4)
+	}
+	// Odd number of rays: Antithetic is ignored.
+	if !slices.Equal(render(3, true, 5), render(3, false, 5)) {
+		t.Errorf("Antithetic changed the image with an odd number of rays per pixel")
+	}
+}
+
+func TestRender_ClampIndirect(t *testing.T) {
+	// A small, very bright light above a white floor: the floor is only lit through
+	// bounces and the few samples that find the light are fireflies.
+	floor := NewQuad(Vec3{-5, 0, 5}, Vec3{10, 0, 0}, Vec3{0, 0, -10}, Lambertian{Albedo: ColorF{1, 1, 1}})
+	light := &Sphere{Center: Vec3{0, 1, 0}, Radius: 0.05, Mat: DiffuseLight{Emit: ColorF{1000, 1000, 1000}}}
+	scene := &Scene{Objects: []Hittable{floor, light}, Background: NoBackground}
+	maxLuminance := func(clamp float64) float64 {
+		rt := New(32, 32)
+		rt.Seed = 42
+		rt.NumRaysPerPixel = 1 // so the HDR pixels are the samples
+		rt.KeepHDR = true
+		rt.ClampIndirect = clamp
+		// Looking down at the floor from under the light, which isn't directly visible.
+		rt.Camera = Camera{Position: Vec3{0, 0.5, 0}, LookAt: Vec3{0, 0, 0}, Up: Vec3{0, 0, -1}, VerticalFoV: 60}
+		rt.Render(scene)
+		m := 0.0
+		for _, c := range rt.HDRBuffer() {
+			m = math.Max(m, Luminance(c))
+		}
+		return m
+	}
+	if m := maxLuminance(0); m <= 10 {
+		t.Errorf("max sample luminance without clamping = %v, want fireflies > 10", m)
+	}
+	for _, clamp := range []float64{0.5, 2, 10} {
+		if m := maxLuminance(clamp); m > clamp*(1+1e-9) {
+			t.Errorf("max sample luminance with ClampIndirect %v = %v, want <= %v", clamp, m, clamp)
+		}
+	}
+}
+
+func TestRender_ClampIndirectKeepsDirect(t *testing.T) {
+	// Directly visible emitters aren't clamped.
+	scene := &Scene{
+		Objects:    []Hittable{&Sphere{Center: Vec3{0, 0, -1}, Radius: 0.5, Mat: DiffuseLight{Emit: ColorF{4, 2, 1}}}},
+		Background: NoBackground,
+	}
+	rt := New(8, 8)
+	rt.Seed = 42
+	rt.KeepHDR = true
+	rt.ClampIndirect = 0.1
+	rt.Render(scene)
+	center := rt.HDRBuffer()[4*8+4]
+	if center != (ColorF{4, 2, 1}) {
+		t.Errorf("emitter seen with ClampIndirect = %v, want %v", center, ColorF{4, 2, 1})
+	}
+}
+
+func TestRender_ClampIndirectDepth1(t *testing.T) {
+	// An emitter that also scatters, all around the camera: with MaxDepth 1 the paths end
+	// right after the first hit, so all their light is direct and isn't clamped.
+	glowing := MixMaterial{A: DiffuseLight{Emit: ColorF{8, 8, 8}}, B: Metal{Albedo: ColorF{1, 1, 1}}, Ratio: 0.5}
+	scene := &Scene{Objects: []Hittable{NewSphere(Vec3{}, 10, glowing)}, Background: NoBackground}
+	rt := New(8, 8)
+	rt.Seed = 42
+	rt.MaxDepth = 1
+	rt.KeepHDR = true
+	rt.ClampIndirect = 0.1
+	rt.Render(scene)
+	for i, c := range rt.HDRBuffer() {
+		if c != (ColorF{4, 4, 4}) {
+			t.Fatalf("pixel %d with MaxDepth 1 and ClampIndirect = %v, want %v", i, c, ColorF{4, 4, 4})
+		}
+	}
+}
+
+func TestRender_NaNIsBlack(t *testing.T) {
+	// A broken emitter all around the camera: every sample is NaN (or infinite).
+	nan := math.NaN()
+	scene := &Scene{Objects: []Hittable{NewSphere(Vec3{}, 10, DiffuseLight{Emit: ColorF{nan, math.Inf(1), nan}})}}
+	rt := New(4, 4)
+	rt.Seed = 42
+	rt.NumRaysPerPixel = 4
+	rt.KeepHDR = true
+	img := rt.Render(scene)
+	for i, c := range rt.HDRBuffer() {
+		if c != (ColorF{}) {
+			t.Fatalf("HDR pixel %d = %v, want black", i, c)
+		}
+	}
+	for y := range 4 {
+		for x := range 4 {
+			if c := img.RGBAAt(x, y); c != (color.RGBA{0, 0, 0, 255}) {
+				t.Fatalf("pixel %d,%d = %v, want black", x, y, c)
+			}
+		}
+	}
+}
+
+func TestRenderStream(t *testing.T) {
+	for _, workers := range []int{1, 4} {
+		t.Run(fmt.Sprintf("workers=%d", workers), func(t *testing.T) {
+			const w, h = 7, 20
+			expected := New(w, h)
+			expected.Seed = 42
+			expected.NumWorkers = workers
+			want := expected.Render(nil)
+
+			tracer := New(w, h)
+			tracer.Seed = 42
+			tracer.NumWorkers = workers
+			seen := make([]bool, h)
+			got := image.NewRGBA(image.Rect(0, 0, w, h))
+			for row := range tracer.RenderStream(nil) {
+				if seen[row.Y] {
+					t.Errorf("row %d received twice", row.Y)
+				}
+				seen[row.Y] = true
+				if len(row.Pix) != 4*w {
+					t.Fatalf("row %d has %d bytes, want %d", row.Y, len(row.Pix), 4*w)
+				}
+				copy(got.Pix[got.PixOffset(0, row.Y):], row.Pix)
+			}
+			for y, ok := range seen {
+				if !ok {
+					t.Errorf("row %d never received", y)
+				}
+			}
+			if !slices.Equal(got.Pix, want.Pix) {
+				t.Error("streamed rows differ from Render() image")
+			}
+		})
+	}
+}
+
+func TestRenderStream_SingleWorkerWideImage(t *testing.T) {
+	// Several tiles per band: each row must still be sent once, after all of them are done.
+	const w, h = 40, 20
+	expected := New(w, h)
+	expected.Seed = 42
+	expected.NumWorkers = 1
+	expected.TileSize = 16
+	want := expected.Render(nil)
+
+	tracer := New(w, h)
+	tracer.Seed = 42
+	tracer.NumWorkers = 1
+	tracer.TileSize = 16
+	count := make([]int, h)
+	for row := range tracer.RenderStream(nil) {
+		count[row.Y]++
+		off := want.PixOffset(0, row.Y)
+		if !slices.Equal(row.Pix, want.Pix[off:off+4*w]) {
+			t.Errorf("row %d sent before being fully rendered", row.Y)
+		}
+	}
+	for y, n := range count {
+		if n != 1 {
+			t.Errorf("row %d received %d times, want 1", y, n)
+		}
+	}
+}
+
+func TestRenderRegion(t *testing.T) {
+	tests := []struct {
+		name     string
+		region   image.Rectangle
+		expected image.Rectangle // rendered part after clipping
+	}{
+		{"inside", image.Rect(2, 3, 6, 9), image.Rect(2, 3, 6, 9)},
+		{"clipped", image.Rect(-5, 7, 4, 50), image.Rect(0, 7, 4, 12)},
+		{"outside", image.Rect(20, 20, 30, 30), image.Rectangle{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracer := New(10, 12)
+			tracer.NumWorkers = 2
+			var pixels atomic.Int32
+			tracer.ProgressFunc = func(n int) { pixels.Add(int32(n)) }
+			img := tracer.RenderRegion(nil, tt.region)
+			if img != tracer.imageData {
+				t.Error("RenderRegion() should return the tracer's imageData")
+			}
+			for y := range 12 {
+				for x := range 10 {
+					_, _, _, a := img.At(x, y).RGBA()
+					inside := image.Pt(x, y).In(tt.expected)
+					if inside != (a != 0) {
+						t.Errorf("pixel (%d,%d) rendered = %v, want %v", x, y, a != 0, inside)
+					}
+				}
+			}
+			if n, want := int(pixels.Load()), tt.expected.Dx()*tt.expected.Dy(); n != want {
+				t.Errorf("progress reported %d pixels, want %d", n, want)
+			}
+		})
+	}
+}
+
+func TestPrimaryRay(t *testing.T) {
+	tracer := New(21, 11)
+	tracer.Aperture = 1 // ignored
+	r := tracer.PrimaryRay(10, 5)
+	if r.Origin != (Vec3{}) {
+		t.Errorf("PrimaryRay() origin = %v, want the camera position", r.Origin)
+	}
+	if d := Unit(r.Direction); !Equal(d, Vec3{0, 0, -1}, 1e-9) {
+		t.Errorf("PrimaryRay(center) direction = %v, want {0 0 -1}", d)
+	}
+	// Top left pixel goes up and left.
+	if d := tracer.PrimaryRay(0, 0).Direction; d.x >= 0 || d.y <= 0 {
+		t.Errorf("PrimaryRay(0, 0) direction = %v, want up and left", d)
+	}
+	// Picking: the sphere in the middle of the default scene.
+	scene := DefaultScene()
+	tracer = New(40, 20)
+	tracer.Render(scene)
+	obj, _, ok := scene.HitObject(tracer.PrimaryRay(20, 10))
+	if !ok || obj != scene.Objects[0] {
+		t.Errorf("HitObject(PrimaryRay(center)) = %v, %v; want the center sphere %v", obj, ok, scene.Objects[0])
+	}
+}
+
+func TestRender_DeterministicAcrossWorkers(t *testing.T) {
+	render := func(workers int) []uint8 {
+		tracer := New(45, 37) // not a multiple of the tile size, for partial edge tiles
+		tracer.Seed = 42
+		tracer.NumRaysPerPixel = 4
+		tracer.TileSize = 8
+		tracer.NumWorkers = workers
+		return tracer.Render(nil).Pix
+	}
+	expected := render(1)
+	for _, workers := range []int{2, 3, 4, 5, 6, 7, 8, 16} {
+		if !slices.Equal(render(workers), expected) {
+			t.Errorf("image rendered with %d workers differs from the single worker one", workers)
+		}
+	}
+	// Also when rendering the lines in arbitrary groups.
+	tracer := New(45, 37)
+	tracer.Seed = 42
+	tracer.NumRaysPerPixel = 4
+	tracer.NumWorkers = 1
+	tracer.Render(nil)
+	clear(tracer.imageData.Pix)
+	for _, lines := range [][2]int{{20, 37}, {0, 5}, {5, 20}} {
+		tracer.RenderLines(0, lines[0], lines[1], DefaultScene())
+	}
+	if !slices.Equal(tracer.imageData.Pix, expected) {
+		t.Error("image rendered with RenderLines in pieces differs from Render")
+	}
+}
+
+func TestRender_Tiles(t *testing.T) {
+	// Tile size (and workers) don't change the image, including partial edge tiles.
+	render := func(tileSize, workers int) []uint8 {
+		tracer := New(37, 23)
+		tracer.Seed = 42
+		tracer.TileSize = tileSize
+		tracer.NumWorkers = workers
+		return tracer.Render(nil).Pix
+	}
+	expected := render(0, 1)
+	for _, tileSize := range []int{1, 5, 16, 64} {
+		if !slices.Equal(render(tileSize, 4), expected) {
+			t.Errorf("image rendered with %d pixels tiles differs", tileSize)
+		}
+	}
+}
+
+func TestRender_OnTileComplete(t *testing.T) {
+	for _, workers := range []int{1, 3} {
+		tracer := New(37, 23)
+		tracer.TileSize = 8
+		tracer.NumWorkers = workers
+		var mu sync.Mutex
+		covered := make([]int, 37*23)
+		tiles := 0
+		tracer.OnTileComplete = func(tile image.Rectangle, dur time.Duration) {
+			mu.Lock()
+			defer mu.Unlock()
+			tiles++
+			if dur <= 0 || tile.Dx() > 8 || tile.Dy() > 8 {
+				t.Errorf("OnTileComplete(%v, %v), want a tile of at most 8x8 and a duration", tile, dur)
+			}
+			for y := tile.Min.Y; y < tile.Max.Y; y++ {
+				for x := tile.Min.X; x < tile.Max.X; x++ {
+					covered[y*37+x]++
+				}
+			}
+		}
+		tracer.Render(nil)
+		if tiles != 5*3 {
+			t.Errorf("%d workers: OnTileComplete called %d times, want %d", workers, tiles, 5*3)
+		}
+		for i, n := range covered {
+			if n != 1 {
+				t.Fatalf("%d workers: pixel %d covered by %d tiles, want 1", workers, i, n)
+			}
+		}
+	}
+}
+
+// idleFraction simulates workers taking jobs (of the given costs) in order from a shared
+// queue and returns the fraction of the total worker time spent idle waiting for the last one.
+func idleFraction(costs []time.Duration, workers int) float64 {
+	finish := make([]time.Duration, workers)
+	var total time.Duration
+	for _, c := range costs {
+		first := slices.Index(finish, slices.Min(finish))
+		finish[first] += c
+		total += c
+	}
+	return 1 - float64(total)/float64(time.Duration(workers)*slices.Max(finish))
+}
+
+// BenchmarkRender_LoadBalance measures the cost of each band (the previous fixed height
+// chunks) and each tile of RichScene, and reports the simulated idle time of 8 workers.
+func BenchmarkRender_LoadBalance(b *testing.B) {
+	const workers = 8
+	tracer := New(240, 135)
+	tracer.Seed = 7
+	tracer.NumRaysPerPixel = 4
+	tracer.Camera = *RichSceneCamera()
+	scene := tracer.setup(RichScene(rand.New(7)))
+	timeRect := func(r image.Rectangle) time.Duration {
+		start := time.Now()
+		tracer.renderRect(context.Background(), 0, r, scene)
+		return time.Since(start)
+	}
+	bandHeight := max(4, tracer.height/(workers*4))
+	var bandIdle, tileIdle float64
+	for b.Loop() {
+		var bands, tiles []time.Duration
+		for y := 0; y < tracer.height; y += bandHeight {
+			bands = append(bands, timeRect(image.Rect(0, y, tracer.width, y+bandHeight).Intersect(tracer.imageData.Bounds())))
+		}
+		for y := 0; y < tracer.height; y += tracer.TileSize {
+			for x := 0; x < tracer.width; x += tracer.TileSize {
+				tiles = append(tiles, timeRect(image.Rect(x, y, x+tracer.TileSize, y+tracer.TileSize).Intersect(tracer.imageData.Bounds())))
+			}
+		}
+		bandIdle += idleFraction(bands, workers)
+		tileIdle += idleFraction(tiles, workers)
+	}
+	b.ReportMetric(100*bandIdle/float64(b.N), "band-idle-%")
+	b.ReportMetric(100*tileIdle/float64(b.N), "tile-idle-%")
+}
+
+func BenchmarkRender_RichScene(b *testing.B) {
+	scene := RichScene(rand.New(7))
+	tracer := New(80, 45)
+	tracer.Seed = 7
+	tracer.NumWorkers = 1
+	tracer.NumRaysPerPixel = 4
+	tracer.MaxDepth = 20
+	tracer.Camera = *RichSceneCamera()
+	b.ReportAllocs()
+	for b.Loop() {
+		tracer.Render(scene)
+	}
+	b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*80*45*4), "ns/ray")
+}
+
+// BenchmarkRandPerPixel is the cost of the random generator each pixel creates (see
+// renderRect), to compare with the ns/ray of BenchmarkRender_RichScene.
+func BenchmarkRandPerPixel(b *testing.B) {
+	b.ReportAllocs()
+	i := 0
+	for b.Loop() {
+		_ = rand.NewIdx(i, 7).Float64()
+		i++
+	}
+}
+
+// BenchmarkRandFloat64 is the cost of each random number, e.g. drawn by Scatter.
+func BenchmarkRandFloat64(b *testing.B) {
+	rng := rand.NewIdx(1, 7)
+	for b.Loop() {
+		_ = rng.Float64()
+	}
+}
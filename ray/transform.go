@@ -0,0 +1,88 @@
+package ray
+
+import "math"
+
+// Translate is an instance of Object moved by Offset.
+type Translate struct {
+	Object Hittable
+	Offset Vec3
+}
+
+func (t *Translate) Hit(r *Ray, i Interval, hr *HitRecord) bool {
+	// Move the ray backwards by the offset (into object space).
+	offsetRay := Ray{Rand: r.Rand, Origin: Sub(r.Origin, t.Offset), Direction: r.Direction, Time: r.Time, Channel: r.Channel}
+	if !t.Object.Hit(&offsetRay, i, hr) {
+		return false
+	}
+	// Move the intersection point forwards by the offset (back to world space).
+	hr.Point = Add(hr.Point, t.Offset)
+	return true
+}
+
+func (t *Translate) BoundingBox() AABB {
+	box := t.Object.BoundingBox()
+	return AABB{
+		X: Interval{Start: box.X.Start + t.Offset.x, End: box.X.End + t.Offset.x},
+		Y: Interval{Start: box.Y.Start + t.Offset.y, End: box.Y.End + t.Offset.y},
+		Z: Interval{Start: box.Z.Start + t.Offset.z, End: box.Z.End + t.Offset.z},
+	}
+}
+
+// RotateY is an instance of Object rotated by Angle degrees around the Y axis
+// (counterclockwise when looking down from +Y). Use NewRotateY to create one,
+// as it needs precomputed values.
+type RotateY struct {
+	Object Hittable
+	Angle  float64 // in degrees.
+	// Computed fields (initialized by NewRotateY)
+	sinTheta, cosTheta float64
+	bbox               AABB
+}
+
+// NewRotateY creates a RotateY instance of object rotated by angle degrees around the Y axis.
+func NewRotateY(object Hittable, angle float64) *RotateY {
+	radians := angle * (math.Pi / 180.0)
+	ry := &RotateY{
+		Object:   object,
+		Angle:    angle,
+		sinTheta: math.Sin(radians),
+		cosTheta: math.Cos(radians),
+	}
+	// The rotated box is the box around the 8 rotated corners of the original one.
+	box := object.BoundingBox()
+	ry.bbox = EmptyAABB
+	for _, x := range []float64{box.X.Start, box.X.End} {
+		for _, y := range []float64{box.Y.Start, box.Y.End} {
+			for _, z := range []float64{box.Z.Start, box.Z.End} {
+				p := ry.toWorld(Vec3{x, y, z})
+				ry.bbox = UnionAABB(ry.bbox, NewAABB(p, p))
+			}
+		}
+	}
+	return ry
+}
+
+// toObject rotates v by -Angle (world to object space).
+func (ry *RotateY) toObject(v Vec3) Vec3 {
+	return Vec3{ry.cosTheta*v.x - ry.sinTheta*v.z, v.y, ry.sinTheta*v.x + ry.cosTheta*v.z}
+}
+
+// toWorld rotates v by Angle (object to world space).
+func (ry *RotateY) toWorld(v Vec3) Vec3 {
+	return Vec3{ry.cosTheta*v.x + ry.sinTheta*v.z, v.y, -ry.sinTheta*v.x + ry.cosTheta*v.z}
+}
+
+func (ry *RotateY) Hit(r *Ray, i Interval, hr *HitRecord) bool {
+	rotatedRay := Ray{Rand: r.Rand, Origin: ry.toObject(r.Origin), Direction: ry.toObject(r.Direction), Time: r.Time, Channel: r.Channel}
+	if !ry.Object.Hit(&rotatedRay, i, hr) {
+		return false
+	}
+	hr.Point = ry.toWorld(hr.Point)
+	hr.Normal = ry.toWorld(hr.Normal)
+	hr.Tangent = ry.toWorld(hr.Tangent)
+	return true
+}
+
+func (ry *RotateY) BoundingBox() AABB {
+	return ry.bbox
+}
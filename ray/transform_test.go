@@ -0,0 +1,83 @@
+package ray
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTranslate(t *testing.T) {
+	rnd := RandForTests()
+	sphere := &Sphere{Center: Vec3{0, 0, 0}, Radius: 0.5, Mat: Lambertian{}}
+	moved := &Translate{Object: sphere, Offset: Vec3{1, 2, -3}}
+
+	hit, rec := testHit(moved, NewRay(rnd, Vec3{1, 2, 0}, Vec3{0, 0, -1}), FrontEpsilon)
+	if !hit {
+		t.Fatal("Expected hit on the translated sphere")
+	}
+	if !Equal(rec.Point, Vec3{1, 2, -2.5}, 1e-10) {
+		t.Errorf("Expected hit point {1, 2, -2.5}, got %v", rec.Point)
+	}
+	if !Equal(rec.Normal, Vec3{0, 0, 1}, 1e-10) {
+		t.Errorf("Expected normal {0, 0, 1}, got %v", rec.Normal)
+	}
+	if hit, _ := testHit(moved, NewRay(rnd, Vec3{0, 0, 5}, Vec3{0, 0, -1}), FrontEpsilon); hit {
+		t.Error("Expected no hit at the original position")
+	}
+	expected := NewAABB(Vec3{0.5, 1.5, -3.5}, Vec3{1.5, 2.5, -2.5})
+	if box := moved.BoundingBox(); box != expected {
+		t.Errorf("BoundingBox() = %v, want %v", box, expected)
+	}
+}
+
+func TestRotateY(t *testing.T) {
+	rnd := RandForTests()
+	// Thin quad in the z=0 plane, facing +Z, spanning x in [0, 2].
+	quad := NewQuad(Vec3{0, -1, 0}, Vec3{2, 0, 0}, Vec3{0, 2, 0}, Lambertian{})
+	// Rotated by 90 degrees: now spans z in [-2, 0] facing +X.
+	rotated := NewRotateY(quad, 90)
+
+	hit, rec := testHit(rotated, NewRay(rnd, Vec3{5, 0, -1}, Vec3{-1, 0, 0}), FrontEpsilon)
+	if !hit {
+		t.Fatal("Expected hit on the rotated quad")
+	}
+	if !Equal(rec.Point, Vec3{0, 0, -1}, 1e-10) {
+		t.Errorf("Expected hit point {0, 0, -1}, got %v", rec.Point)
+	}
+	if !Equal(rec.Normal, Vec3{1, 0, 0}, 1e-10) {
+		t.Errorf("Expected normal {1, 0, 0}, got %v", rec.Normal)
+	}
+	if !rec.FrontFace {
+		t.Error("Expected front face")
+	}
+	if hit, _ := testHit(rotated, NewRay(rnd, Vec3{1, 0, 5}, Vec3{0, 0, -1}), FrontEpsilon); hit {
+		t.Error("Expected no hit at the original orientation")
+	}
+	box := rotated.BoundingBox()
+	expected := NewAABB(Vec3{0, -1, -2}, Vec3{0, 1, 0})
+	for a := range 3 {
+		got, want := box.Axis(a), expected.Axis(a)
+		if math.Abs(got.Start-want.Start) > quadPadding || math.Abs(got.End-want.End) > quadPadding {
+			t.Errorf("BoundingBox() axis %d = %v, want %v", a, got, want)
+		}
+	}
+}
+
+func TestRotateYTranslateBox(t *testing.T) {
+	rnd := RandForTests()
+	// Unit cube rotated 45 degrees then moved: its edge now points at the camera.
+	box := &Translate{
+		Object: NewRotateY(NewBox(Vec3{-0.5, -0.5, -0.5}, Vec3{0.5, 0.5, 0.5}, Lambertian{}), 45),
+		Offset: Vec3{0, 0, -5},
+	}
+	hit, rec := testHit(box, NewRay(rnd, Vec3{0, 0, 0}, Vec3{0, 0, -1}), FrontEpsilon)
+	if !hit {
+		t.Fatal("Expected hit")
+	}
+	if math.Abs(rec.T-(5-math.Sqrt2/2)) > 1e-10 {
+		t.Errorf("Expected t = %v (edge of the rotated cube), got %v", 5-math.Sqrt2/2, rec.T)
+	}
+	bb := box.BoundingBox()
+	if math.Abs(bb.X.End-math.Sqrt2/2) > quadPadding || math.Abs(bb.Z.Start-(-5-math.Sqrt2/2)) > quadPadding {
+		t.Errorf("Unexpected bounding box %v", bb)
+	}
+}
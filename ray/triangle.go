@@ -0,0 +1,116 @@
+package ray
+
+import "math"
+
+// Triangle is a flat triangle with corners A, B and C, optionally smooth shaded by
+// interpolating per vertex normals. Use NewTriangle or NewSmoothTriangle to create one.
+// The front face is the one where (B-A) x (C-A) points (counter clockwise corners).
+type Triangle struct {
+	A, B, C Vec3
+	// NA, NB, NC are the unit vertex normals for smooth shading (zero for flat shading).
+	NA, NB, NC Vec3
+	Mat        Material
+	// OneSided triangles can only be hit from the front (see Quad.OneSided).
+	OneSided bool
+	// Computed fields (initialized by NewTriangle)
+	e1, e2 Vec3 // edges from A
+	normal Vec3 // unit geometric normal
+	smooth bool
+	bbox   AABB
+}
+
+// NewTriangle creates a flat shaded Triangle.
+func NewTriangle(a, b, c Vec3, mat Material) *Triangle {
+	e1, e2 := Sub(b, a), Sub(c, a)
+	return &Triangle{
+		A: a, B: b, C: c, Mat: mat,
+		e1: e1, e2: e2,
+		normal: Unit(Cross(e1, e2)),
+		bbox:   UnionAABB(NewAABB(a, b), NewAABB(c, c)).Pad(quadPadding),
+	}
+}
+
+// NewSmoothTriangle creates a Triangle whose shading normal is interpolated between the
+// unit vertex normals na, nb and nc (e.g. of the surface the mesh approximates).
+func NewSmoothTriangle(a, b, c, na, nb, nc Vec3, mat Material) *Triangle {
+	t := NewTriangle(a, b, c, mat)
+	t.NA, t.NB, t.NC = na, nb, nc
+	t.smooth = true
+	return t
+}
+
+// Hit uses the Möller-Trumbore algorithm. U and V of the hit record are the barycentric
+// coordinates of the hit point (weights of B and C).
+func (tr *Triangle) Hit(r *Ray, i Interval, hr *HitRecord) bool {
+	p := Cross(r.Direction, tr.e2)
+	det := Dot(tr.e1, p)
+	if math.Abs(det) < 1e-12 || (tr.OneSided && det < 0) {
+		return false // parallel to the triangle (or hitting the back of a one sided one)
+	}
+	invDet := 1 / det
+	s := Sub(r.Origin, tr.A)
+	u := Dot(s, p) * invDet
+	if u < 0 || u > 1 {
+		return false
+	}
+	q := Cross(s, tr.e1)
+	v := Dot(r.Direction, q) * invDet
+	if v < 0 || u+v > 1 {
+		return false
+	}
+	t := Dot(tr.e2, q) * invDet
+	if !i.Surrounds(t) {
+		return false
+	}
+	hr.Point = r.At(t)
+	hr.T = t
+	hr.U, hr.V = u, v
+	hr.Tangent = tr.e1
+	hr.SetFaceNormal(r, tr.normal)
+	if tr.smooth {
+		n := Unit(AddMultiple(SMul(tr.NA, 1-u-v), SMul(tr.NB, u), SMul(tr.NC, v)))
+		if !hr.FrontFace {
+			n = Neg(n)
+		}
+		hr.Normal = n
+	}
+	hr.Mat = tr.Mat
+	return true
+}
+
+func (tr *Triangle) BoundingBox() AABB {
+	return tr.bbox
+}
+
+// TessellateSphere returns a triangle mesh (in a BVH) approximating the sphere with the
+// given number of stacks (rings from pole to pole, at least 2) and slices (around the Y
+// axis, at least 3), smooth shaded with the sphere's normals.
+func TessellateSphere(center Vec3, radius float64, stacks, slices int, mat Material) Hittable {
+	stacks, slices = max(stacks, 2), max(slices, 3)
+	// Unit sphere point for stack i (0 is the top pole) and slice j.
+	vertex := func(i, j int) Vec3 {
+		theta := math.Pi * float64(i) / float64(stacks)
+		phi := 2 * math.Pi * float64(j%slices) / float64(slices)
+		sinTheta, cosTheta := math.Sincos(theta)
+		sinPhi, cosPhi := math.Sincos(phi)
+		return Vec3{sinTheta * cosPhi, cosTheta, -sinTheta * sinPhi}
+	}
+	triangle := func(a, b, c Vec3) Hittable {
+		return NewSmoothTriangle(AddScaled(center, a, radius), AddScaled(center, b, radius), AddScaled(center, c, radius),
+			a, b, c, mat)
+	}
+	triangles := make([]Hittable, 0, 2*(stacks-1)*slices)
+	for i := range stacks {
+		for j := range slices {
+			// Counter clockwise seen from outside: top left, bottom left, bottom right, top right.
+			tl, bl, br, tr := vertex(i, j), vertex(i+1, j), vertex(i+1, j+1), vertex(i, j+1)
+			if i > 0 {
+				triangles = append(triangles, triangle(tl, bl, tr))
+			}
+			if i < stacks-1 {
+				triangles = append(triangles, triangle(tr, bl, br))
+			}
+		}
+	}
+	return NewBVH(triangles)
+}
@@ -0,0 +1,85 @@
+package ray
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTriangleHit(t *testing.T) {
+	rnd := RandForTests()
+	mat := Lambertian{Albedo: ColorF{1, 0, 0}}
+	// Right triangle in the z=-2 plane, facing the camera (+Z).
+	tri := NewTriangle(Vec3{0, 0, -2}, Vec3{1, 0, -2}, Vec3{0, 1, -2}, mat)
+	hit, rec := testHit(tri, NewRay(rnd, Vec3{0.25, 0.5, 0}, Vec3{0, 0, -1}), FrontEpsilon)
+	if !hit {
+		t.Fatal("Expected hit")
+	}
+	if math.Abs(rec.T-2) > 1e-12 || math.Abs(rec.U-0.25) > 1e-12 || math.Abs(rec.V-0.5) > 1e-12 {
+		t.Errorf("Hit() t, u, v = %v, %v, %v, want 2, 0.25, 0.5", rec.T, rec.U, rec.V)
+	}
+	if !rec.FrontFace || rec.Normal != (Vec3{0, 0, 1}) || rec.Mat != mat {
+		t.Errorf("Hit() = %+v, want front face with normal {0, 0, 1}", rec)
+	}
+	tests := []struct {
+		name string
+		dir  Vec3
+	}{
+		{"outside the hypotenuse", Vec3{0.3, 0.3, -1}},
+		{"parallel to the plane", Vec3{1, 0, 0}},
+		{"pointing away", Vec3{0, 0, 1}},
+	}
+	for _, tt := range tests {
+		if hit, _ := testHit(tri, NewRay(rnd, Vec3{0.25, 0.5, 0}, tt.dir), FrontEpsilon); hit {
+			t.Errorf("%s: Hit() = true, want false", tt.name)
+		}
+	}
+	tri.OneSided = true
+	if hit, _ := testHit(tri, NewRay(rnd, Vec3{0.25, 0.5, -4}, Vec3{0, 0, 1}), FrontEpsilon); hit {
+		t.Errorf("OneSided: Hit() from the back = true, want false")
+	}
+}
+
+func TestSmoothTriangleNormal(t *testing.T) {
+	up, tilted := Vec3{0, 0, 1}, Unit(Vec3{1, 0, 1})
+	tri := NewSmoothTriangle(Vec3{0, 0, 0}, Vec3{1, 0, 0}, Vec3{0, 1, 0}, up, tilted, up, Lambertian{})
+	_, rec := testHit(tri, NewRay(RandForTests(), Vec3{0.5, 0, 1}, Vec3{0, 0, -1}), FrontEpsilon)
+	if want := Unit(Add(up, tilted)); !Equal(rec.Normal, want, 1e-12) {
+		t.Errorf("normal halfway = %v, want %v", rec.Normal, want)
+	}
+	_, rec = testHit(tri, NewRay(RandForTests(), Vec3{0.5, 0, -1}, Vec3{0, 0, 1}), FrontEpsilon)
+	if want := Neg(Unit(Add(up, tilted))); rec.FrontFace || !Equal(rec.Normal, want, 1e-12) {
+		t.Errorf("back face normal = %v, want %v", rec.Normal, want)
+	}
+}
+
+func TestTessellateSphere(t *testing.T) {
+	center := Vec3{0.2, -0.1, -3}
+	sphere := NewSphere(center, 1, Lambertian{})
+	mesh := TessellateSphere(center, 1, 32, 64, Lambertian{})
+	tracer := New(64, 64)
+	tracer.Camera = Camera{Position: Vec3{0, 0, 0}, LookAt: Vec3{0, 0, -1}, VerticalFoV: 45}
+	var hr, meshHr HitRecord
+	mismatches, hits := 0, 0
+	for y := range 64 {
+		for x := range 64 {
+			r := tracer.PrimaryRay(x, y)
+			hit := sphere.Hit(r, FrontEpsilon, &hr)
+			meshHit := mesh.Hit(r, FrontEpsilon, &meshHr)
+			if hit != meshHit {
+				mismatches++
+				continue
+			}
+			if !hit {
+				continue
+			}
+			hits++
+			if !meshHr.FrontFace || Dot(meshHr.Normal, hr.Normal) < 0.999 {
+				t.Errorf("pixel %d,%d: mesh normal %v (front %v), want about %v", x, y, meshHr.Normal, meshHr.FrontFace, hr.Normal)
+			}
+		}
+	}
+	// Only pixels right on the silhouette can differ.
+	if hits < 500 || mismatches > hits/50 {
+		t.Errorf("mesh silhouette differs for %d pixels out of %d sphere pixels", mismatches, hits)
+	}
+}
@@ -1,6 +1,9 @@
 package ray
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
 	"image/color"
 	"math"
 
@@ -17,8 +20,30 @@ type Vec3 struct {
 // Sadly go generics on [3]float64 has a huge negative performance impact and I don't
 // want to copy-pasta the same implementation twice, and can't use generics on structs.
 // So... type safety is no more.
+// As the components are unexported, ColorF{r, g, b} literals only work inside this
+// package: use RGB elsewhere, and R, G, B to read them.
 type ColorF = Vec3
 
+// RGB returns the color with the given (linear) red, green and blue components.
+func RGB(r, g, b float64) ColorF {
+	return ColorF{r, g, b}
+}
+
+// R returns the red component of the color (same as X).
+func (v Vec3) R() float64 {
+	return v.x
+}
+
+// G returns the green component of the color (same as Y).
+func (v Vec3) G() float64 {
+	return v.y
+}
+
+// B returns the blue component of the color (same as Z).
+func (v Vec3) B() float64 {
+	return v.z
+}
+
 // Methods for both Vec3 and ColorF
 
 // Add: vector addition. returns u + v.
@@ -92,6 +117,12 @@ func SMul(v Vec3, t float64) Vec3 {
 	return Vec3{v.x * t, v.y * t, v.z * t}
 }
 
+// AddScaled: fused multiply-add, returns u + v * t. Same as Add(u, SMul(v, t)) but
+// builds a single vector, for the hot paths (e.g. Ray.At).
+func AddScaled(u, v Vec3, t float64) Vec3 {
+	return Vec3{u.x + v.x*t, u.y + v.y*t, u.z + v.z*t}
+}
+
 // Mul: component-wise multiplication. returns u * v.
 func Mul(u, v Vec3) Vec3 {
 	return Vec3{u.x * v.x, u.y * v.y, u.z * v.z}
@@ -130,9 +161,20 @@ func NearZero(v Vec3) bool {
 	return (math.Abs(v.x) < s) && (math.Abs(v.y) < s) && (math.Abs(v.z) < s)
 }
 
+// Equal returns true if a and b are within eps of each other (Euclidean distance), e.g.
+// to compare computed points or colors without relying on exact float equality.
+func Equal(a, b Vec3, eps float64) bool {
+	return Length(Sub(a, b)) <= eps
+}
+
+// ApproxEqual returns true if v and b are within eps of each other, see Equal.
+func (v Vec3) ApproxEqual(b Vec3, eps float64) bool {
+	return Equal(v, b, eps)
+}
+
 // Reflect returns the reflection of vector v around normal n.
 func Reflect(v, n Vec3) Vec3 {
-	return Sub(v, SMul(n, 2*Dot(v, n)))
+	return AddScaled(v, n, -2*Dot(v, n))
 }
 
 // Refract computes the refraction of vector uv through normal n
@@ -144,6 +186,66 @@ func Refract(uv, n Vec3, etaiOverEtat float64) Vec3 {
 	return Add(rOutPerp, rOutParallel)
 }
 
+// Lerp: linear interpolation, returns a + (b - a) * t (a for t=0, b for t=1).
+func Lerp(a, b Vec3, t float64) Vec3 {
+	return Vec3{a.x + (b.x-a.x)*t, a.y + (b.y-a.y)*t, a.z + (b.z-a.z)*t}
+}
+
+// Min: component-wise minimum of u and v.
+func Min(u, v Vec3) Vec3 {
+	return Vec3{math.Min(u.x, v.x), math.Min(u.y, v.y), math.Min(u.z, v.z)}
+}
+
+// Max: component-wise maximum of u and v.
+func Max(u, v Vec3) Vec3 {
+	return Vec3{math.Max(u.x, v.x), math.Max(u.y, v.y), math.Max(u.z, v.z)}
+}
+
+// Abs: component-wise absolute value.
+func Abs(v Vec3) Vec3 {
+	return Vec3{math.Abs(v.x), math.Abs(v.y), math.Abs(v.z)}
+}
+
+// Lerp interpolates linearly from v (t=0) to b (t=1).
+// This is a convenience method wrapper around Lerp.
+func (v Vec3) Lerp(b Vec3, t float64) Vec3 {
+	return Lerp(v, b, t)
+}
+
+// Min returns the component-wise minimum of v and u.
+// This is a convenience method wrapper around Min.
+func (v Vec3) Min(u Vec3) Vec3 {
+	return Min(v, u)
+}
+
+// Max returns the component-wise maximum of v and u.
+// This is a convenience method wrapper around Max.
+func (v Vec3) Max(u Vec3) Vec3 {
+	return Max(v, u)
+}
+
+// Abs returns the component-wise absolute value of v.
+// This is a convenience method wrapper around Abs.
+func (v Vec3) Abs() Vec3 {
+	return Abs(v)
+}
+
+// Rotate returns v rotated by angleRad radians around axis (which doesn't need to be
+// normalized), counterclockwise when looking down the axis toward the origin (right-hand rule),
+// using the Rodrigues rotation formula:
+// v cos θ + (k × v) sin θ + k (k · v)(1 - cos θ) with k the unit axis.
+func Rotate(v Vec3, axis Vec3, angleRad float64) Vec3 {
+	k := Unit(axis)
+	sin, cos := math.Sincos(angleRad)
+	return AddMultiple(SMul(v, cos), SMul(Cross(k, v), sin), SMul(k, Dot(k, v)*(1-cos)))
+}
+
+// RotateAround returns v rotated by angleRad radians around axis.
+// This is a convenience method wrapper around Rotate.
+func (v Vec3) RotateAround(axis Vec3, angleRad float64) Vec3 {
+	return Rotate(v, axis, angleRad)
+}
+
 // X: returns the X component.
 func (v Vec3) X() float64 {
 	return v.x
@@ -164,11 +266,74 @@ func (v Vec3) Components() [3]float64 {
 	return [3]float64{v.x, v.y, v.z}
 }
 
+// FromComponents creates a Vec3 from an array of its components (the inverse of Components).
+func FromComponents(a [3]float64) Vec3 {
+	return Vec3{a[0], a[1], a[2]}
+}
+
 // XYZ: creates a Vec3 from its components.
 func XYZ(x, y, z float64) Vec3 {
 	return Vec3{x, y, z}
 }
 
+// MarshalJSON encodes the vector (or color) as a [x, y, z] array.
+func (v Vec3) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.Components())
+}
+
+// UnmarshalJSON decodes a [x, y, z] array, or an object with either x, y, z
+// or (for colors) r, g, b keys; missing keys are 0.
+func (v *Vec3) UnmarshalJSON(data []byte) error {
+	var a []float64
+	if err := json.Unmarshal(data, &a); err == nil {
+		if len(a) != 3 {
+			return fmt.Errorf("invalid vector %s, should have 3 components, not %d", data, len(a))
+		}
+		*v = Vec3{a[0], a[1], a[2]}
+		return nil
+	}
+	var o struct {
+		X, Y, Z, R, G, B *float64
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&o); err != nil {
+		return fmt.Errorf("invalid vector %s, should be [x, y, z], {\"x\", \"y\", \"z\"} or {\"r\", \"g\", \"b\"}: %w", data, err)
+	}
+	if (o.X != nil || o.Y != nil || o.Z != nil) && (o.R != nil || o.G != nil || o.B != nil) {
+		return fmt.Errorf("invalid vector %s, can't mix x, y, z with r, g, b", data)
+	}
+	*v = Vec3{deref(o.X) + deref(o.R), deref(o.Y) + deref(o.G), deref(o.Z) + deref(o.B)}
+	return nil
+}
+
+// deref returns *f, or 0 when f is nil.
+func deref(f *float64) float64 {
+	if f == nil {
+		return 0
+	}
+	return *f
+}
+
+// IsFinite returns true if none of the components is NaN or infinite.
+func (v Vec3) IsFinite() bool {
+	return !math.IsNaN(v.x) && !math.IsInf(v.x, 0) &&
+		!math.IsNaN(v.y) && !math.IsInf(v.y, 0) &&
+		!math.IsNaN(v.z) && !math.IsInf(v.z, 0)
+}
+
+// Sanitize returns c with its NaN and infinite channels replaced by 0, so a broken
+// sample (e.g. from the Unit of a zero vector) is black instead of garbage once encoded.
+func (c ColorF) Sanitize() ColorF {
+	finite := func(f float64) float64 {
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return 0
+		}
+		return f
+	}
+	return ColorF{finite(c.x), finite(c.y), finite(c.z)}
+}
+
 // ToSRGBA converts a linear ColorF to sRGB color.RGBA, clamping values to [0,1].
 func (c ColorF) ToSRGBA() color.RGBA {
 	return color.RGBA{
@@ -179,6 +344,24 @@ func (c ColorF) ToSRGBA() color.RGBA {
 	}
 }
 
+// ToRGBALinear converts a ColorF to color.RGBA without any transfer function (linear
+// 8 bit values), clamping values to [0,1].
+func (c ColorF) ToRGBALinear() color.RGBA {
+	return color.RGBA{R: toByte(c.x), G: toByte(c.y), B: toByte(c.z), A: 255}
+}
+
+// ToGamma converts a linear ColorF to color.RGBA encoded with the pure power law
+// gamma g (i.e. c^(1/g), e.g. 2.2), clamping values to [0,1].
+func (c ColorF) ToGamma(g float64) color.RGBA {
+	inv := 1 / g
+	return color.RGBA{
+		R: toByte(math.Pow(ZeroOne.Clamp(c.x), inv)),
+		G: toByte(math.Pow(ZeroOne.Clamp(c.y), inv)),
+		B: toByte(math.Pow(ZeroOne.Clamp(c.z), inv)),
+		A: 255,
+	}
+}
+
 // Interval represents a closed interval [Start, End] on the real number line.
 type Interval struct {
 	Start, End float64
@@ -211,10 +394,49 @@ func (i Interval) Clamp(t float64) float64 {
 	return t
 }
 
+// OrderedInterval returns the interval between a and b, whichever order they are given in.
+func OrderedInterval(a, b float64) Interval {
+	if a <= b {
+		return Interval{Start: a, End: b}
+	}
+	return Interval{Start: b, End: a}
+}
+
+// Union returns the smallest interval enclosing both a and b.
+func Union(a, b Interval) Interval {
+	return Interval{Start: math.Min(a.Start, b.Start), End: math.Max(a.End, b.End)}
+}
+
+// Intersect returns the overlap of a and b, empty (see IsEmpty) when they don't overlap.
+func Intersect(a, b Interval) Interval {
+	return Interval{Start: math.Max(a.Start, b.Start), End: math.Min(a.End, b.End)}
+}
+
+// Expand returns the interval grown by delta in total (delta/2 on each side);
+// a negative delta shrinks it.
+func (i Interval) Expand(delta float64) Interval {
+	padding := delta / 2
+	return Interval{Start: i.Start - padding, End: i.End + padding}
+}
+
+// IsEmpty returns true if the interval contains nothing (Start > End).
+func (i Interval) IsEmpty() bool {
+	return i.Start > i.End
+}
+
+// DefaultShadowEpsilon is the default Tracer.ShadowEpsilon: the distance (in units of the
+// ray direction's length) below which hits are considered to be the surface the ray starts
+// from, due to floating point rounding of the hit point (self intersection, "shadow acne":
+// dark speckles). Rounding errors grow with the coordinates so scenes with big objects or
+// far from the origin may need a larger value, while too large a value makes contact
+// shadows and thin objects leak light. For instance the 1000 radius ground sphere of
+// RichScene gets acne at grazing angles below about 1e-8.
+const DefaultShadowEpsilon = 1e-6
+
 var (
 	Empty        = Interval{Start: math.Inf(1), End: math.Inf(-1)}
 	Universe     = Interval{Start: math.Inf(-1), End: math.Inf(1)}
 	Front        = Interval{Start: 0, End: math.Inf(1)}
-	FrontEpsilon = Interval{Start: 1e-6, End: math.Inf(1)}
+	FrontEpsilon = Interval{Start: DefaultShadowEpsilon, End: math.Inf(1)}
 	ZeroOne      = Interval{Start: 0, End: 1}
 )
@@ -68,3 +68,14 @@ func BenchmarkChainedDirect(b *testing.B) {
 	}
 	_ = result
 }
+
+func BenchmarkChainedAddScaled(b *testing.B) {
+	v1 := Vec3{1.0, 2.0, 3.0}
+	v2 := Vec3{4.0, 5.0, 6.0}
+	v3 := Vec3{7.0, 8.0, 9.0}
+	var result Vec3
+	for b.Loop() {
+		result = AddScaled(Add(v1, v2), v3, 2.0)
+	}
+	_ = result
+}
@@ -1,8 +1,10 @@
 package ray
 
 import (
+	"encoding/json"
 	"image/color"
 	"math"
+	"reflect"
 	"testing"
 
 	"fortio.org/rand"
@@ -261,6 +263,27 @@ func TestColorF(t *testing.T) {
 	}
 }
 
+func TestColorFSanitize(t *testing.T) {
+	nan, inf := math.NaN(), math.Inf(1)
+	tests := []struct {
+		c, want ColorF
+		finite  bool
+	}{
+		{ColorF{0.5, 2, -1}, ColorF{0.5, 2, -1}, true},
+		{ColorF{nan, 0.5, 1}, ColorF{0, 0.5, 1}, false},
+		{ColorF{1, inf, -inf}, ColorF{1, 0, 0}, false},
+		{ColorF{nan, nan, nan}, ColorF{}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.c.IsFinite(); got != tt.finite {
+			t.Errorf("%v.IsFinite() = %v, want %v", tt.c, got, tt.finite)
+		}
+		if got := tt.c.Sanitize(); got != tt.want {
+			t.Errorf("%v.Sanitize() = %v, want %v", tt.c, got, tt.want)
+		}
+	}
+}
+
 func TestFloatColorToRGBA(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -531,6 +554,58 @@ func TestRandomUnitVectorCorrectness(t *testing.T) {
 	}
 }
 
+// TestRandMethodsCorrectness mirrors TestRandomUnitVectorCorrectness for the Rand methods,
+// and checks they return the same values as the free functions for the same seed.
+func TestRandMethodsCorrectness(t *testing.T) {
+	normal := Unit(Vec3{1, 2, -3})
+	tests := []struct {
+		name   string
+		method func(Rand) Vec3
+		fn     func(rand.Rand) Vec3
+	}{
+		{"UnitVector", Rand.UnitVector, RandomUnitVector},
+		{"OnHemisphere", func(r Rand) Vec3 { return r.OnHemisphere(normal) },
+			func(r rand.Rand) Vec3 { return RandomOnHemisphere(r, normal) }},
+		{"CosineDirection", func(r Rand) Vec3 { return r.CosineDirection(normal) },
+			func(r rand.Rand) Vec3 { return RandomCosineDirection(r, normal) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			const samples = 100
+			const tolerance = 1e-9
+			r, ref := NewRand(42), rand.New(42)
+			for i := range samples {
+				v := tt.method(r)
+				if length := Length(v); math.Abs(length-1.0) > tolerance {
+					t.Errorf("sample %d: Length() = %.15f, want 1.0", i, length)
+				}
+				if tt.name != "UnitVector" && Dot(v, normal) < 0 {
+					t.Errorf("sample %d: %v isn't in the hemisphere of %v", i, v, normal)
+				}
+				if want := tt.fn(ref); v != want {
+					t.Errorf("sample %d: %s() = %v, want %v as the free function", i, tt.name, v, want)
+				}
+			}
+		})
+	}
+	r, ref := NewRand(42), rand.New(42)
+	iv := Interval{-2, 3}
+	for i := range 100 {
+		v := r.Vec3InRange(iv)
+		for _, c := range v.Components() {
+			if c < iv.Start || c >= iv.End {
+				t.Errorf("sample %d: Vec3InRange() = %v, out of %v", i, v, iv)
+			}
+		}
+		if want := RandomInRange(ref, iv); v != want {
+			t.Errorf("sample %d: Vec3InRange() = %v, want %v", i, v, want)
+		}
+		if v, want := r.Vec3Random(), Random(ref); v != want {
+			t.Errorf("sample %d: Vec3Random() = %v, want %v", i, v, want)
+		}
+	}
+}
+
 // TestRandomUnitVectorDistribution checks that the generated vectors are
 // uniformly distributed over the unit sphere by testing:
 // 1. Mean of components approaches zero.
@@ -738,6 +813,40 @@ func TestRandomOnHemisphere(t *testing.T) {
 	}
 }
 
+// TestRandomCosineDirectionDistribution checks the cosine weighted hemisphere distribution:
+// E[cos] = 2/3 and E[cos^2] = 1/2 (vs 1/2 and 1/3 for uniform), centered around the normal.
+func TestRandomCosineDirectionDistribution(t *testing.T) {
+	for _, normal := range []Vec3{{0, 0, 1}, {0, -1, 0}, {1, 0, 0}, Unit(Vec3{1, -2, 3})} {
+		r := RandForTests()
+		const samples = 100000
+		var sumCos, sumCos2 float64
+		var sum Vec3
+		for range samples {
+			v := RandomCosineDirection(r, normal)
+			if math.Abs(Length(v)-1) > 1e-9 {
+				t.Fatalf("RandomCosineDirection(%v) = %v, length %v, want 1", normal, v, Length(v))
+			}
+			cos := Dot(v, normal)
+			if cos < 0 {
+				t.Fatalf("RandomCosineDirection(%v) = %v, below the surface", normal, v)
+			}
+			sumCos += cos
+			sumCos2 += cos * cos
+			sum = Add(sum, v)
+		}
+		if mean := sumCos / samples; math.Abs(mean-2.0/3) > 0.005 {
+			t.Errorf("%v: mean cosine = %v, want 2/3", normal, mean)
+		}
+		if mean2 := sumCos2 / samples; math.Abs(mean2-0.5) > 0.005 {
+			t.Errorf("%v: mean squared cosine = %v, want 1/2", normal, mean2)
+		}
+		// The tangential components average out: the mean vector is along the normal.
+		if tangential := Length(Sub(SDiv(sum, samples), SMul(normal, sumCos/samples))); tangential > 0.01 {
+			t.Errorf("%v: mean tangential component = %v, want 0", normal, tangential)
+		}
+	}
+}
+
 // Benchmarks for comparing the three methods
 
 func BenchmarkRandomUnitVector(b *testing.B) {
@@ -786,6 +895,36 @@ func TestNearZero(t *testing.T) {
 	}
 }
 
+func TestEqual(t *testing.T) {
+	tenth := 0.1
+	sum := tenth + 0.2 // 0.30000000000000004 (not folded as a constant)
+	tests := []struct {
+		name     string
+		a, b     Vec3
+		eps      float64
+		expected bool
+	}{
+		{"identical", Vec3{1, 2, 3}, Vec3{1, 2, 3}, 0, true},
+		{"within eps", Vec3{1, 2, 3}, Vec3{1 + 1e-10, 2, 3 - 1e-10}, 1e-9, true},
+		{"at eps", Vec3{0, 0, 0}, Vec3{3, 4, 0}, 5, true},
+		{"each component within eps but not the distance", Vec3{0, 0, 0}, Vec3{0.8, 0.8, 0.8}, 1, false},
+		{"one component off", Vec3{1, 2, 3}, Vec3{1, 2, 3.1}, 0.01, false},
+		{"rounding errors", Vec3{sum, 0.3, 1}, Vec3{0.3, sum, 1}, 1e-15, true},
+		{"rounding errors with exact eps", Vec3{sum, 0.3, 1}, Vec3{0.3, sum, 1}, 0, false},
+		{"NaN", Vec3{math.NaN(), 0, 0}, Vec3{math.NaN(), 0, 0}, 1, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := Equal(tt.a, tt.b, tt.eps); result != tt.expected {
+				t.Errorf("Equal(%v, %v, %g) = %v, want %v", tt.a, tt.b, tt.eps, result, tt.expected)
+			}
+			if result := tt.b.ApproxEqual(tt.a, tt.eps); result != tt.expected {
+				t.Errorf("%v.ApproxEqual(%v, %g) = %v, want %v", tt.b, tt.a, tt.eps, result, tt.expected)
+			}
+		})
+	}
+}
+
 func TestReflect(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -902,3 +1041,284 @@ func TestRefract(t *testing.T) {
 		})
 	}
 }
+
+func TestOrderedInterval(t *testing.T) {
+	if i := OrderedInterval(1, 2); i != (Interval{Start: 1, End: 2}) {
+		t.Errorf("OrderedInterval(1, 2) = %v, want [1, 2]", i)
+	}
+	if i := OrderedInterval(2, -1); i != (Interval{Start: -1, End: 2}) {
+		t.Errorf("OrderedInterval(2, -1) = %v, want [-1, 2]", i)
+	}
+}
+
+func TestIntervalUnion(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     Interval
+		expected Interval
+	}{
+		{"overlapping", Interval{Start: 0, End: 2}, Interval{Start: 1, End: 3}, Interval{Start: 0, End: 3}},
+		{"disjoint", Interval{Start: 0, End: 1}, Interval{Start: 5, End: 6}, Interval{Start: 0, End: 6}},
+		{"nested", Interval{Start: 0, End: 10}, Interval{Start: 2, End: 3}, Interval{Start: 0, End: 10}},
+		{"with Empty", Empty, Interval{Start: 2, End: 3}, Interval{Start: 2, End: 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := Union(tt.a, tt.b); result != tt.expected {
+				t.Errorf("Union() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIntervalIntersect(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     Interval
+		expected Interval
+		empty    bool
+	}{
+		{"overlapping", Interval{Start: 0, End: 2}, Interval{Start: 1, End: 3}, Interval{Start: 1, End: 2}, false},
+		{"nested", Interval{Start: 0, End: 10}, Interval{Start: 2, End: 3}, Interval{Start: 2, End: 3}, false},
+		{"touching", Interval{Start: 0, End: 1}, Interval{Start: 1, End: 2}, Interval{Start: 1, End: 1}, false},
+		{"disjoint", Interval{Start: 0, End: 1}, Interval{Start: 5, End: 6}, Interval{Start: 5, End: 1}, true},
+		{"disjoint reversed", Interval{Start: 5, End: 6}, Interval{Start: 0, End: 1}, Interval{Start: 5, End: 1}, true},
+		{"with Universe", Universe, Interval{Start: 2, End: 3}, Interval{Start: 2, End: 3}, false},
+		{"with Empty", Empty, Interval{Start: 2, End: 3}, Empty, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Intersect(tt.a, tt.b)
+			if result != tt.expected {
+				t.Errorf("Intersect() = %v, want %v", result, tt.expected)
+			}
+			if result.IsEmpty() != tt.empty {
+				t.Errorf("Intersect().IsEmpty() = %v, want %v", result.IsEmpty(), tt.empty)
+			}
+		})
+	}
+}
+
+func TestIntervalExpandIsEmpty(t *testing.T) {
+	tests := []struct {
+		name     string
+		i        Interval
+		delta    float64
+		expected Interval
+		empty    bool
+	}{
+		{"grow", Interval{Start: 1, End: 2}, 1, Interval{Start: 0.5, End: 2.5}, false},
+		{"point", Interval{Start: 3, End: 3}, 0.5, Interval{Start: 2.75, End: 3.25}, false},
+		{"shrink", Interval{Start: 0, End: 4}, -2, Interval{Start: 1, End: 3}, false},
+		{"shrink to empty", Interval{Start: 0, End: 1}, -2, Interval{Start: 1, End: 0}, true},
+		{"zero", ZeroOne, 0, ZeroOne, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.i.Expand(tt.delta)
+			if result != tt.expected {
+				t.Errorf("Expand(%v) = %v, want %v", tt.delta, result, tt.expected)
+			}
+			if result.IsEmpty() != tt.empty {
+				t.Errorf("Expand(%v).IsEmpty() = %v, want %v", tt.delta, result.IsEmpty(), tt.empty)
+			}
+		})
+	}
+	if !Empty.IsEmpty() || Universe.IsEmpty() || Front.IsEmpty() {
+		t.Error("IsEmpty() should be true for Empty only")
+	}
+}
+
+func TestRotate(t *testing.T) {
+	tests := []struct {
+		name     string
+		v        Vec3
+		axis     Vec3
+		angle    float64
+		expected Vec3
+	}{
+		{"x 90 around z", Vec3{1, 0, 0}, Vec3{0, 0, 1}, math.Pi / 2, Vec3{0, 1, 0}},
+		{"y 90 around z", Vec3{0, 1, 0}, Vec3{0, 0, 1}, math.Pi / 2, Vec3{-1, 0, 0}},
+		{"z 90 around x", Vec3{0, 0, 1}, Vec3{1, 0, 0}, math.Pi / 2, Vec3{0, -1, 0}},
+		{"x 90 around y", Vec3{1, 0, 0}, Vec3{0, 1, 0}, math.Pi / 2, Vec3{0, 0, -1}},
+		{"z 90 around unnormalized y", Vec3{0, 0, 1}, Vec3{0, 5, 0}, math.Pi / 2, Vec3{1, 0, 0}},
+		{"x 180 around z", Vec3{1, 0, 0}, Vec3{0, 0, 1}, math.Pi, Vec3{-1, 0, 0}},
+		{"y 180 around x", Vec3{0, 2, 0}, Vec3{1, 0, 0}, math.Pi, Vec3{0, -2, 0}},
+		{"along the axis", Vec3{0, 0, 3}, Vec3{0, 0, 1}, 1.234, Vec3{0, 0, 3}},
+		{"x 120 around diagonal", Vec3{1, 0, 0}, Vec3{1, 1, 1}, 2 * math.Pi / 3, Vec3{0, 1, 0}},
+		{"negative angle", Vec3{1, 0, 0}, Vec3{0, 0, 1}, -math.Pi / 2, Vec3{0, -1, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Rotate(tt.v, tt.axis, tt.angle); !Equal(got, tt.expected, 1e-12) {
+				t.Errorf("Rotate(%v, %v, %v) = %v, want %v", tt.v, tt.axis, tt.angle, got, tt.expected)
+			}
+			if got := tt.v.RotateAround(tt.axis, tt.angle); !Equal(got, tt.expected, 1e-12) {
+				t.Errorf("%v.RotateAround(%v, %v) = %v, want %v", tt.v, tt.axis, tt.angle, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRotateRoundTrip(t *testing.T) {
+	rng := RandForTests()
+	for range 100 {
+		v := RandomInRange(rng, Interval{-10, 10})
+		axis := RandomUnitVector(rng)
+		angle := rng.Float64Range(-2*math.Pi, 2*math.Pi)
+		rotated := Rotate(v, axis, angle)
+		if math.Abs(Length(rotated)-Length(v)) > 1e-9 {
+			t.Errorf("Rotate(%v, %v, %v) changed the length to %v", v, axis, angle, Length(rotated))
+		}
+		if back := rotated.RotateAround(axis, -angle); !Equal(back, v, 1e-9) {
+			t.Errorf("Rotate back of %v around %v by %v = %v", v, axis, angle, back)
+		}
+	}
+}
+
+func TestLerp(t *testing.T) {
+	a, b := Vec3{1, 2, 3}, Vec3{3, -2, 3}
+	tests := []struct {
+		t        float64
+		expected Vec3
+	}{
+		{0, a},
+		{1, b},
+		{0.5, Vec3{2, 0, 3}},
+		{0.25, Vec3{1.5, 1, 3}},
+		{2, Vec3{5, -6, 3}}, // extrapolates
+		{-1, Vec3{-1, 6, 3}},
+	}
+	for _, tt := range tests {
+		if got := Lerp(a, b, tt.t); got != tt.expected {
+			t.Errorf("Lerp(%v, %v, %v) = %v, want %v", a, b, tt.t, got, tt.expected)
+		}
+		if got := a.Lerp(b, tt.t); got != tt.expected {
+			t.Errorf("%v.Lerp(%v, %v) = %v, want %v", a, b, tt.t, got, tt.expected)
+		}
+	}
+}
+
+func TestAddScaled(t *testing.T) {
+	tests := []struct {
+		u, v Vec3
+		t    float64
+	}{
+		{Vec3{1, 2, 3}, Vec3{4, 5, 6}, 2},
+		{Vec3{1, 2, 3}, Vec3{4, 5, 6}, 0},
+		{Vec3{0, 0, 0}, Vec3{-1, 0.5, 3}, -0.25},
+	}
+	for _, tt := range tests {
+		expected := Add(tt.u, SMul(tt.v, tt.t))
+		if got := AddScaled(tt.u, tt.v, tt.t); got != expected {
+			t.Errorf("AddScaled(%v, %v, %v) = %v, want %v", tt.u, tt.v, tt.t, got, expected)
+		}
+	}
+}
+
+func TestFromComponents(t *testing.T) {
+	v := Vec3{1, -2, 3.5}
+	if got := FromComponents(v.Components()); got != v {
+		t.Errorf("FromComponents(%v) = %v, want %v", v.Components(), got, v)
+	}
+}
+
+func TestRGB(t *testing.T) {
+	c := RGB(0.5, 0.75, 1.0)
+	if c != (ColorF{0.5, 0.75, 1.0}) {
+		t.Errorf("RGB(0.5, 0.75, 1.0) = %v, want the same as the ColorF literal", c)
+	}
+	if c.R() != 0.5 || c.G() != 0.75 || c.B() != 1.0 {
+		t.Errorf("R(), G(), B() = %v, %v, %v, want 0.5, 0.75, 1", c.R(), c.G(), c.B())
+	}
+}
+
+func TestMinMaxAbs(t *testing.T) {
+	tests := []struct {
+		name               string
+		u, v               Vec3
+		minV, maxV, absOfU Vec3
+	}{
+		{"mixed", Vec3{1, -2, 3}, Vec3{-1, 5, 3}, Vec3{-1, -2, 3}, Vec3{1, 5, 3}, Vec3{1, 2, 3}},
+		{"same", Vec3{-4, -5, -6}, Vec3{-4, -5, -6}, Vec3{-4, -5, -6}, Vec3{-4, -5, -6}, Vec3{4, 5, 6}},
+		{"infinities", Vec3{math.Inf(1), 0, 2}, Vec3{0, math.Inf(-1), 1}, Vec3{0, math.Inf(-1), 1},
+			Vec3{math.Inf(1), 0, 2}, Vec3{math.Inf(1), 0, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Min(tt.u, tt.v); got != tt.minV {
+				t.Errorf("Min(%v, %v) = %v, want %v", tt.u, tt.v, got, tt.minV)
+			}
+			if got := tt.u.Min(tt.v); got != tt.minV {
+				t.Errorf("%v.Min(%v) = %v, want %v", tt.u, tt.v, got, tt.minV)
+			}
+			if got := Max(tt.u, tt.v); got != tt.maxV {
+				t.Errorf("Max(%v, %v) = %v, want %v", tt.u, tt.v, got, tt.maxV)
+			}
+			if got := tt.u.Max(tt.v); got != tt.maxV {
+				t.Errorf("%v.Max(%v) = %v, want %v", tt.u, tt.v, got, tt.maxV)
+			}
+			if got := Abs(tt.u); got != tt.absOfU {
+				t.Errorf("Abs(%v) = %v, want %v", tt.u, got, tt.absOfU)
+			}
+			if got := tt.u.Abs(); got != tt.absOfU {
+				t.Errorf("%v.Abs() = %v, want %v", tt.u, got, tt.absOfU)
+			}
+		})
+	}
+}
+
+func TestVec3JSON(t *testing.T) {
+	type withVectors struct {
+		Position Vec3   `json:"position"`
+		Color    ColorF `json:"color"`
+		Points   []Vec3 `json:"points"`
+	}
+	in := withVectors{Position: Vec3{1, -2.5, 3e10}, Color: ColorF{0.1, 0.2, 0.3}, Points: []Vec3{{}, {1, 1, 1}}}
+	data, err := json.Marshal(in)
+	if err != nil {
+		t.Fatalf("json.Marshal() error: %v", err)
+	}
+	expected := `{"position":[1,-2.5,30000000000],"color":[0.1,0.2,0.3],"points":[[0,0,0],[1,1,1]]}`
+	if string(data) != expected {
+		t.Errorf("json.Marshal() = %s, want %s", data, expected)
+	}
+	var out withVectors
+	if err = json.Unmarshal(data, &out); err != nil {
+		t.Fatalf("json.Unmarshal() error: %v", err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Errorf("round trip = %+v, want %+v", out, in)
+	}
+}
+
+func TestVec3UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Vec3
+		wantErr  bool
+	}{
+		{`[1, 2, 3]`, Vec3{1, 2, 3}, false},
+		{`{"x": 1, "y": 2, "z": 3}`, Vec3{1, 2, 3}, false},
+		{`{"r": 0.5, "g": 0.25, "b": 1}`, Vec3{0.5, 0.25, 1}, false},
+		{`{"R": 0.5, "B": 1}`, Vec3{0.5, 0, 1}, false},
+		{`{}`, Vec3{}, false},
+		{`[1, 2]`, Vec3{}, true},
+		{`[1, 2, 3, 4]`, Vec3{}, true},
+		{`{"x": 1, "g": 2}`, Vec3{}, true},
+		{`{"w": 1}`, Vec3{}, true},
+		{`"1,2,3"`, Vec3{}, true},
+		{`[1, "a", 3]`, Vec3{}, true},
+	}
+	for _, tt := range tests {
+		var v Vec3
+		err := json.Unmarshal([]byte(tt.input), &v)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Unmarshal(%s) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && v != tt.expected {
+			t.Errorf("Unmarshal(%s) = %v, want %v", tt.input, v, tt.expected)
+		}
+	}
+}